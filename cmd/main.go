@@ -1,5 +1,5 @@
 //  Created : 2024-Apr-04
-// Modified : 2024-Jun-18
+// Modified : 2026-Oct-18
 
 // The following marks in the func header comment mean:
 //    +++        func can be used in other projects without change.
@@ -14,6 +14,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -66,16 +67,53 @@ const UPDATE_RATE_LIMIT = DEFAULT_RATE_LIMIT / 5
 // Why would anybody send many request to this endpoint?
 const HEALTH_RATE_LIMIT = 2
 
-// This is Apache Cassandra Database entry node.
-// It can be overwritten by the cmdline param 'database-url'.
+// The rate limit for '/admin/...' endpoints (all together), it also slows down
+// anybody trying to guess the admin token.
+const ADMIN_RATE_LIMIT = 5
+
+// Database drivers: Apache Cassandra (default), SQLite or PostgreSQL (see 'createStore').
+const DATABASE_DRIVER_GOCQL = "gocql"
+const DATABASE_DRIVER_SQLITE = "sqlite"
+const DATABASE_DRIVER_POSTGRES = "postgres"
+
+// 'vote-svc migrate [flags]' updates SQL database schema and exits (see 'runMigrate').
+const CMD_MIGRATE = "migrate"
+
+// 'vote-svc audit [flags] vote_id ...' verifies the audit logs of the polls and exits (see 'runAudit').
+const CMD_AUDIT = "audit"
+
+// 'vote-svc keygen FILE' writes a new private key of the encrypted polls to FILE, prints the
+// public key and exits (see 'runKeygen').
+const CMD_KEYGEN = "keygen"
+
+// 'vote-svc tally [flags] KEY_FILE vote_id' decrypts the tally of the closed encrypted poll
+// and exits (see 'runTally').
+const CMD_TALLY = "tally"
+
+// SQLite database file, unless it's specified by the cmdline param 'database-url'.
+const DEFAULT_SQLITE_FILE = "vote-svc.db"
+
+// This is Apache Cassandra Database entry node. It can be overwritten by the cmdline
+// param 'database-url' (a comma-separated list of nodes, e.g. "10.0.0.1,10.0.0.2").
 const DEFAULT_DATABASE_URL = "172.16.70.31"
 
+// Storage types (see 'createStore').
+const STORE_DATABASE = "database"
+const STORE_MEMORY = "memory"
+
 // Keyspace name must coincide with the actual keyspace in the database.
 const DEFAULT_DATABASE_KEYSPACE = "polls"
 
 // Most likely you won't need to change this port.
 const DEFAULT_DATABASE_PORT = 9042
 
+// Apache Cassandra connection defaults (see cmdline params 'database-...').
+const DEFAULT_DATABASE_CONSISTENCY = "QUORUM"
+const DEFAULT_DATABASE_CONNS = 4
+const DEFAULT_DATABASE_TIMEOUT = 10 * time.Second
+const DEFAULT_DATABASE_RECONNECT_INTERVAL = 6 * time.Second
+const DEFAULT_DATABASE_RECONNECT_RETRIES = 10
+
 // Cache.
 const DEFAULT_CACHE_EXPIRE = 15 * time.Minute
 const DEFAULT_CACHE_CLEAR = 30 * time.Minute
@@ -110,12 +148,34 @@ var lightstepToken = fs.String("lightstep-token", "", "Enable LightStep tracing
 var appdashAddr = fs.String("appdash-addr", "", "Enable Appdash tracing via an Appdash server host:port")
 var rateLimit = fs.Int("rate-limit", DEFAULT_RATE_LIMIT, "Rate limit for requests")
 var rateLimitPut = fs.Int("rate-limit-put", UPDATE_RATE_LIMIT, "Rate limit for PUT/POST requests")
-var databaseURL = fs.String("database-url", DEFAULT_DATABASE_URL, "Database URL (whatever it means for the database you use)")
+var storeType = fs.String("store", STORE_DATABASE, "Storage: 'database' or 'memory' (no database, for demos and tests)")
+var storeSeed = fs.String("store-seed", "", "JSON file to fill the 'memory' store, e.g. testdata/votes.json")
+var databaseDriver = fs.String("database-driver", "", "Database driver: 'gocql' (Apache Cassandra), 'sqlite' or 'postgres' (default is 'postgres' for postgres:// URL, otherwise 'gocql')")
+var cassandraCounters = fs.Bool("cassandra-counters", false, "Apache Cassandra: keep the counts in the counter table polls.vote_counts (see 'vote-svc migrate')")
+var databaseURL = fs.String("database-url", getEnv("DATABASE_URL", DEFAULT_DATABASE_URL), "Database URL (whatever it means for the database you use), env DATABASE_URL")
+var adminToken = fs.String("admin-token", getEnv("ADMIN_TOKEN", ""), "Bearer token for '/admin/...' endpoints (empty: disabled), env ADMIN_TOKEN (preferred)")
+var jwtKeys = fs.String("jwt-keys", getEnv("JWT_KEYS", ""), "Public keys to check voters' JWT (comma-separated PEM or JWKS files) for polls with 'authenticate', env JWT_KEYS")
+var jwtIssuer = fs.String("jwt-issuer", getEnv("JWT_ISSUER", ""), "Required JWT issuer ('iss'), env JWT_ISSUER")
+var jwtAudience = fs.String("jwt-audience", getEnv("JWT_AUDIENCE", ""), "Required JWT audience ('aud'), env JWT_AUDIENCE")
 var cacheExpire = fs.Duration("cache-expire", DEFAULT_CACHE_EXPIRE, "Cache expiration time")
 var cacheClear = fs.Duration("cache-clear", DEFAULT_CACHE_CLEAR, "Cache clear time")
 
-// var databasePort = fs.Int("database-port", DEFAULT_DATABASE_PORT, "Cassandra Database port")
-// var databaseKeyspace = fs.String("database-keyspace", DEFAULT_DATABASE_KEYSPACE, "Cassandra Database keyspace")
+// Apache Cassandra only (see 'getCassandraCluster').
+var databasePort = fs.Int("database-port", getEnvInt("DATABASE_PORT", DEFAULT_DATABASE_PORT), "Cassandra Database port, env DATABASE_PORT")
+var databaseLocalDC = fs.String("database-local-dc", getEnv("DATABASE_LOCAL_DC", ""), "Cassandra local data center; queries go to its nodes (DC-aware routing), env DATABASE_LOCAL_DC")
+var databaseKeyspace = fs.String("database-keyspace", getEnv("DATABASE_KEYSPACE", DEFAULT_DATABASE_KEYSPACE), "Cassandra Database keyspace, env DATABASE_KEYSPACE")
+var databaseUser = fs.String("database-user", getEnv("DATABASE_USER", ""), "Cassandra Database user (password auth), env DATABASE_USER")
+var databasePassword = fs.String("database-password", getEnv("DATABASE_PASSWORD", ""), "Cassandra Database password, env DATABASE_PASSWORD (preferred, cmdline is visible to other users)")
+var databaseCA = fs.String("database-ca", getEnv("DATABASE_CA", ""), "CA certificate file to verify Cassandra nodes (it enables TLS), env DATABASE_CA")
+var databaseCert = fs.String("database-cert", getEnv("DATABASE_CERT", ""), "Client certificate file for TLS to Cassandra, env DATABASE_CERT")
+var databaseKey = fs.String("database-key", getEnv("DATABASE_KEY", ""), "Client private key file for TLS to Cassandra, env DATABASE_KEY")
+var databaseHostVerify = fs.Bool("database-host-verification", getEnvBool("DATABASE_HOST_VERIFICATION", true), "Check that Cassandra node certificate matches its address, env DATABASE_HOST_VERIFICATION")
+var readConsistency = fs.String("database-read-consistency", getEnv("DATABASE_READ_CONSISTENCY", DEFAULT_DATABASE_CONSISTENCY), "Cassandra consistency level for reads (ONE, LOCAL_QUORUM, QUORUM, ...), env DATABASE_READ_CONSISTENCY")
+var writeConsistency = fs.String("database-write-consistency", getEnv("DATABASE_WRITE_CONSISTENCY", DEFAULT_DATABASE_CONSISTENCY), "Cassandra consistency level for writes, env DATABASE_WRITE_CONSISTENCY")
+var databaseConns = fs.Int("database-conns", getEnvInt("DATABASE_CONNS", DEFAULT_DATABASE_CONNS), "Connections to each Cassandra node, env DATABASE_CONNS")
+var databaseTimeout = fs.Duration("database-timeout", getEnvDuration("DATABASE_TIMEOUT", DEFAULT_DATABASE_TIMEOUT), "Cassandra connect and query timeout, env DATABASE_TIMEOUT")
+var reconnectInterval = fs.Duration("database-reconnect-interval", getEnvDuration("DATABASE_RECONNECT_INTERVAL", DEFAULT_DATABASE_RECONNECT_INTERVAL), "Interval between attempts to reconnect to a Cassandra node, env DATABASE_RECONNECT_INTERVAL")
+var reconnectRetries = fs.Int("database-reconnect-retries", getEnvInt("DATABASE_RECONNECT_RETRIES", DEFAULT_DATABASE_RECONNECT_RETRIES), "Attempts to reconnect to a Cassandra node, env DATABASE_RECONNECT_RETRIES")
 
 ///////////
 //
@@ -125,13 +185,36 @@ var cacheClear = fs.Duration("cache-clear", DEFAULT_CACHE_CLEAR, "Cache clear ti
 
 func main() {
 
-	fs.Parse(os.Args[1:])
+	// 'vote-svc migrate ...', 'vote-svc audit ...' and 'vote-svc tally ...' have the same cmdline
+	// params as 'vote-svc ...'.
+	args := os.Args[1:]
+	migrateOnly := len(args) > 0 && args[0] == CMD_MIGRATE
+	auditOnly := len(args) > 0 && args[0] == CMD_AUDIT
+	keygenOnly := len(args) > 0 && args[0] == CMD_KEYGEN
+	tallyOnly := len(args) > 0 && args[0] == CMD_TALLY
+	if migrateOnly || auditOnly || keygenOnly || tallyOnly {
+		args = args[1:]
+	}
+	fs.Parse(args)
 
 	// Create a single logger that will be used here and given to other components.
 	logger = log.NewLogfmtLogger(os.Stderr)
 	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
 	logger = log.With(logger, "caller", log.DefaultCaller)
 
+	if migrateOnly {
+		os.Exit(runMigrate())
+	}
+	if auditOnly {
+		os.Exit(runAudit(fs.Args()))
+	}
+	if keygenOnly {
+		os.Exit(runKeygen(fs.Args()))
+	}
+	if tallyOnly {
+		os.Exit(runTally(fs.Args()))
+	}
+
 	// Determine which tracer to use. It will be passed as a dependency
 	// to all the components using it.
 	if *zipkinURL != "" {
@@ -164,27 +247,17 @@ func main() {
 		tracer = opentracinggo.GlobalTracer()
 	}
 
-	// Database (Apache Cassandra noSQL). Technically, 'cluster' is just a pointer
-	// to a struct 'gocql.ClusterConfig'. The actual connection to the database is
-	// established in the 'service' layer (see 'pkg/service/service.go'). Notice that
-	// this app does not use password auth for the database access, but it can be changed.
-	cluster := gocql.NewCluster(*databaseURL)
-	cluster.Consistency = gocql.Quorum
-	cluster.NumConns = 4
-	cluster.Timeout = time.Second * 10
-	cluster.ConnectTimeout = time.Second * 10
-	cluster.ReconnectionPolicy = &gocql.ConstantReconnectionPolicy{MaxRetries: 10, Interval: 6 * time.Second}
-
-	// cluster.ProtoVersion = 4
-	// cluster.Keyspace = databaseKeyspace
-	// cluster.Port = databasePort
-	// cluster.Hosts = []string{*databaseURL}
-	// cluster.PoolConfig.HostSelectionPolicy = gocql.HostPoolHostPolicy(hostpool.New(nil))
-
-	// cluster.Authenticator = gocql.PasswordAuthenticator{
-	// Username: "user",
-	// Password: "password",
-	// }
+	// The storage, see 'createStore' below.
+	store, err := createStore()
+	if err != nil {
+		logger.Log("store", *storeType, "during", "createStore", "err", err)
+		os.Exit(1)
+	}
+
+	// The service can start before the database, but it's good to know.
+	if err = store.Ping(context.Background()); err != nil {
+		logger.Log("store", *storeType, "during", "Ping", "err", err)
+	}
 
 	// Note! This is not memcached! This is local in-memory cache.
 	memCache := cache.New(*cacheExpire, *cacheClear)
@@ -195,14 +268,385 @@ func main() {
 	//
 	// ===== Main part =====
 	//
-	svc := service.New(cluster, getServiceMiddleware(logger))
+	opts, err := getServiceOptions()
+	if err != nil {
+		logger.Log("during", "getServiceOptions", "err", err)
+		os.Exit(1)
+	}
+	svc := service.New(store, getServiceMiddleware(logger), opts...)
 	eps := endpoint.New(svc, memCache, getEndpointMiddleware(logger))
 	g := createService(eps)
 	initMetricsEndpoint(g)
+	initStoreShutdown(store, g)
 	initCancelInterrupt(g)
 	logger.Log("exit", g.Run())
 }
 
+////////////////
+//
+// CREATE STORE
+//
+////////// called by main ---
+
+// The 'database' store is the Apache Cassandra, SQLite for small deployments, or PostgreSQL
+// (see 'database-driver'; SQLite tables are created automatically, PostgreSQL tables are
+// created by 'vote-svc migrate'). The 'memory' store does
+// not need any database, it's good for demos and tests (it can be filled from a JSON file,
+// see 'testdata/votes.json'), but all the votes are lost when the app stops.
+
+func createStore() (service.VoteStore, error) {
+	switch *storeType {
+	case STORE_MEMORY:
+		if *storeSeed == "" {
+			logger.Log("store", STORE_MEMORY)
+			return service.NewMemoryVoteStore(), nil
+		}
+		logger.Log("store", STORE_MEMORY, "seed", *storeSeed)
+		return service.LoadMemoryVoteStore(*storeSeed)
+
+	case STORE_DATABASE:
+		switch getDatabaseDriver() {
+		case DATABASE_DRIVER_GOCQL:
+			// The store in the 'service' layer creates a single session at startup and
+			// shares it between all requests (see 'pkg/service/cassandra.go').
+			cluster, opts, err := getCassandraCluster()
+			if err != nil {
+				return nil, err
+			}
+
+			logger.Log("store", STORE_DATABASE, "database-url", *databaseURL, "database-local-dc", *databaseLocalDC, "database-keyspace", *databaseKeyspace,
+				"tls", cluster.SslOpts != nil, "cassandra-counters", *cassandraCounters)
+			return service.NewCassandraVoteStore(cluster, getCassandraMetrics(), opts...), nil
+
+		case DATABASE_DRIVER_SQLITE:
+			// For SQLite the 'database-url' is a file name, the default is not a good name.
+			filename := *databaseURL
+			if filename == DEFAULT_DATABASE_URL {
+				filename = DEFAULT_SQLITE_FILE
+			}
+			logger.Log("store", STORE_DATABASE, "database-driver", DATABASE_DRIVER_SQLITE, "database-url", filename)
+			return service.OpenSQLVoteStore(service.SQL_DRIVER_SQLITE, filename)
+
+		case DATABASE_DRIVER_POSTGRES:
+			// The URL contains password, it must not be logged.
+			logger.Log("store", STORE_DATABASE, "database-driver", DATABASE_DRIVER_POSTGRES)
+			return service.OpenSQLVoteStore(service.SQL_DRIVER_POSTGRES, *databaseURL)
+
+		default:
+			return nil, fmt.Errorf("unknown database driver %q", getDatabaseDriver())
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown store %q", *storeType)
+	}
+}
+
+/////////////////////////
+//
+// GET CASSANDRA CLUSTER
+//
+////////// called by createStore, runMigrate ---
+
+// Database (Apache Cassandra noSQL). Technically, 'cluster' is just a pointer to a struct
+// 'gocql.ClusterConfig', the connection is established later. The options are for the
+// store (keyspace, read/write consistency, ...), see 'pkg/service/cassandra.go'.
+
+func getCassandraCluster() (*gocql.ClusterConfig, []service.CassandraOption, error) {
+	read, err := gocql.ParseConsistencyWrapper(*readConsistency)
+	if err != nil {
+		return nil, nil, fmt.Errorf("database-read-consistency: %w", err)
+	}
+	write, err := gocql.ParseConsistencyWrapper(*writeConsistency)
+	if err != nil {
+		return nil, nil, fmt.Errorf("database-write-consistency: %w", err)
+	}
+	if err = service.CheckCassandraKeyspace(*databaseKeyspace); err != nil {
+		return nil, nil, err
+	}
+
+	// Contact points, the driver discovers the other nodes of the ring.
+	var hosts []string
+	for _, h := range strings.Split(*databaseURL, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, nil, errors.New("database-url: no Cassandra nodes")
+	}
+
+	cluster := gocql.NewCluster(hosts...)
+	cluster.Port = *databasePort
+	cluster.Consistency = write
+	cluster.NumConns = *databaseConns
+	cluster.Timeout = *databaseTimeout
+	cluster.ConnectTimeout = *databaseTimeout
+	cluster.ReconnectionPolicy = &gocql.ConstantReconnectionPolicy{MaxRetries: *reconnectRetries, Interval: *reconnectInterval}
+
+	// Token-aware routing sends a query directly to a node (replica) owning the data. With
+	// the local data center, the other data centers are used only if all local nodes are
+	// down (it's also required by LOCAL_QUORUM and LOCAL_ONE consistency levels).
+	if *databaseLocalDC != "" {
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.DCAwareRoundRobinPolicy(*databaseLocalDC))
+	} else {
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.RoundRobinHostPolicy())
+	}
+
+	if *databaseUser != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: *databaseUser,
+			Password: *databasePassword,
+		}
+	}
+
+	// TLS is enabled by the CA certificate (server auth) and/or the client certificate
+	// (if the nodes require client auth, i.e. 'require_client_auth' in 'cassandra.yaml').
+	if (*databaseCert == "") != (*databaseKey == "") {
+		return nil, nil, errors.New("database-cert and database-key must be used together")
+	}
+	if *databaseCA != "" || *databaseCert != "" {
+		cluster.SslOpts = &gocql.SslOptions{
+			CaPath:                 *databaseCA,
+			CertPath:               *databaseCert,
+			KeyPath:                *databaseKey,
+			EnableHostVerification: *databaseHostVerify,
+		}
+	}
+
+	opts := []service.CassandraOption{
+		service.WithKeyspace(*databaseKeyspace),
+		service.WithConsistency(read, write),
+	}
+	if *cassandraCounters {
+		opts = append(opts, service.WithCounterTable())
+	}
+
+	return cluster, opts, nil
+}
+
+///////////////////////
+//
+// GET DATABASE DRIVER
+//
+////////// called by createStore, runMigrate ---
+
+func getDatabaseDriver() string {
+	if *databaseDriver != "" {
+		return *databaseDriver
+	}
+
+	if strings.HasPrefix(*databaseURL, "postgres://") || strings.HasPrefix(*databaseURL, "postgresql://") {
+		return DATABASE_DRIVER_POSTGRES
+	}
+
+	return DATABASE_DRIVER_GOCQL
+}
+
+///////////////
+//
+// RUN MIGRATE
+//
+////////// called by main ---
+
+// It applies SQL schema migrations embedded in the binary (see 'pkg/service/migrations')
+// and returns the exit code. Apache Cassandra tables are created by CQL scripts (see
+// 'scripts/*.cql'), but the counts can be copied to the counter table 'polls.vote_counts'
+// (one-off action before the first start with 'cassandra-counters').
+
+func runMigrate() int {
+	var driver, dsn string
+	switch getDatabaseDriver() {
+	case DATABASE_DRIVER_SQLITE:
+		driver, dsn = service.SQL_DRIVER_SQLITE, *databaseURL
+		if dsn == DEFAULT_DATABASE_URL {
+			dsn = DEFAULT_SQLITE_FILE
+		}
+	case DATABASE_DRIVER_POSTGRES:
+		driver, dsn = service.SQL_DRIVER_POSTGRES, *databaseURL
+	case DATABASE_DRIVER_GOCQL:
+		cluster, opts, err := getCassandraCluster()
+		if err != nil {
+			logger.Log("migrate", DATABASE_DRIVER_GOCQL, "err", err)
+			return 1
+		}
+		n, err := service.MigrateCassandraCounts(context.Background(), cluster, opts...)
+		if err != nil {
+			logger.Log("migrate", DATABASE_DRIVER_GOCQL, "copied", n, "err", err)
+			return 1
+		}
+		logger.Log("migrate", DATABASE_DRIVER_GOCQL, "copied", n, "status", *databaseKeyspace+".vote_counts is up to date")
+		return 0
+	default:
+		logger.Log("migrate", getDatabaseDriver(), "err", "unknown database driver")
+		return 1
+	}
+
+	done, err := service.MigrateSQLVoteStore(context.Background(), driver, dsn)
+	for _, m := range done {
+		logger.Log("migrate", driver, "applied", m.Name)
+	}
+	if err != nil {
+		logger.Log("migrate", driver, "err", err)
+		return 1
+	}
+
+	logger.Log("migrate", driver, "applied", len(done), "status", "up to date")
+	return 0
+}
+
+/////////////
+//
+// RUN AUDIT
+//
+////////// called by main ---
+
+// It verifies the audit logs of the polls (see 'pkg/service/audit.go'): the chain of the
+// hashes, and the tallies recomputed from the log against the counts in the store. It returns
+// the exit code, 1 if any log is broken or diverges from the counts.
+
+func runAudit(ids []string) int {
+	if len(ids) == 0 {
+		logger.Log("audit", "", "err", "usage: vote-svc audit [flags] vote_id ...")
+		return 1
+	}
+
+	store, err := createStore()
+	if err != nil {
+		logger.Log("audit", "", "err", err)
+		return 1
+	}
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	code := 0
+	for _, id := range ids {
+		vote_id, err := strconv.Atoi(id)
+		if err != nil {
+			logger.Log("audit", id, "err", "bad vote_id")
+			code = 1
+			continue
+		}
+
+		r, err := service.VerifyAudit(context.Background(), store, vote_id)
+		if err != nil {
+			logger.Log("audit", vote_id, "err", err)
+			code = 1
+			continue
+		}
+
+		if r.Broken != 0 {
+			logger.Log("audit", vote_id, "broken", r.Broken, "reason", r.Reason)
+		}
+		for _, d := range r.Divergences {
+			logger.Log("audit", vote_id, "co_id", d.ContenderId, "logged", d.Logged, "counted", d.Counted, "status", "divergence")
+		}
+		if !r.OK() {
+			code = 1
+			continue
+		}
+		logger.Log("audit", vote_id, "entries", r.Entries, "head", r.Head, "status", "ok")
+	}
+	return code
+}
+
+//////////////
+//
+// RUN KEYGEN
+//
+////////// called by main ---
+
+// It generates the key pair of the encrypted polls (see 'pkg/service/elgamal.go'). The private
+// key is written to the new file (it's never overwritten) readable by the owner only, and should
+// be kept offline until the polls are closed; the public key is printed to stdout, it's the
+// 'public_key' of the polls. It returns the exit code.
+
+func runKeygen(args []string) int {
+	if len(args) != 1 {
+		logger.Log("keygen", "", "err", "usage: vote-svc keygen FILE")
+		return 1
+	}
+
+	private, public, err := service.GenerateElGamalKey()
+	if err != nil {
+		logger.Log("keygen", args[0], "err", err)
+		return 1
+	}
+
+	f, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		logger.Log("keygen", args[0], "err", err)
+		return 1
+	}
+	if _, err = fmt.Fprintln(f, private); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		logger.Log("keygen", args[0], "err", err)
+		return 1
+	}
+
+	fmt.Println(public)
+	logger.Log("keygen", args[0], "status", "private key is written, public key is printed")
+	return 0
+}
+
+/////////////
+//
+// RUN TALLY
+//
+////////// called by main ---
+
+// It decrypts the tally of the closed encrypted poll with the private key from KEY_FILE (see
+// 'runKeygen'), saves it with the proofs of the decryption, and verifies it the same way as
+// anybody could do without the key. It returns the exit code.
+
+func runTally(args []string) int {
+	if len(args) != 2 {
+		logger.Log("tally", "", "err", "usage: vote-svc tally [flags] KEY_FILE vote_id")
+		return 1
+	}
+	vote_id, err := strconv.Atoi(args[1])
+	if err != nil {
+		logger.Log("tally", args[1], "err", "bad vote_id")
+		return 1
+	}
+
+	key, err := os.ReadFile(args[0])
+	if err != nil {
+		logger.Log("tally", vote_id, "err", err)
+		return 1
+	}
+
+	store, err := createStore()
+	if err != nil {
+		logger.Log("tally", vote_id, "err", err)
+		return 1
+	}
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	ctx := context.Background()
+	t, err := service.TallyEncryptedVote(ctx, store, vote_id, strings.TrimSpace(string(key)))
+	if err != nil {
+		logger.Log("tally", vote_id, "err", err)
+		return 1
+	}
+	for _, c := range t.Contenders {
+		logger.Log("tally", vote_id, "co_id", c.ContenderId, "count", c.Count)
+	}
+
+	if err = service.VerifyTally(ctx, store, vote_id); err != nil {
+		logger.Log("tally", vote_id, "err", err)
+		return 1
+	}
+	logger.Log("tally", vote_id, "ballots", t.Ballots, "rejected", t.Rejected, "status", "ok")
+	return 0
+}
+
 //////////////////
 //
 // CREATE SERVICE
@@ -289,14 +733,61 @@ func getHttpListenerWithTLS(addr string) (net.Listener, error) {
 
 func defaultHttpOptions(logger log.Logger, tracer opentracinggo.Tracer) map[string][]kithttp.ServerOption {
 	options := map[string][]kithttp.ServerOption{
-		"GetVoteData":       {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetVoteData", logger))},
-		"GetVoteResults":    {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetVoteResults", logger))},
-		"UpdateVoteResults": {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "UpdateVoteResults", logger))},
-		"GetServiceStatus":  {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetServiceStatus", logger))},
+		"GetVoteData":         {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetVoteData", logger))},
+		"GetVoteResults":      {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetVoteResults", logger))},
+		"UpdateVoteResults":   {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "UpdateVoteResults", logger))},
+		"CastBallot":          {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "CastBallot", logger))},
+		"RetractVote":         {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "RetractVote", logger))},
+		"RateContenders":      {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "RateContenders", logger))},
+		"CastEncryptedBallot": {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "CastEncryptedBallot", logger))},
+		"GetReceipt":          {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetReceipt", logger))},
+		"GetAuditHead":        {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetAuditHead", logger))},
+		"GetCommitment":       {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetCommitment", logger))},
+		"GetReceiptProof":     {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetReceiptProof", logger))},
+		"GetServiceStatus":    {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GetServiceStatus", logger))},
+		"CreateVote":          {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "CreateVote", logger))},
+		"UpdateVote":          {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "UpdateVote", logger))},
+		"DeleteVote":          {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "DeleteVote", logger))},
+		"CloseVote":           {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "CloseVote", logger))},
+		"ReopenVote":          {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "ReopenVote", logger))},
+		"AddContender":        {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "AddContender", logger))},
+		"UpdateContender":     {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "UpdateContender", logger))},
+		"WithdrawContender":   {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "WithdrawContender", logger))},
+		"SetRoll":             {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "SetRoll", logger))},
+		"GenerateCodes":       {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "GenerateCodes", logger))},
+		"RevokeCodes":         {kithttp.ServerErrorEncoder(pkghttp.ErrorEncoder), kithttp.ServerErrorLogger(logger), kithttp.ServerBefore(opentracing.HTTPToContext(tracer, "RevokeCodes", logger))},
 	}
 	return options
 }
 
+///////////////////////
+//
+// GET SERVICE OPTIONS
+//
+////////// called by main +++
+
+// Without the keys, the polls with 'authenticate' reject all votes (HTTP 401).
+func getServiceOptions() ([]service.Option, error) {
+	if *jwtKeys == "" {
+		logger.Log("auth", "disabled", "reason", "jwt-keys is not set")
+		return nil, nil
+	}
+
+	var opts []service.JWTOption
+	if *jwtIssuer != "" {
+		opts = append(opts, service.WithIssuer(*jwtIssuer))
+	}
+	if *jwtAudience != "" {
+		opts = append(opts, service.WithAudience(*jwtAudience))
+	}
+
+	verifier, err := service.NewJWTVerifier(strings.Split(*jwtKeys, ","), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return []service.Option{service.WithTokenVerifier(verifier)}, nil
+}
+
 /////////////////////////
 //
 // GET SERVICE MIDDLEWARE
@@ -357,19 +848,58 @@ func addDefaultEndpointMiddleware(logger log.Logger, duration *prometheus.Summar
 		ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(*rateLimit), RATE_BURST_FACTOR*(*rateLimit)))}
 
 	mw["GetVoteResults"] = []kitendpoint.Middleware{
+		endpoint.AdminOverrideMiddleware(*adminToken), // The admin can see hidden results;
 		endpoint.LoggingMiddleware(log.With(logger, "method", "GetVoteResults")),
 		endpoint.InstrumentingMiddleware(duration.With("method", "GetVoteResults")),
 		ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(*rateLimit), RATE_BURST_FACTOR*(*rateLimit)))}
 
-	mw["UpdateVoteResults"] = []kitendpoint.Middleware{
-		endpoint.LoggingMiddleware(log.With(logger, "method", "UpdateVoteResults")),
-		endpoint.InstrumentingMiddleware(duration.With("method", "UpdateVoteResults")),
-		ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(*rateLimitPut), RATE_BURST_FACTOR*(*rateLimitPut)))}
+	mw["GetReceipt"] = []kitendpoint.Middleware{
+		endpoint.LoggingMiddleware(log.With(logger, "method", "GetReceipt")),
+		endpoint.InstrumentingMiddleware(duration.With("method", "GetReceipt")),
+		ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(*rateLimit), RATE_BURST_FACTOR*(*rateLimit)))}
+
+	mw["GetAuditHead"] = []kitendpoint.Middleware{
+		endpoint.LoggingMiddleware(log.With(logger, "method", "GetAuditHead")),
+		endpoint.InstrumentingMiddleware(duration.With("method", "GetAuditHead")),
+		ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(*rateLimit), RATE_BURST_FACTOR*(*rateLimit)))}
+
+	mw["GetCommitment"] = []kitendpoint.Middleware{
+		endpoint.LoggingMiddleware(log.With(logger, "method", "GetCommitment")),
+		endpoint.InstrumentingMiddleware(duration.With("method", "GetCommitment")),
+		ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(*rateLimit), RATE_BURST_FACTOR*(*rateLimit)))}
+
+	mw["GetReceiptProof"] = []kitendpoint.Middleware{
+		endpoint.LoggingMiddleware(log.With(logger, "method", "GetReceiptProof")),
+		endpoint.InstrumentingMiddleware(duration.With("method", "GetReceiptProof")),
+		ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(*rateLimit), RATE_BURST_FACTOR*(*rateLimit)))}
+
+	// All the ways to vote share the same rate limiter;
+	putLimiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(*rateLimitPut), RATE_BURST_FACTOR*(*rateLimitPut)))
+	for _, method := range []string{"UpdateVoteResults", "CastBallot", "RateContenders", "CastEncryptedBallot", "RetractVote"} {
+		mw[method] = []kitendpoint.Middleware{
+			endpoint.LoggingMiddleware(log.With(logger, "method", method)),
+			endpoint.InstrumentingMiddleware(duration.With("method", method)),
+			putLimiter}
+	}
 
 	mw["GetServiceStatus"] = []kitendpoint.Middleware{
 		endpoint.LoggingMiddleware(log.With(logger, "method", "GetServiceStatus")),
 		endpoint.InstrumentingMiddleware(duration.With("method", "GetServiceStatus")),
 		ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(HEALTH_RATE_LIMIT), RATE_BURST_FACTOR*(HEALTH_RATE_LIMIT)))}
+
+	// Admin endpoints share the same rate limiter, and they are disabled if there is no token.
+	if *adminToken == "" {
+		logger.Log("admin", "disabled", "reason", "admin-token is not set")
+	}
+	adminLimiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(ADMIN_RATE_LIMIT), RATE_BURST_FACTOR*(ADMIN_RATE_LIMIT)))
+	for _, method := range []string{"CreateVote", "UpdateVote", "DeleteVote", "CloseVote", "ReopenVote",
+		"AddContender", "UpdateContender", "WithdrawContender", "SetRoll", "GenerateCodes", "RevokeCodes"} {
+		mw[method] = []kitendpoint.Middleware{
+			endpoint.AdminMiddleware(*adminToken),
+			endpoint.LoggingMiddleware(log.With(logger, "method", method)),
+			endpoint.InstrumentingMiddleware(duration.With("method", method)),
+			adminLimiter}
+	}
 }
 
 /////////////////////////
@@ -392,6 +922,77 @@ func initMetricsEndpoint(g *group.Group) {
 	})
 }
 
+/////////////////////////
+//
+// GET CASSANDRA METRICS
+//
+////////// called by createStore ---
+
+// Database connection pool stats, see '/metrics' endpoint.
+func getCassandraMetrics() *service.CassandraMetrics {
+	return &service.CassandraMetrics{
+		Sessions: prometheus.NewCounterFrom(prometheus1.CounterOpts{
+			Help:      "The number of sessions created, i.e. (re)connects to the database cluster.",
+			Name:      "cassandra_sessions_total",
+			Namespace: "example",
+			Subsystem: "vote_svc",
+		}, []string{}),
+		SessionUp: prometheus.NewGaugeFrom(prometheus1.GaugeOpts{
+			Help:      "1 if the database session is open, 0 if it is not.",
+			Name:      "cassandra_session_up",
+			Namespace: "example",
+			Subsystem: "vote_svc",
+		}, []string{}),
+		Connects: prometheus.NewCounterFrom(prometheus1.CounterOpts{
+			Help:      "Connections to the database nodes.",
+			Name:      "cassandra_connects_total",
+			Namespace: "example",
+			Subsystem: "vote_svc",
+		}, []string{"success"}),
+		HostsUp: prometheus.NewGaugeFrom(prometheus1.GaugeOpts{
+			Help:      "The number of database nodes which are up.",
+			Name:      "cassandra_hosts_up",
+			Namespace: "example",
+			Subsystem: "vote_svc",
+		}, []string{}),
+		HostsDown: prometheus.NewGaugeFrom(prometheus1.GaugeOpts{
+			Help:      "The number of database nodes which are known, but down.",
+			Name:      "cassandra_hosts_down",
+			Namespace: "example",
+			Subsystem: "vote_svc",
+		}, []string{}),
+		Queries: prometheus.NewSummaryFrom(prometheus1.SummaryOpts{
+			Help:      "Database query duration in seconds.",
+			Name:      "cassandra_query_duration_seconds",
+			Namespace: "example",
+			Subsystem: "vote_svc",
+		}, []string{"success"}),
+	}
+}
+
+///////////////////////
+//
+// INIT STORE SHUTDOWN
+//
+////////// called by main +++
+
+// The store is closed (e.g. the database session) when the app stops.
+func initStoreShutdown(store service.VoteStore, g *group.Group) {
+	c, ok := store.(io.Closer)
+	if !ok {
+		return
+	}
+
+	done := make(chan struct{})
+	g.Add(func() error {
+		<-done
+		return nil
+	}, func(error) {
+		c.Close()
+		close(done)
+	})
+}
+
 /////////////////////////
 //
 // INIT CANCEL INTERRUPT
@@ -459,4 +1060,41 @@ func registerServiceWithConsul() {
 	}
 }
 
+///////////
+//
+// GET ENV
+//
+////////// called by cmdline flags +++
+
+// It returns the environment variable, or the default value if it's not set.
+func getEnv(name string, def string) string {
+	if s, ok := os.LookupEnv(name); ok && s != "" {
+		return s
+	}
+	return def
+}
+
+// The same for other types, the default value is also used if the variable is not valid.
+
+func getEnvInt(name string, def int) int {
+	if n, err := strconv.Atoi(getEnv(name, "")); err == nil {
+		return n
+	}
+	return def
+}
+
+func getEnvBool(name string, def bool) bool {
+	if b, err := strconv.ParseBool(getEnv(name, "")); err == nil {
+		return b
+	}
+	return def
+}
+
+func getEnvDuration(name string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(getEnv(name, "")); err == nil {
+		return d
+	}
+	return def
+}
+
 // --- END OF FILE ---
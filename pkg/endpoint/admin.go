@@ -0,0 +1,370 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package endpoint
+
+import (
+	"context"
+	"strconv"
+	service "vote_svc/pkg/service"
+
+	endpoint "github.com/go-kit/kit/endpoint"
+	"github.com/patrickmn/go-cache"
+)
+
+// Poll administration endpoints (see 'pkg/service/admin.go'). They are supposed to be
+// wrapped by 'AdminMiddleware'. Each change of the vote removes it from the cache, so
+// the clients see the changes immediately.
+
+/////////////////////////////
+//
+// MAKE CREATE VOTE ENDPOINT
+//
+/////////////////////////////
+
+// CreateVoteRequest collects the request parameters for the CreateVote method.
+type CreateVoteRequest struct {
+	Vote service.VoteData `json:"vote"`
+}
+
+// CreateVoteResponse collects the response parameters for the CreateVote method.
+type CreateVoteResponse struct {
+	V0 *service.VoteData `json:"v0"`
+	E1 error             `json:"e1"`
+}
+
+// MakeCreateVoteEndpoint returns an endpoint that invokes CreateVote on the service.
+func MakeCreateVoteEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateVoteRequest)
+		v0, e1 := s.CreateVote(ctx, req.Vote)
+		if e1 == nil {
+			invalidateVote(c, req.Vote.VoteId) // A 'not found' could be cached;
+		}
+		return CreateVoteResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r CreateVoteResponse) Failed() error {
+	return r.E1
+}
+
+/////////////////////////////
+//
+// MAKE UPDATE VOTE ENDPOINT
+//
+/////////////////////////////
+
+// UpdateVoteRequest collects the request parameters for the UpdateVote method.
+type UpdateVoteRequest struct {
+	VoteId int               `json:"vote_id"`
+	Patch  service.VotePatch `json:"patch"`
+}
+
+// UpdateVoteResponse collects the response parameters for the UpdateVote method.
+type UpdateVoteResponse struct {
+	V0 *service.VoteData `json:"v0"`
+	E1 error             `json:"e1"`
+}
+
+// MakeUpdateVoteEndpoint returns an endpoint that invokes UpdateVote on the service.
+func MakeUpdateVoteEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateVoteRequest)
+		v0, e1 := s.UpdateVote(ctx, req.VoteId, req.Patch)
+		invalidateVote(c, req.VoteId)
+		return UpdateVoteResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r UpdateVoteResponse) Failed() error {
+	return r.E1
+}
+
+/////////////////////////////
+//
+// MAKE DELETE VOTE ENDPOINT
+//
+/////////////////////////////
+
+// DeleteVoteRequest collects the request parameters for the DeleteVote method.
+type DeleteVoteRequest struct {
+	VoteId int `json:"vote_id"`
+}
+
+// DeleteVoteResponse collects the response parameters for the DeleteVote method.
+type DeleteVoteResponse struct {
+	E0 error `json:"e0"`
+}
+
+// MakeDeleteVoteEndpoint returns an endpoint that invokes DeleteVote on the service.
+func MakeDeleteVoteEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteVoteRequest)
+		e0 := s.DeleteVote(ctx, req.VoteId)
+		invalidateVote(c, req.VoteId)
+		return DeleteVoteResponse{E0: e0}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r DeleteVoteResponse) Failed() error {
+	return r.E0
+}
+
+////////////////////////////
+//
+// MAKE CLOSE VOTE ENDPOINT
+//
+////////////////////////////
+
+// CloseVoteRequest collects the request parameters for the CloseVote and ReopenVote methods.
+type CloseVoteRequest struct {
+	VoteId int `json:"vote_id"`
+}
+
+// CloseVoteResponse collects the response parameters for the CloseVote and ReopenVote methods.
+type CloseVoteResponse struct {
+	V0 *service.VoteData `json:"v0"`
+	E1 error             `json:"e1"`
+}
+
+// MakeCloseVoteEndpoint returns an endpoint that invokes CloseVote on the service.
+func MakeCloseVoteEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CloseVoteRequest)
+		v0, e1 := s.CloseVote(ctx, req.VoteId)
+		invalidateVote(c, req.VoteId)
+		return CloseVoteResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r CloseVoteResponse) Failed() error {
+	return r.E1
+}
+
+/////////////////////////////
+//
+// MAKE REOPEN VOTE ENDPOINT
+//
+/////////////////////////////
+
+// MakeReopenVoteEndpoint returns an endpoint that invokes ReopenVote on the service.
+func MakeReopenVoteEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CloseVoteRequest)
+		v0, e1 := s.ReopenVote(ctx, req.VoteId)
+		invalidateVote(c, req.VoteId)
+		return CloseVoteResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+///////////////////////////////
+//
+// MAKE ADD CONTENDER ENDPOINT
+//
+///////////////////////////////
+
+// AddContenderRequest collects the request parameters for the AddContender method.
+type AddContenderRequest struct {
+	VoteId    int               `json:"vote_id"`
+	Contender service.Contender `json:"contender"`
+}
+
+// AddContenderResponse collects the response parameters for the AddContender method.
+type AddContenderResponse struct {
+	V0 *service.VoteData `json:"v0"`
+	E1 error             `json:"e1"`
+}
+
+// MakeAddContenderEndpoint returns an endpoint that invokes AddContender on the service.
+func MakeAddContenderEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddContenderRequest)
+		v0, e1 := s.AddContender(ctx, req.VoteId, req.Contender)
+		invalidateVote(c, req.VoteId)
+		return AddContenderResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r AddContenderResponse) Failed() error {
+	return r.E1
+}
+
+//////////////////////////////////
+//
+// MAKE UPDATE CONTENDER ENDPOINT
+//
+//////////////////////////////////
+
+// UpdateContenderRequest collects the request parameters for the UpdateContender method.
+type UpdateContenderRequest struct {
+	VoteId int                    `json:"vote_id"`
+	CoId   int16                  `json:"co_id"`
+	Patch  service.ContenderPatch `json:"patch"`
+}
+
+// UpdateContenderResponse collects the response parameters for the UpdateContender method.
+type UpdateContenderResponse struct {
+	V0 *service.VoteData `json:"v0"`
+	E1 error             `json:"e1"`
+}
+
+// MakeUpdateContenderEndpoint returns an endpoint that invokes UpdateContender on the service.
+func MakeUpdateContenderEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateContenderRequest)
+		v0, e1 := s.UpdateContender(ctx, req.VoteId, req.CoId, req.Patch)
+		invalidateVote(c, req.VoteId)
+		return UpdateContenderResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r UpdateContenderResponse) Failed() error {
+	return r.E1
+}
+
+////////////////////////////////////
+//
+// MAKE WITHDRAW CONTENDER ENDPOINT
+//
+////////////////////////////////////
+
+// WithdrawContenderRequest collects the request parameters for the WithdrawContender method.
+type WithdrawContenderRequest struct {
+	VoteId int   `json:"vote_id"`
+	CoId   int16 `json:"co_id"`
+}
+
+// WithdrawContenderResponse collects the response parameters for the WithdrawContender method.
+type WithdrawContenderResponse struct {
+	V0 *service.VoteData `json:"v0"`
+	E1 error             `json:"e1"`
+}
+
+// MakeWithdrawContenderEndpoint returns an endpoint that invokes WithdrawContender on the service.
+func MakeWithdrawContenderEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WithdrawContenderRequest)
+		v0, e1 := s.WithdrawContender(ctx, req.VoteId, req.CoId)
+		invalidateVote(c, req.VoteId)
+		return WithdrawContenderResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r WithdrawContenderResponse) Failed() error {
+	return r.E1
+}
+
+//////////////////////////
+//
+// MAKE SET ROLL ENDPOINT
+//
+//////////////////////////
+
+// SetRollRequest collects the request parameters for the SetRoll method.
+type SetRollRequest struct {
+	VoteId  int      `json:"vote_id"`
+	Entries []string `json:"entries"`
+}
+
+// SetRollResponse collects the response parameters for the SetRoll method.
+type SetRollResponse struct {
+	V0 *service.RollStats `json:"v0"`
+	E1 error              `json:"e1"`
+}
+
+// MakeSetRollEndpoint returns an endpoint that invokes SetRoll on the service.
+func MakeSetRollEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SetRollRequest)
+		v0, e1 := s.SetRoll(ctx, req.VoteId, req.Entries)
+		invalidateVote(c, req.VoteId) // The results include the turnout;
+		return SetRollResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r SetRollResponse) Failed() error {
+	return r.E1
+}
+
+////////////////////////////////
+//
+// MAKE GENERATE CODES ENDPOINT
+//
+////////////////////////////////
+
+// GenerateCodesRequest collects the request parameters for the GenerateCodes method.
+type GenerateCodesRequest struct {
+	VoteId int `json:"vote_id"`
+	Count  int `json:"count"`
+}
+
+// GenerateCodesResponse collects the response parameters for the GenerateCodes method.
+type GenerateCodesResponse struct {
+	V0 []string `json:"v0"`
+	E1 error    `json:"e1"`
+}
+
+// MakeGenerateCodesEndpoint returns an endpoint that invokes GenerateCodes on the service.
+func MakeGenerateCodesEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GenerateCodesRequest)
+		v0, e1 := s.GenerateCodes(ctx, req.VoteId, req.Count)
+		invalidateVote(c, req.VoteId) // The vote data tells if the poll requires codes;
+		return GenerateCodesResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r GenerateCodesResponse) Failed() error {
+	return r.E1
+}
+
+//////////////////////////////
+//
+// MAKE REVOKE CODES ENDPOINT
+//
+//////////////////////////////
+
+// RevokeCodesRequest collects the request parameters for the RevokeCodes method.
+type RevokeCodesRequest struct {
+	VoteId     int                    `json:"vote_id"`
+	Revocation service.CodeRevocation `json:"revocation"`
+}
+
+// RevokeCodesResponse collects the response parameters for the RevokeCodes method.
+type RevokeCodesResponse struct {
+	V0 int64 `json:"v0"`
+	E1 error `json:"e1"`
+}
+
+// MakeRevokeCodesEndpoint returns an endpoint that invokes RevokeCodes on the service.
+func MakeRevokeCodesEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevokeCodesRequest)
+		v0, e1 := s.RevokeCodes(ctx, req.VoteId, req.Revocation)
+		return RevokeCodesResponse{V0: v0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r RevokeCodesResponse) Failed() error {
+	return r.E1
+}
+
+// It removes the vote data and the results from the cache (see 'MakeGetVoteDataEndpoint'
+// and 'MakeGetVoteResultsEndpoint' for the keys).
+func invalidateVote(c *cache.Cache, vote_id int) {
+	c.Delete(strconv.Itoa(vote_id))
+	c.Delete(strconv.Itoa(vote_id) + "r")
+}
+
+// --- END OF FILE ---
@@ -1,5 +1,5 @@
 //  Created : 2024-Mar-14
-// Modified : 2024-Apr-24
+// Modified : 2026-Oct-18
 
 package endpoint
 
@@ -31,6 +31,7 @@ type GetVoteDataResponse struct {
 }
 
 // MakeGetVoteDataEndpoint returns an endpoint that invokes GetVoteData on the service.
+// The state of the cached poll is recomputed for each request (see 'currentVote').
 func MakeGetVoteDataEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(GetVoteDataRequest)
@@ -38,13 +39,15 @@ func MakeGetVoteDataEndpoint(s service.VoteService, c *cache.Cache) endpoint.End
 		v0, ok := c.Get(strconv.Itoa(req.VoteId))
 		if ok {
 			return GetVoteDataResponse{
-				V0: v0.(*service.VoteData),
+				V0: currentVote(v0),
 				E1: nil,
 			}, nil
 		}
 
 		v0, e1 := s.GetVoteData(ctx, req.VoteId)
-		c.Set(strconv.Itoa(req.VoteId), v0, cache.DefaultExpiration)
+		if e1 == nil {
+			c.Set(strconv.Itoa(req.VoteId), v0, cache.DefaultExpiration)
+		}
 		return GetVoteDataResponse{
 			E1: e1,
 			V0: v0.(*service.VoteData),
@@ -57,6 +60,15 @@ func (r GetVoteDataResponse) Failed() error {
 	return r.E1
 }
 
+// The state depends on the clock (see 'VoteData.CurrentState'): the cached poll may have been
+// opened at 'opens_at' or closed at the deadline since it was cached. It returns a copy with the
+// current state, the cached poll is shared by the requests, so it's not changed.
+func currentVote(v0 interface{}) *service.VoteData {
+	vote := *v0.(*service.VoteData)
+	vote.State = vote.CurrentState(time.Now())
+	return &vote
+}
+
 //////////////////////////////////
 //
 // MAKE GET VOTE RESULTS ENDPOINT
@@ -75,21 +87,25 @@ type GetVoteResultsResponse struct {
 }
 
 // MakeGetVoteResultsEndpoint returns an endpoint that invokes GetVoteResults on the service.
+// The admin may see the results hidden from others, so the admin does not use the cache.
 func MakeGetVoteResultsEndpoint(s service.VoteService, c *cache.Cache) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(GetVoteResultsRequest)
+		admin := service.IsAdmin(ctx)
 
 		key := strconv.Itoa(req.VoteId) + "r"
 		v0, ok := c.Get(key)
-		if ok {
-			return GetVoteDataResponse{
-				V0: v0.(*service.VoteData),
+		if ok && !admin {
+			return GetVoteResultsResponse{
+				V0: currentVote(v0),
 				E1: nil,
 			}, nil
 		}
 
-		v0, e1 := s.GetVoteData(ctx, req.VoteId)
-		c.Set(key, v0, 10*time.Second)
+		v0, e1 := s.GetVoteResults(ctx, req.VoteId)
+		if e1 == nil && !admin {
+			c.Set(key, v0, 10*time.Second)
+		}
 		return GetVoteResultsResponse{
 			E1: e1,
 			V0: v0.(*service.VoteData),
@@ -113,27 +129,304 @@ type UpdateVoteResultsRequest struct {
 	VoteId      int    `json:"vote_id"`
 	ContenderId int16  `json:"co_id"`
 	UserId      string `json:"user_id"`
+	Code        string `json:"code"` // The invitation code, if the poll requires it;
 }
 
 // UpdateVoteResultsResponse collects the response parameters for the UpdateVoteResults method.
 type UpdateVoteResultsResponse struct {
-	E0 error `json:"e0"`
+	R0 *service.Receipt `json:"r0"` // The receipt of the vote, see 'GetReceipt';
+	E1 error            `json:"e1"`
 }
 
 // MakeUpdateVoteResultsEndpoint returns an endpoint that invokes UpdateVoteResults on the service.
 func MakeUpdateVoteResultsEndpoint(s service.VoteService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(UpdateVoteResultsRequest)
-		e0 := s.UpdateVoteResults(ctx, req.VoteId, req.ContenderId, req.UserId)
-		return UpdateVoteResultsResponse{E0: e0}, nil
+		if req.Code != "" {
+			ctx = service.WithVoteCode(ctx, req.Code)
+		}
+		r0, e1 := s.UpdateVoteResults(ctx, req.VoteId, req.ContenderId, req.UserId)
+		return UpdateVoteResultsResponse{R0: r0, E1: e1}, nil
 	}
 }
 
 // Failed implements Failer.
 func (r UpdateVoteResultsResponse) Failed() error {
+	return r.E1
+}
+
+/////////////////////////////
+//
+// MAKE CAST BALLOT ENDPOINT
+//
+/////////////////////////////
+
+// CastBallotRequest collects the request parameters for the CastBallot method.
+type CastBallotRequest struct {
+	VoteId       int     `json:"vote_id"`
+	ContenderIds []int16 `json:"co_ids"` // Up to 'max_choices' of the poll;
+	UserId       string  `json:"user_id"`
+	Code         string  `json:"code"` // The invitation code, if the poll requires it;
+}
+
+// CastBallotResponse collects the response parameters for the CastBallot method.
+type CastBallotResponse struct {
+	R0 *service.Receipt `json:"r0"` // The receipt of the vote, see 'GetReceipt';
+	E1 error            `json:"e1"`
+}
+
+// MakeCastBallotEndpoint returns an endpoint that invokes CastBallot on the service.
+func MakeCastBallotEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CastBallotRequest)
+		if req.Code != "" {
+			ctx = service.WithVoteCode(ctx, req.Code)
+		}
+		r0, e1 := s.CastBallot(ctx, req.VoteId, req.ContenderIds, req.UserId)
+		return CastBallotResponse{R0: r0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r CastBallotResponse) Failed() error {
+	return r.E1
+}
+
+//////////////////////////////
+//
+// MAKE RETRACT VOTE ENDPOINT
+//
+//////////////////////////////
+
+// RetractVoteRequest collects the request parameters for the RetractVote method.
+type RetractVoteRequest struct {
+	VoteId int    `json:"vote_id"`
+	UserId string `json:"user_id"`
+}
+
+// RetractVoteResponse collects the response parameters for the RetractVote method.
+type RetractVoteResponse struct {
+	E0 error `json:"e0"`
+}
+
+// MakeRetractVoteEndpoint returns an endpoint that invokes RetractVote on the service.
+func MakeRetractVoteEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RetractVoteRequest)
+		e0 := s.RetractVote(ctx, req.VoteId, req.UserId)
+		return RetractVoteResponse{E0: e0}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r RetractVoteResponse) Failed() error {
 	return r.E0
 }
 
+/////////////////////////////////
+//
+// MAKE RATE CONTENDERS ENDPOINT
+//
+/////////////////////////////////
+
+// RateContendersRequest collects the request parameters for the RateContenders method.
+type RateContendersRequest struct {
+	VoteId int           `json:"vote_id"`
+	Scores map[int16]int `json:"scores"` // co_id -> score, all the contenders of the poll;
+	UserId string        `json:"user_id"`
+	Code   string        `json:"code"` // The invitation code, if the poll requires it;
+}
+
+// RateContendersResponse collects the response parameters for the RateContenders method.
+type RateContendersResponse struct {
+	R0 *service.Receipt `json:"r0"` // The receipt of the vote, see 'GetReceipt';
+	E1 error            `json:"e1"`
+}
+
+// MakeRateContendersEndpoint returns an endpoint that invokes RateContenders on the service.
+func MakeRateContendersEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RateContendersRequest)
+		if req.Code != "" {
+			ctx = service.WithVoteCode(ctx, req.Code)
+		}
+		r0, e1 := s.RateContenders(ctx, req.VoteId, req.Scores, req.UserId)
+		return RateContendersResponse{R0: r0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r RateContendersResponse) Failed() error {
+	return r.E1
+}
+
+///////////////////////////////////////
+//
+// MAKE CAST ENCRYPTED BALLOT ENDPOINT
+//
+///////////////////////////////////////
+
+// CastEncryptedBallotRequest collects the request parameters for the CastEncryptedBallot method.
+type CastEncryptedBallotRequest struct {
+	VoteId int                     `json:"vote_id"`
+	Ballot service.EncryptedBallot `json:"ballot"` // See 'service.EncryptBallot';
+	UserId string                  `json:"user_id"`
+	Code   string                  `json:"code"` // The invitation code, if the poll requires it;
+}
+
+// CastEncryptedBallotResponse collects the response parameters for the CastEncryptedBallot method.
+type CastEncryptedBallotResponse struct {
+	R0 *service.Receipt `json:"r0"` // The receipt of the vote, see 'GetReceipt';
+	E1 error            `json:"e1"`
+}
+
+// MakeCastEncryptedBallotEndpoint returns an endpoint that invokes CastEncryptedBallot on the service.
+func MakeCastEncryptedBallotEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CastEncryptedBallotRequest)
+		if req.Code != "" {
+			ctx = service.WithVoteCode(ctx, req.Code)
+		}
+		r0, e1 := s.CastEncryptedBallot(ctx, req.VoteId, req.Ballot, req.UserId)
+		return CastEncryptedBallotResponse{R0: r0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r CastEncryptedBallotResponse) Failed() error {
+	return r.E1
+}
+
+/////////////////////////////
+//
+// MAKE GET RECEIPT ENDPOINT
+//
+/////////////////////////////
+
+// GetReceiptRequest collects the request parameters for the GetReceipt method.
+type GetReceiptRequest struct {
+	VoteId  int    `json:"vote_id"`
+	Receipt string `json:"receipt"`
+}
+
+// GetReceiptResponse collects the response parameters for the GetReceipt method.
+type GetReceiptResponse struct {
+	R0 *service.Receipt `json:"r0"`
+	E1 error            `json:"e1"`
+}
+
+// MakeGetReceiptEndpoint returns an endpoint that invokes GetReceipt on the service.
+// The receipts are not cached, a revisable vote may be changed at any time.
+func MakeGetReceiptEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetReceiptRequest)
+		r0, e1 := s.GetReceipt(ctx, req.VoteId, req.Receipt)
+		return GetReceiptResponse{R0: r0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r GetReceiptResponse) Failed() error {
+	return r.E1
+}
+
+////////////////////////////////
+//
+// MAKE GET AUDIT HEAD ENDPOINT
+//
+////////////////////////////////
+
+// GetAuditHeadRequest collects the request parameters for the GetAuditHead method.
+type GetAuditHeadRequest struct {
+	VoteId int `json:"vote_id"`
+}
+
+// GetAuditHeadResponse collects the response parameters for the GetAuditHead method.
+type GetAuditHeadResponse struct {
+	R0 *service.AuditHead `json:"r0"`
+	E1 error              `json:"e1"`
+}
+
+// MakeGetAuditHeadEndpoint returns an endpoint that invokes GetAuditHead on the service.
+// The head is not cached, it's changed by each vote.
+func MakeGetAuditHeadEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetAuditHeadRequest)
+		r0, e1 := s.GetAuditHead(ctx, req.VoteId)
+		return GetAuditHeadResponse{R0: r0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r GetAuditHeadResponse) Failed() error {
+	return r.E1
+}
+
+////////////////////////////////
+//
+// MAKE GET COMMITMENT ENDPOINT
+//
+////////////////////////////////
+
+// GetCommitmentRequest collects the request parameters for the GetCommitment method.
+type GetCommitmentRequest struct {
+	VoteId int `json:"vote_id"`
+}
+
+// GetCommitmentResponse collects the response parameters for the GetCommitment method.
+type GetCommitmentResponse struct {
+	R0 *service.Commitment `json:"r0"`
+	E1 error               `json:"e1"`
+}
+
+// MakeGetCommitmentEndpoint returns an endpoint that invokes GetCommitment on the service.
+// The commitment is not cached, the poll may be reopened.
+func MakeGetCommitmentEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetCommitmentRequest)
+		r0, e1 := s.GetCommitment(ctx, req.VoteId)
+		return GetCommitmentResponse{R0: r0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r GetCommitmentResponse) Failed() error {
+	return r.E1
+}
+
+///////////////////////////////////
+//
+// MAKE GET RECEIPT PROOF ENDPOINT
+//
+///////////////////////////////////
+
+// GetReceiptProofRequest collects the request parameters for the GetReceiptProof method.
+type GetReceiptProofRequest struct {
+	VoteId  int    `json:"vote_id"`
+	Receipt string `json:"receipt"`
+}
+
+// GetReceiptProofResponse collects the response parameters for the GetReceiptProof method.
+type GetReceiptProofResponse struct {
+	R0 *service.ReceiptProof `json:"r0"`
+	E1 error                 `json:"e1"`
+}
+
+// MakeGetReceiptProofEndpoint returns an endpoint that invokes GetReceiptProof on the service.
+// The proofs are not cached, as the receipts.
+func MakeGetReceiptProofEndpoint(s service.VoteService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetReceiptProofRequest)
+		r0, e1 := s.GetReceiptProof(ctx, req.VoteId, req.Receipt)
+		return GetReceiptProofResponse{R0: r0, E1: e1}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r GetReceiptProofResponse) Failed() error {
+	return r.E1
+}
+
 ////////////////////////////////////
 //
 // MAKE GET SERVICE STATUS ENDPOINT
@@ -11,20 +11,58 @@ import (
 // meant to be used as a helper struct, to collect all of the endpoints into a
 // single parameter.
 type Endpoints struct {
-	GetVoteDataEndpoint       endpoint.Endpoint
-	GetVoteResultsEndpoint    endpoint.Endpoint
-	UpdateVoteResultsEndpoint endpoint.Endpoint
-	GetServiceStatusEndpoint  endpoint.Endpoint
+	GetVoteDataEndpoint         endpoint.Endpoint
+	GetVoteResultsEndpoint      endpoint.Endpoint
+	UpdateVoteResultsEndpoint   endpoint.Endpoint
+	CastBallotEndpoint          endpoint.Endpoint
+	RateContendersEndpoint      endpoint.Endpoint
+	CastEncryptedBallotEndpoint endpoint.Endpoint
+	RetractVoteEndpoint         endpoint.Endpoint
+	GetReceiptEndpoint          endpoint.Endpoint
+	GetAuditHeadEndpoint        endpoint.Endpoint
+	GetCommitmentEndpoint       endpoint.Endpoint
+	GetReceiptProofEndpoint     endpoint.Endpoint
+	GetServiceStatusEndpoint    endpoint.Endpoint
+	CreateVoteEndpoint          endpoint.Endpoint
+	UpdateVoteEndpoint          endpoint.Endpoint
+	DeleteVoteEndpoint          endpoint.Endpoint
+	CloseVoteEndpoint           endpoint.Endpoint
+	ReopenVoteEndpoint          endpoint.Endpoint
+	AddContenderEndpoint        endpoint.Endpoint
+	UpdateContenderEndpoint     endpoint.Endpoint
+	WithdrawContenderEndpoint   endpoint.Endpoint
+	SetRollEndpoint             endpoint.Endpoint
+	GenerateCodesEndpoint       endpoint.Endpoint
+	RevokeCodesEndpoint         endpoint.Endpoint
 }
 
 // New returns a Endpoints struct that wraps the provided service, and wires in all of the
 // expected endpoint middlewares
 func New(s service.VoteService, c *cache.Cache, mdw map[string][]endpoint.Middleware) Endpoints {
 	eps := Endpoints{
-		GetServiceStatusEndpoint:  MakeGetServiceStatusEndpoint(s),
-		GetVoteDataEndpoint:       MakeGetVoteDataEndpoint(s, c),
-		GetVoteResultsEndpoint:    MakeGetVoteResultsEndpoint(s, c),
-		UpdateVoteResultsEndpoint: MakeUpdateVoteResultsEndpoint(s),
+		GetServiceStatusEndpoint:    MakeGetServiceStatusEndpoint(s),
+		GetVoteDataEndpoint:         MakeGetVoteDataEndpoint(s, c),
+		GetVoteResultsEndpoint:      MakeGetVoteResultsEndpoint(s, c),
+		UpdateVoteResultsEndpoint:   MakeUpdateVoteResultsEndpoint(s),
+		CastBallotEndpoint:          MakeCastBallotEndpoint(s),
+		RateContendersEndpoint:      MakeRateContendersEndpoint(s),
+		CastEncryptedBallotEndpoint: MakeCastEncryptedBallotEndpoint(s),
+		RetractVoteEndpoint:         MakeRetractVoteEndpoint(s),
+		GetReceiptEndpoint:          MakeGetReceiptEndpoint(s),
+		GetAuditHeadEndpoint:        MakeGetAuditHeadEndpoint(s),
+		GetCommitmentEndpoint:       MakeGetCommitmentEndpoint(s),
+		GetReceiptProofEndpoint:     MakeGetReceiptProofEndpoint(s),
+		CreateVoteEndpoint:          MakeCreateVoteEndpoint(s, c),
+		UpdateVoteEndpoint:          MakeUpdateVoteEndpoint(s, c),
+		DeleteVoteEndpoint:          MakeDeleteVoteEndpoint(s, c),
+		CloseVoteEndpoint:           MakeCloseVoteEndpoint(s, c),
+		ReopenVoteEndpoint:          MakeReopenVoteEndpoint(s, c),
+		AddContenderEndpoint:        MakeAddContenderEndpoint(s, c),
+		UpdateContenderEndpoint:     MakeUpdateContenderEndpoint(s, c),
+		WithdrawContenderEndpoint:   MakeWithdrawContenderEndpoint(s, c),
+		SetRollEndpoint:             MakeSetRollEndpoint(s, c),
+		GenerateCodesEndpoint:       MakeGenerateCodesEndpoint(s, c),
+		RevokeCodesEndpoint:         MakeRevokeCodesEndpoint(s),
 	}
 	for _, m := range mdw["GetVoteData"] {
 		eps.GetVoteDataEndpoint = m(eps.GetVoteDataEndpoint)
@@ -35,8 +73,65 @@ func New(s service.VoteService, c *cache.Cache, mdw map[string][]endpoint.Middle
 	for _, m := range mdw["UpdateVoteResults"] {
 		eps.UpdateVoteResultsEndpoint = m(eps.UpdateVoteResultsEndpoint)
 	}
+	for _, m := range mdw["CastBallot"] {
+		eps.CastBallotEndpoint = m(eps.CastBallotEndpoint)
+	}
+	for _, m := range mdw["RateContenders"] {
+		eps.RateContendersEndpoint = m(eps.RateContendersEndpoint)
+	}
+	for _, m := range mdw["CastEncryptedBallot"] {
+		eps.CastEncryptedBallotEndpoint = m(eps.CastEncryptedBallotEndpoint)
+	}
+	for _, m := range mdw["RetractVote"] {
+		eps.RetractVoteEndpoint = m(eps.RetractVoteEndpoint)
+	}
+	for _, m := range mdw["GetReceipt"] {
+		eps.GetReceiptEndpoint = m(eps.GetReceiptEndpoint)
+	}
+	for _, m := range mdw["GetAuditHead"] {
+		eps.GetAuditHeadEndpoint = m(eps.GetAuditHeadEndpoint)
+	}
+	for _, m := range mdw["GetCommitment"] {
+		eps.GetCommitmentEndpoint = m(eps.GetCommitmentEndpoint)
+	}
+	for _, m := range mdw["GetReceiptProof"] {
+		eps.GetReceiptProofEndpoint = m(eps.GetReceiptProofEndpoint)
+	}
 	for _, m := range mdw["GetServiceStatus"] {
 		eps.GetServiceStatusEndpoint = m(eps.GetServiceStatusEndpoint)
 	}
+	for _, m := range mdw["CreateVote"] {
+		eps.CreateVoteEndpoint = m(eps.CreateVoteEndpoint)
+	}
+	for _, m := range mdw["UpdateVote"] {
+		eps.UpdateVoteEndpoint = m(eps.UpdateVoteEndpoint)
+	}
+	for _, m := range mdw["DeleteVote"] {
+		eps.DeleteVoteEndpoint = m(eps.DeleteVoteEndpoint)
+	}
+	for _, m := range mdw["CloseVote"] {
+		eps.CloseVoteEndpoint = m(eps.CloseVoteEndpoint)
+	}
+	for _, m := range mdw["ReopenVote"] {
+		eps.ReopenVoteEndpoint = m(eps.ReopenVoteEndpoint)
+	}
+	for _, m := range mdw["AddContender"] {
+		eps.AddContenderEndpoint = m(eps.AddContenderEndpoint)
+	}
+	for _, m := range mdw["UpdateContender"] {
+		eps.UpdateContenderEndpoint = m(eps.UpdateContenderEndpoint)
+	}
+	for _, m := range mdw["WithdrawContender"] {
+		eps.WithdrawContenderEndpoint = m(eps.WithdrawContenderEndpoint)
+	}
+	for _, m := range mdw["SetRoll"] {
+		eps.SetRollEndpoint = m(eps.SetRollEndpoint)
+	}
+	for _, m := range mdw["GenerateCodes"] {
+		eps.GenerateCodesEndpoint = m(eps.GenerateCodesEndpoint)
+	}
+	for _, m := range mdw["RevokeCodes"] {
+		eps.RevokeCodesEndpoint = m(eps.RevokeCodesEndpoint)
+	}
 	return eps
 }
@@ -1,5 +1,5 @@
 //  Created : 2024-Mar-27
-// Modified : 2024-Apr-24
+// Modified : 2026-Oct-18
 
 package endpoint
 
@@ -18,25 +18,42 @@ const TESTDATA_MESSAGE = "Vote for the best candidate"
 const TESTDATA_DEADLINE = "2030-04-29T22:00:00Z"
 const TESTDATA_USER_ID = "5287eb7d-4813-49e7-8e2d-c8a6ce8b3c4c"
 const TESTDATA_CO_INFO = "Good Person "
+const TESTDATA_RECEIPT = "0d9c2f5e-6a7b-4c1d-8e2f-3a4b5c6d7e8f"
 
 var voteGetVoteDataMock func(ctx context.Context, vote_id int) (*service.VoteData, error)
+var voteGetVoteResultsMock func(ctx context.Context, vote_id int) (*service.VoteData, error)
 var voteUpdateVoteResultsMock func(ctx context.Context, vote_id int, co_id int16, user_id string) error
 var voteGetServiceStatusMock func(ctx context.Context) *service.HealthStatus
+var voteDeleteVoteMock func(ctx context.Context, vote_id int) error
 
-type voteServiceMock struct{}
+// The methods which are not mocked below are not supposed to be called (nil VoteService).
+type voteServiceMock struct {
+	service.VoteService
+}
 
 func (b voteServiceMock) GetVoteData(ctx context.Context, vote_id int) (*service.VoteData, error) {
 	return voteGetVoteDataMock(ctx, vote_id)
 }
 
-func (b voteServiceMock) UpdateVoteResults(ctx context.Context, vote_id int, co_id int16, user_id string) error {
-	return voteUpdateVoteResultsMock(ctx, vote_id, co_id, user_id)
+func (b voteServiceMock) GetVoteResults(ctx context.Context, vote_id int) (*service.VoteData, error) {
+	return voteGetVoteResultsMock(ctx, vote_id)
+}
+
+func (b voteServiceMock) UpdateVoteResults(ctx context.Context, vote_id int, co_id int16, user_id string) (*service.Receipt, error) {
+	if err := voteUpdateVoteResultsMock(ctx, vote_id, co_id, user_id); err != nil {
+		return nil, err
+	}
+	return &service.Receipt{Id: TESTDATA_RECEIPT, VoteId: vote_id}, nil
 }
 
 func (b voteServiceMock) GetServiceStatus(ctx context.Context) *service.HealthStatus {
 	return voteGetServiceStatusMock(ctx)
 }
 
+func (b voteServiceMock) DeleteVote(ctx context.Context, vote_id int) error {
+	return voteDeleteVoteMock(ctx, vote_id)
+}
+
 func newServiceMock([]service.Middleware) service.VoteService {
 	return &voteServiceMock{}
 }
@@ -108,6 +125,18 @@ func TestMakeGetVoteDataEndpoint(t *testing.T) {
 				t.Errorf("%v (case # 2) failed, err %v, must be %v", testinfo, v.E1, service.ErrNotFound)
 			}
 		}
+
+		// Case 3: the cached poll has passed the deadline, it's closed, but the cache is not changed;
+		vote_id = 3
+		cached := &service.VoteData{VoteId: vote_id, State: service.VOTE_STATE_OPEN, Deadline: time.Now().Add(-time.Minute)}
+		memCache.Set("3", cached, cache.DefaultExpiration)
+		r, _ = endpoint(context.Background(), GetVoteDataRequest{VoteId: vote_id})
+		if v, ok := r.(GetVoteDataResponse); !ok || v.E1 != nil || v.V0.State != service.VOTE_STATE_CLOSED {
+			t.Errorf("%v (case # 3) failed, response %+v, state must be %v", testinfo, r, service.VOTE_STATE_CLOSED)
+		}
+		if cached.State != service.VOTE_STATE_OPEN {
+			t.Errorf("%v (case # 3) failed, cached state %v, must be %v", testinfo, cached.State, service.VOTE_STATE_OPEN)
+		}
 	})
 }
 
@@ -125,7 +154,7 @@ func TestMakeGetVoteResultsEndpoint(t *testing.T) {
 	var deadline, _ = time.Parse(time.RFC3339, TESTDATA_DEADLINE)
 
 	t.Run(testinfo, func(t *testing.T) {
-		voteGetVoteDataMock = func(_ context.Context, vote_id int) (*service.VoteData, error) {
+		voteGetVoteResultsMock = func(_ context.Context, vote_id int) (*service.VoteData, error) {
 			if vote_id == 1 {
 				contenders := make([]service.Contender, 2)
 				contenders[0] = service.Contender{
@@ -205,36 +234,36 @@ func TestMakeUpdateVoteResultsEndpoint(t *testing.T) {
 				return service.ErrInternalServerError
 			}
 		}
-		// Case 1: vote_id = 1, co_id = 1, user_id != TESTDATA_USER_ID, service returns 'nil';
+		// Case 1: vote_id = 1, co_id = 1, user_id != TESTDATA_USER_ID, service returns the receipt;
 		good_user_id := "a8597900-9aa0-40d9-9dcc-ff1f4210d7d8"
 		r, _ := endpoint(context.Background(), UpdateVoteResultsRequest{VoteId: 1, ContenderId: 1, UserId: good_user_id})
 		if v, ok := r.(UpdateVoteResultsResponse); ok {
-			if v.E0 != nil {
-				t.Errorf("%v (case # 1) failed, err %v (must be nil)", testinfo, v.E0)
+			if v.E1 != nil || v.R0 == nil || v.R0.Id != TESTDATA_RECEIPT {
+				t.Errorf("%v (case # 1) failed, receipt %+v, err %v (must be nil)", testinfo, v.R0, v.E1)
 			}
 		}
 
 		// Case 2: vote_id = 1, co_id = 1, user_id == TESTDATA_USER_ID, service returns ErrForbidden;
 		r, _ = endpoint(context.Background(), UpdateVoteResultsRequest{VoteId: 1, ContenderId: 1, UserId: TESTDATA_USER_ID})
 		if v, ok := r.(UpdateVoteResultsResponse); ok {
-			if v.E0 != service.ErrForbidden {
-				t.Errorf("%v (case # 2) failed, err %v (must be %v)", testinfo, v.E0, service.ErrForbidden)
+			if v.E1 != service.ErrForbidden {
+				t.Errorf("%v (case # 2) failed, err %v (must be %v)", testinfo, v.E1, service.ErrForbidden)
 			}
 		}
 
 		// Case 3: vote_id = 2, co_id = 1, user_id != TESTDATA_USER_ID, service returns ErrBadRequest;
 		r, _ = endpoint(context.Background(), UpdateVoteResultsRequest{VoteId: 2, ContenderId: 1, UserId: good_user_id})
 		if v, ok := r.(UpdateVoteResultsResponse); ok {
-			if v.E0 != service.ErrBadRequest {
-				t.Errorf("%v (case # 3) failed, err %v (must be %v)", testinfo, v.E0, service.ErrBadRequest)
+			if v.E1 != service.ErrBadRequest {
+				t.Errorf("%v (case # 3) failed, err %v (must be %v)", testinfo, v.E1, service.ErrBadRequest)
 			}
 		}
 
 		// Case 4: vote_id = 3, co_id = 1, user_id != TESTDATA_USER_ID, service returns ErrInternalServerError;
 		r, _ = endpoint(context.Background(), UpdateVoteResultsRequest{VoteId: 3, ContenderId: 1, UserId: good_user_id})
 		if v, ok := r.(UpdateVoteResultsResponse); ok {
-			if v.E0 != service.ErrInternalServerError {
-				t.Errorf("%v (case # 4) failed, err %v (must be %v)", testinfo, v.E0, service.ErrInternalServerError)
+			if v.E1 != service.ErrInternalServerError {
+				t.Errorf("%v (case # 4) failed, err %v (must be %v)", testinfo, v.E1, service.ErrInternalServerError)
 			}
 		}
 	})
@@ -309,4 +338,50 @@ func TestMakeGetServiceStatusEndpoint(t *testing.T) {
 	})
 }
 
+//////////////////////////////////
+//
+// TEST MAKE DELETE VOTE ENDPOINT
+//
+//////////////////////////////////
+
+func TestMakeDeleteVoteEndpoint(t *testing.T) {
+	testinfo := "test # 5: DeleteVote endpoint"
+	memCache := cache.New(5*time.Second, 10*time.Second)
+	svc := newServiceMock([]service.Middleware{})
+	endpoint := AdminMiddleware("secret")(MakeDeleteVoteEndpoint(svc, memCache))
+
+	voteDeleteVoteMock = func(ctx context.Context, vote_id int) error {
+		if !service.IsAdmin(ctx) {
+			return service.ErrUnauthorized
+		}
+		return nil
+	}
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case # 1: no token or wrong token, the service is not called;
+		for _, token := range []string{"", "Secret"} {
+			ctx := service.WithBearerToken(context.Background(), token)
+			_, err := endpoint(ctx, DeleteVoteRequest{VoteId: 1})
+			if err != service.ErrUnauthorized {
+				t.Errorf("%v (case # 1) failed, err %v, must be %v", testinfo, err, service.ErrUnauthorized)
+			}
+		}
+
+		// Case # 2: good token, the vote is removed from the cache;
+		memCache.Set("1", &service.VoteData{VoteId: 1}, cache.DefaultExpiration)
+		memCache.Set("1r", &service.VoteData{VoteId: 1}, cache.DefaultExpiration)
+		ctx := service.WithBearerToken(context.Background(), "secret")
+		r, err := endpoint(ctx, DeleteVoteRequest{VoteId: 1})
+		if v, ok := r.(DeleteVoteResponse); !ok || err != nil || v.E0 != nil {
+			t.Errorf("%v (case # 2) failed, response %v, err %v", testinfo, r, err)
+		}
+		if _, ok := memCache.Get("1"); ok {
+			t.Errorf("%v (case # 2) failed, vote data is still in the cache", testinfo)
+		}
+		if _, ok := memCache.Get("1r"); ok {
+			t.Errorf("%v (case # 2) failed, vote results are still in the cache", testinfo)
+		}
+	})
+}
+
 // --- END OF FILE ---
@@ -2,8 +2,10 @@ package endpoint
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"time"
+	service "vote_svc/pkg/service"
 
 	endpoint "github.com/go-kit/kit/endpoint"
 	metrics "github.com/go-kit/kit/metrics"
@@ -37,3 +39,34 @@ func LoggingMiddleware(logger log.Logger) endpoint.Middleware {
 		}
 	}
 }
+
+// AdminMiddleware returns an endpoint middleware that lets only the admin in: the
+// bearer token of the request (see 'service.WithBearerToken') must be equal to the
+// 'token'. The context of the admin is marked by 'service.WithAdmin'. If the 'token'
+// is empty, nobody is the admin, i.e. the admin endpoints are disabled.
+func AdminMiddleware(token string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			got := service.BearerToken(ctx)
+			if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				return nil, service.ErrUnauthorized
+			}
+			return next(service.WithAdmin(ctx), request)
+		}
+	}
+}
+
+// AdminOverrideMiddleware is the same as 'AdminMiddleware', but it lets everybody in: the
+// context is marked by 'service.WithAdmin' only if the token is good, so the admin can see
+// more than others (e.g. the results hidden by 'allow_results').
+func AdminOverrideMiddleware(token string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			got := service.BearerToken(ctx)
+			if token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				ctx = service.WithAdmin(ctx)
+			}
+			return next(ctx, request)
+		}
+	}
+}
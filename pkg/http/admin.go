@@ -0,0 +1,407 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	endpoint "vote_svc/pkg/endpoint"
+	service "vote_svc/pkg/service"
+
+	http1 "github.com/go-kit/kit/transport/http"
+)
+
+// Poll administration handlers. The admin is authenticated by the bearer token
+// ('Authorization: Bearer ...'), see 'AdminMiddleware' in 'pkg/endpoint'.
+
+// The size of the roll CSV is limited, it's ~100K voters identified by hashes.
+const MAX_ROLL_SIZE = 8 << 20
+
+////////////////////////////
+//
+// MAKE CREATE VOTE HANDLER
+//
+////////////////////////////
+
+func makeCreateVoteHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("POST /admin/votes", http1.NewServer(
+		endpoints.CreateVoteEndpoint,
+		decodeCreateVoteRequest,
+		encodeCreateVoteResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+// The request body is 'VoteData' with contenders (the counts are ignored).
+func decodeCreateVoteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := endpoint.CreateVoteRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req.Vote); err != nil {
+		return req, service.ErrBadRequest
+	}
+	return req, nil
+}
+
+// The response is the new vote with HTTP Status 201.
+func encodeCreateVoteResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(response)
+}
+
+////////////////////////////
+//
+// MAKE UPDATE VOTE HANDLER
+//
+////////////////////////////
+
+func makeUpdateVoteHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("PATCH /admin/votes/{id}", http1.NewServer(
+		endpoints.UpdateVoteEndpoint,
+		decodeUpdateVoteRequest,
+		encodeResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+// The request body contains only the fields to be changed (see 'service.VotePatch').
+func decodeUpdateVoteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.UpdateVoteRequest{}, service.ErrBadRequest
+	}
+
+	req := endpoint.UpdateVoteRequest{VoteId: id}
+	if err = json.NewDecoder(r.Body).Decode(&req.Patch); err != nil {
+		return req, service.ErrBadRequest
+	}
+	return req, nil
+}
+
+////////////////////////////
+//
+// MAKE DELETE VOTE HANDLER
+//
+////////////////////////////
+
+func makeDeleteVoteHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("DELETE /admin/votes/{id}", http1.NewServer(
+		endpoints.DeleteVoteEndpoint,
+		decodeDeleteVoteRequest,
+		encodeResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+func decodeDeleteVoteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.DeleteVoteRequest{}, service.ErrBadRequest
+	}
+	return endpoint.DeleteVoteRequest{VoteId: id}, nil
+}
+
+///////////////////////////
+//
+// MAKE CLOSE VOTE HANDLER
+//
+///////////////////////////
+
+func makeCloseVoteHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("POST /admin/votes/{id}/close", http1.NewServer(
+		endpoints.CloseVoteEndpoint,
+		decodeCloseVoteRequest,
+		encodeResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+////////////////////////////
+//
+// MAKE REOPEN VOTE HANDLER
+//
+////////////////////////////
+
+func makeReopenVoteHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("POST /admin/votes/{id}/reopen", http1.NewServer(
+		endpoints.ReopenVoteEndpoint,
+		decodeCloseVoteRequest,
+		encodeResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+// There is no body, the same request is used to close and to reopen the vote.
+func decodeCloseVoteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.CloseVoteRequest{}, service.ErrBadRequest
+	}
+	return endpoint.CloseVoteRequest{VoteId: id}, nil
+}
+
+//////////////////////////////
+//
+// MAKE ADD CONTENDER HANDLER
+//
+//////////////////////////////
+
+func makeAddContenderHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("POST /admin/votes/{id}/contenders", http1.NewServer(
+		endpoints.AddContenderEndpoint,
+		decodeAddContenderRequest,
+		encodeCreateVoteResponse, // 201, the same as a new vote;
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+// The request body is 'Contender' (the count is ignored).
+func decodeAddContenderRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.AddContenderRequest{}, service.ErrBadRequest
+	}
+
+	req := endpoint.AddContenderRequest{VoteId: id}
+	if err = json.NewDecoder(r.Body).Decode(&req.Contender); err != nil {
+		return req, service.ErrBadRequest
+	}
+	return req, nil
+}
+
+/////////////////////////////////
+//
+// MAKE UPDATE CONTENDER HANDLER
+//
+/////////////////////////////////
+
+func makeUpdateContenderHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("PATCH /admin/votes/{id}/contenders/{co_id}", http1.NewServer(
+		endpoints.UpdateContenderEndpoint,
+		decodeUpdateContenderRequest,
+		encodeResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+// The request body contains only the fields to be changed (see 'service.ContenderPatch').
+func decodeUpdateContenderRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, co_id, err := contenderPathValues(r)
+	if err != nil {
+		return endpoint.UpdateContenderRequest{}, err
+	}
+
+	req := endpoint.UpdateContenderRequest{VoteId: id, CoId: co_id}
+	if err = json.NewDecoder(r.Body).Decode(&req.Patch); err != nil {
+		return req, service.ErrBadRequest
+	}
+	return req, nil
+}
+
+///////////////////////////////////
+//
+// MAKE WITHDRAW CONTENDER HANDLER
+//
+///////////////////////////////////
+
+// The contender is not deleted (its votes stay in the results), so it's not DELETE.
+func makeWithdrawContenderHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("POST /admin/votes/{id}/contenders/{co_id}/withdraw", http1.NewServer(
+		endpoints.WithdrawContenderEndpoint,
+		decodeWithdrawContenderRequest,
+		encodeResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+func decodeWithdrawContenderRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, co_id, err := contenderPathValues(r)
+	if err != nil {
+		return endpoint.WithdrawContenderRequest{}, err
+	}
+	return endpoint.WithdrawContenderRequest{VoteId: id, CoId: co_id}, nil
+}
+
+/////////////////////////
+//
+// MAKE SET ROLL HANDLER
+//
+/////////////////////////
+
+// The roll is replaced as a whole, so it's PUT.
+func makeSetRollHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("PUT /admin/votes/{id}/roll", http1.NewServer(
+		endpoints.SetRollEndpoint,
+		decodeSetRollRequest,
+		encodeResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+// The request body is CSV, the first column is the voter identifier or its hash (see
+// 'service.ParseRollCSV'). The empty body removes the roll.
+func decodeSetRollRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.SetRollRequest{}, service.ErrBadRequest
+	}
+
+	entries, err := service.ParseRollCSV(http.MaxBytesReader(nil, r.Body, MAX_ROLL_SIZE))
+	if err != nil {
+		return endpoint.SetRollRequest{}, service.ErrBadRequest
+	}
+	return endpoint.SetRollRequest{VoteId: id, Entries: entries}, nil
+}
+
+///////////////////////////////
+//
+// MAKE GENERATE CODES HANDLER
+//
+///////////////////////////////
+
+// The codes are returned once, as JSON or as CSV ('?format=csv', a file to hand them out).
+func makeGenerateCodesHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("POST /admin/votes/{id}/codes", http1.NewServer(
+		endpoints.GenerateCodesEndpoint,
+		decodeGenerateCodesRequest,
+		encodeGenerateCodesResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext), http1.ServerBefore(formatToContext))...))
+}
+
+// The request body is '{"count": N}'.
+func decodeGenerateCodesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.GenerateCodesRequest{}, service.ErrBadRequest
+	}
+
+	req := endpoint.GenerateCodesRequest{VoteId: id}
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, service.ErrBadRequest
+	}
+	req.VoteId = id // It's the path, not the body;
+	return req, nil
+}
+
+// The response is HTTP Status 201 with the codes, see 'makeGenerateCodesHandler'.
+func encodeGenerateCodesResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	res, ok := response.(endpoint.GenerateCodesResponse)
+	if !ok || res.E1 != nil || ctx.Value(formatKey) != "csv" {
+		return encodeCreateVoteResponse(ctx, w, response)
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="codes.csv"`)
+	w.WriteHeader(http.StatusCreated)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"code"})
+	for _, code := range res.V0 {
+		cw.Write([]string{code})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+/////////////////////////////
+//
+// MAKE REVOKE CODES HANDLER
+//
+/////////////////////////////
+
+func makeRevokeCodesHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("POST /admin/votes/{id}/codes/revoke", http1.NewServer(
+		endpoints.RevokeCodesEndpoint,
+		decodeRevokeCodesRequest,
+		encodeResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...))
+}
+
+// The request body is '{"codes": [...]}' or '{"all": true}' (see 'service.CodeRevocation').
+func decodeRevokeCodesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.RevokeCodesRequest{}, service.ErrBadRequest
+	}
+
+	req := endpoint.RevokeCodesRequest{VoteId: id}
+	if err = json.NewDecoder(r.Body).Decode(&req.Revocation); err != nil {
+		return req, service.ErrBadRequest
+	}
+	return req, nil
+}
+
+// It parses '{id}' and '{co_id}' of the path.
+func contenderPathValues(r *http.Request) (int, int16, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, service.ErrBadRequest
+	}
+
+	co_id, err := strconv.ParseInt(r.PathValue("co_id"), 10, 16)
+	if err != nil {
+		return 0, 0, service.ErrBadRequest
+	}
+	return id, int16(co_id), nil
+}
+
+type formatKeyType struct{}
+
+// The key of the response format ('?format=...') in the context.
+var formatKey formatKeyType
+
+// It moves the query parameter 'format' into the context, the encoder has no request.
+func formatToContext(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, formatKey, r.URL.Query().Get("format"))
+}
+
+///////////////////////////
+//
+// BEARER TOKEN TO CONTEXT
+//
+///////////////////////////
+
+// BearerTokenToContext is a transport/http.RequestFunc, it moves the token from the header
+// 'Authorization: Bearer ...' into the context (see 'service.WithBearerToken').
+func BearerTokenToContext(ctx context.Context, r *http.Request) context.Context {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return ctx
+	}
+	return service.WithBearerToken(ctx, strings.TrimSpace(auth[7:]))
+}
+
+// It encodes any response as JSON (the same as other 'encode...Response' functions).
+func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,166 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	endpoint "vote_svc/pkg/endpoint"
+	"vote_svc/pkg/service"
+
+	endpoint1 "github.com/go-kit/kit/endpoint"
+	http1 "github.com/go-kit/kit/transport/http"
+)
+
+const ADMIN_TOKEN = "b3f1c2d4e5"
+
+// It creates the vote if the bearer token is good (see 'AdminMiddleware').
+func makeCreateVoteEndpointMock() endpoint1.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if service.BearerToken(ctx) != ADMIN_TOKEN {
+			return nil, service.ErrUnauthorized
+		}
+		req := request.(endpoint.CreateVoteRequest)
+		return endpoint.CreateVoteResponse{V0: &req.Vote}, nil
+	}
+}
+
+///////////////////////////////////
+//
+// TEST HTTP TRANSPORT CREATE VOTE
+//
+///////////////////////////////////
+
+func TestHttpTransportCreateVote(t *testing.T) {
+	testinfo := "test # 5: CreateVote"
+	eps := endpoint.Endpoints{CreateVoteEndpoint: makeCreateVoteEndpointMock()}
+	m := http.NewServeMux()
+	makeCreateVoteHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	u := "/admin/votes"
+	body := `{"vote_id": 100, "header": "Test", "contenders": [{"id": 1, "name": "A"}, {"id": 2, "name": "B"}]}`
+
+	var cases = []struct {
+		auth string
+		body string
+		want int
+	}{
+		{"Bearer " + ADMIN_TOKEN, body, http.StatusCreated},
+		{"bearer " + ADMIN_TOKEN, body, http.StatusCreated},
+		{"", body, http.StatusUnauthorized},
+		{"Bearer wrong", body, http.StatusUnauthorized},
+		{"Basic " + ADMIN_TOKEN, body, http.StatusUnauthorized},
+		{"Bearer " + ADMIN_TOKEN, "{", http.StatusBadRequest},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, u, bytes.NewBufferString(c.body))
+			if c.auth != "" {
+				req.Header.Set("Authorization", c.auth)
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			resp := w.Result()
+			if resp.StatusCode != c.want {
+				t.Errorf("%s (case # %d) failed, %s %s: expected %d, but was %d",
+					testinfo, i+1, http.MethodPost, u, c.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+////////////////////////////////
+//
+// TEST HTTP TRANSPORT SET ROLL
+//
+////////////////////////////////
+
+func TestHttpTransportSetRoll(t *testing.T) {
+	testinfo := "test # 6: SetRoll"
+	var got endpoint.SetRollRequest
+	eps := endpoint.Endpoints{SetRollEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+		got = request.(endpoint.SetRollRequest)
+		return endpoint.SetRollResponse{V0: &service.RollStats{Size: int64(len(got.Entries))}}, nil
+	}}
+	m := http.NewServeMux()
+	makeSetRollHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	var cases = []struct {
+		url     string
+		body    string
+		want    int
+		entries int
+	}{
+		{"/admin/votes/100/roll", "user_id,name\nalice,Alice\nbob,Bob\n", http.StatusOK, 2},
+		{"/admin/votes/100/roll", "", http.StatusOK, 0},
+		{"/admin/votes/100/roll", "\"alice\n", http.StatusBadRequest, 0},
+		{"/admin/votes/abc/roll", "alice\n", http.StatusBadRequest, 0},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			got = endpoint.SetRollRequest{}
+			req := httptest.NewRequest(http.MethodPut, c.url, bytes.NewBufferString(c.body))
+			req.Header.Set("Content-Type", "text/csv")
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			resp := w.Result()
+			if resp.StatusCode != c.want || len(got.Entries) != c.entries {
+				t.Errorf("%s (case # %d) failed, %s %s: expected %d (%d entries), but was %d (%d entries)",
+					testinfo, i+1, http.MethodPut, c.url, c.want, c.entries, resp.StatusCode, len(got.Entries))
+			}
+		})
+	}
+}
+
+//////////////////////////////////////
+//
+// TEST HTTP TRANSPORT GENERATE CODES
+//
+//////////////////////////////////////
+
+func TestHttpTransportGenerateCodes(t *testing.T) {
+	testinfo := "test # 7: GenerateCodes"
+	eps := endpoint.Endpoints{GenerateCodesEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(endpoint.GenerateCodesRequest)
+		if req.Count != 2 || req.VoteId != 100 {
+			return endpoint.GenerateCodesResponse{E1: service.ErrBadRequest}, nil
+		}
+		return endpoint.GenerateCodesResponse{V0: []string{"AAAA-BBBB-CCCC-DDDD", "EEEE-FFFF-GGGG-HHHH"}}, nil
+	}}
+	m := http.NewServeMux()
+	makeGenerateCodesHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	var cases = []struct {
+		url  string
+		body string
+		want int
+		resp string
+	}{
+		{"/admin/votes/100/codes", `{"count": 2}`, http.StatusCreated, `{"v0":["AAAA-BBBB-CCCC-DDDD","EEEE-FFFF-GGGG-HHHH"],"e1":null}` + "\n"},
+		{"/admin/votes/100/codes?format=csv", `{"count": 2}`, http.StatusCreated, "code\nAAAA-BBBB-CCCC-DDDD\nEEEE-FFFF-GGGG-HHHH\n"},
+		{"/admin/votes/100/codes?format=csv", `{"count": 3}`, http.StatusBadRequest, `{"error":"bad request"}` + "\n"},
+		{"/admin/votes/100/codes", `{`, http.StatusBadRequest, `{"error":"bad request"}` + "\n"},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, c.url, bytes.NewBufferString(c.body))
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			if w.Code != c.want || w.Body.String() != c.resp {
+				t.Errorf("%s (case # %d) failed, %s %s: expected %d %q, but was %d %q",
+					testinfo, i+1, http.MethodPost, c.url, c.want, c.resp, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// --- END OF FILE ---
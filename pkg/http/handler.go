@@ -1,5 +1,5 @@
 //  Created : 2024-Mar-14
-// Modified : 2024-Apr-22
+// Modified : 2026-Oct-18
 
 package http
 
@@ -22,6 +22,32 @@ type VoteUpdateDTO struct {
 	VoteId      int    `json:"vote_id"`
 	ContenderId int16  `json:"co_id"`
 	UserId      string `json:"user_id"`
+	Code        string `json:"code"` // The invitation code, if the poll requires it;
+}
+
+// The same for the ballot with several contenders (see decodeCastBallotRequest);
+type BallotDTO struct {
+	VoteId       int     `json:"vote_id"`
+	ContenderIds []int16 `json:"co_ids"`
+	UserId       string  `json:"user_id"`
+	Code         string  `json:"code"`
+}
+
+// The same for the ratings of a score poll (see decodeRateContendersRequest), the keys of
+// 'scores' are the contender ids: {"1": 5, "2": 3};
+type RatingsDTO struct {
+	VoteId int           `json:"vote_id"`
+	Scores map[int16]int `json:"scores"`
+	UserId string        `json:"user_id"`
+	Code   string        `json:"code"`
+}
+
+// The same for the encrypted ballot (see decodeCastEncryptedBallotRequest);
+type EncryptedBallotDTO struct {
+	VoteId int                     `json:"vote_id"`
+	Ballot service.EncryptedBallot `json:"ballot"`
+	UserId string                  `json:"user_id"`
+	Code   string                  `json:"code"`
 }
 
 //////////////////////////////
@@ -95,7 +121,7 @@ func makeGetVoteResultsHandler(m *http.ServeMux,
 		endpoints.GetVoteResultsEndpoint,
 		decodeGetVoteResultsRequest,
 		encodeGetVoteResultsResponse,
-		options...))
+		append(options, http1.ServerBefore(BearerTokenToContext))...)) // The admin can see hidden results;
 }
 
 func decodeGetVoteResultsRequest(_ context.Context, r *http.Request) (interface{}, error) {
@@ -138,7 +164,7 @@ func makeUpdateVoteResultsHandler(m *http.ServeMux,
 		endpoints.UpdateVoteResultsEndpoint,
 		decodeUpdateVoteResultsRequest,
 		encodeUpdateVoteResultsResponse,
-		options...))
+		append(options, http1.ServerBefore(BearerTokenToContext))...)) // The voter's token (see 'authenticate');
 }
 
 func decodeUpdateVoteResultsRequest(_ context.Context, r *http.Request) (interface{}, error) {
@@ -152,6 +178,7 @@ func decodeUpdateVoteResultsRequest(_ context.Context, r *http.Request) (interfa
 		VoteId:      req.VoteId,
 		ContenderId: req.ContenderId,
 		UserId:      req.UserId,
+		Code:        req.Code,
 	}
 
 	return decodedReq, nil
@@ -167,6 +194,313 @@ func encodeUpdateVoteResultsResponse(ctx context.Context, w http.ResponseWriter,
 	return json.NewEncoder(w).Encode(response)
 }
 
+/////////////////////////////
+//
+// MAKE CAST BALLOT HANDLER
+//
+/////////////////////////////
+
+func makeCastBallotHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("PUT /ballots", http1.NewServer(
+		endpoints.CastBallotEndpoint,
+		decodeCastBallotRequest,
+		encodeCastBallotResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...)) // The voter's token (see 'authenticate');
+}
+
+func decodeCastBallotRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := BallotDTO{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return endpoint.CastBallotRequest{}, service.ErrBadRequest
+	}
+
+	var decodedReq = endpoint.CastBallotRequest{
+		VoteId:       req.VoteId,
+		ContenderIds: req.ContenderIds,
+		UserId:       req.UserId,
+		Code:         req.Code,
+	}
+
+	return decodedReq, nil
+}
+
+func encodeCastBallotResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+/////////////////////////////
+//
+// MAKE RETRACT VOTE HANDLER
+//
+/////////////////////////////
+
+// The voter of a revisable poll retracts the vote: DELETE /votes/{id}?user_id=...
+func makeRetractVoteHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("DELETE /votes/{id}", http1.NewServer(
+		endpoints.RetractVoteEndpoint,
+		decodeRetractVoteRequest,
+		encodeRetractVoteResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...)) // The voter's token (see 'authenticate');
+}
+
+func decodeRetractVoteRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.RetractVoteRequest{}, service.ErrBadRequest
+	}
+
+	return endpoint.RetractVoteRequest{VoteId: id, UserId: r.URL.Query().Get("user_id")}, nil
+}
+
+func encodeRetractVoteResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+////////////////////////////
+//
+// MAKE GET RECEIPT HANDLER
+//
+////////////////////////////
+
+// The voter checks the receipt of the vote: GET /votes/{id}/receipts/{receipt}
+func makeGetReceiptHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("GET /votes/{id}/receipts/{receipt}", http1.NewServer(
+		endpoints.GetReceiptEndpoint,
+		decodeGetReceiptRequest,
+		encodeGetReceiptResponse,
+		options...))
+}
+
+func decodeGetReceiptRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.GetReceiptRequest{}, service.ErrBadRequest
+	}
+
+	return endpoint.GetReceiptRequest{VoteId: id, Receipt: r.PathValue("receipt")}, nil
+}
+
+// Remember 'r0', it's the same as 'v0' of GetVoteData;
+func encodeGetReceiptResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+///////////////////////////////
+//
+// MAKE GET AUDIT HEAD HANDLER
+//
+///////////////////////////////
+
+func makeGetAuditHeadHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("GET /votes/{id}/audit", http1.NewServer(
+		endpoints.GetAuditHeadEndpoint,
+		decodeGetAuditHeadRequest,
+		encodeGetAuditHeadResponse,
+		options...))
+}
+
+func decodeGetAuditHeadRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.GetAuditHeadRequest{}, service.ErrBadRequest
+	}
+
+	return endpoint.GetAuditHeadRequest{VoteId: id}, nil
+}
+
+func encodeGetAuditHeadResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+///////////////////////////////
+//
+// MAKE GET COMMITMENT HANDLER
+//
+///////////////////////////////
+
+func makeGetCommitmentHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("GET /votes/{id}/commitment", http1.NewServer(
+		endpoints.GetCommitmentEndpoint,
+		decodeGetCommitmentRequest,
+		encodeGetCommitmentResponse,
+		options...))
+}
+
+func decodeGetCommitmentRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.GetCommitmentRequest{}, service.ErrBadRequest
+	}
+
+	return endpoint.GetCommitmentRequest{VoteId: id}, nil
+}
+
+func encodeGetCommitmentResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+//////////////////////////////////
+//
+// MAKE GET RECEIPT PROOF HANDLER
+//
+//////////////////////////////////
+
+func makeGetReceiptProofHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("GET /votes/{id}/receipts/{receipt}/proof", http1.NewServer(
+		endpoints.GetReceiptProofEndpoint,
+		decodeGetReceiptProofRequest,
+		encodeGetReceiptProofResponse,
+		options...))
+}
+
+func decodeGetReceiptProofRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return endpoint.GetReceiptProofRequest{}, service.ErrBadRequest
+	}
+
+	return endpoint.GetReceiptProofRequest{VoteId: id, Receipt: r.PathValue("receipt")}, nil
+}
+
+func encodeGetReceiptProofResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+/////////////////////////////////
+//
+// MAKE RATE CONTENDERS HANDLER
+//
+/////////////////////////////////
+
+func makeRateContendersHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("PUT /ratings", http1.NewServer(
+		endpoints.RateContendersEndpoint,
+		decodeRateContendersRequest,
+		encodeRateContendersResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...)) // The voter's token (see 'authenticate');
+}
+
+func decodeRateContendersRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := RatingsDTO{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return endpoint.RateContendersRequest{}, service.ErrBadRequest
+	}
+
+	var decodedReq = endpoint.RateContendersRequest{
+		VoteId: req.VoteId,
+		Scores: req.Scores,
+		UserId: req.UserId,
+		Code:   req.Code,
+	}
+
+	return decodedReq, nil
+}
+
+func encodeRateContendersResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+//////////////////////////////////////
+//
+// MAKE CAST ENCRYPTED BALLOT HANDLER
+//
+//////////////////////////////////////
+
+func makeCastEncryptedBallotHandler(m *http.ServeMux,
+	endpoints endpoint.Endpoints, options []http1.ServerOption) {
+
+	m.Handle("PUT /encrypted-ballots", http1.NewServer(
+		endpoints.CastEncryptedBallotEndpoint,
+		decodeCastEncryptedBallotRequest,
+		encodeCastEncryptedBallotResponse,
+		append(options, http1.ServerBefore(BearerTokenToContext))...)) // The voter's token (see 'authenticate');
+}
+
+func decodeCastEncryptedBallotRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := EncryptedBallotDTO{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return endpoint.CastEncryptedBallotRequest{}, service.ErrBadRequest
+	}
+
+	var decodedReq = endpoint.CastEncryptedBallotRequest{
+		VoteId: req.VoteId,
+		Ballot: req.Ballot,
+		UserId: req.UserId,
+		Code:   req.Code,
+	}
+
+	return decodedReq, nil
+}
+
+func encodeCastEncryptedBallotResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(endpoint.Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
 ///////////////////////////////////
 //
 // MAKE GET SERVICE STATUS HANDLER
@@ -218,29 +552,36 @@ func ErrorDecoder(r *http.Response) error {
 // This is used to set the http status, see an example here :
 // https://github.com/go-kit/kit/blob/master/examples/addsvc/pkg/addtransport/http.go#L133
 func err2code(err error) int {
-	switch err {
-	case ratelimit.ErrLimited:
+	// The service errors can be wrapped to explain the reason (e.g. "bad request: header is required").
+	switch {
+	case errors.Is(err, ratelimit.ErrLimited):
 		return http.StatusTooManyRequests
 
-	case service.ErrBadRequest:
+	case errors.Is(err, service.ErrBadRequest):
 		return http.StatusBadRequest
 
-	case service.ErrMethodNotAllowed:
+	case errors.Is(err, service.ErrMethodNotAllowed):
 		return http.StatusMethodNotAllowed
 
-	case service.ErrNotFound:
+	case errors.Is(err, service.ErrNotFound):
 		return http.StatusNotFound
 
-	case service.ErrServiceUnavailable:
+	case errors.Is(err, service.ErrServiceUnavailable):
 		return http.StatusServiceUnavailable
 
-	case service.ErrUnauthorized:
+	case errors.Is(err, service.ErrUnauthorized):
 		return http.StatusUnauthorized
 
-	case service.ErrForbidden:
+	case errors.Is(err, service.ErrForbidden):
 		return http.StatusForbidden
 
-	case service.ErrNoContent:
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict
+
+	case errors.Is(err, service.ErrWithdrawn):
+		return http.StatusGone
+
+	case errors.Is(err, service.ErrNoContent):
 		return http.StatusNoContent
 
 	default:
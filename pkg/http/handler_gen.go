@@ -3,7 +3,7 @@
 // Don't bother about it. In most cases this file needs editing.
 
 //  Created : 2024-Mar-14
-// Modified : 2024-Apr-04
+// Modified : 2026-Oct-18
 
 package http
 
@@ -23,7 +23,26 @@ func NewHTTPHandler(endpoints endpoint.Endpoints, options map[string][]kithttp.S
 	makeGetVoteDataHandler(m, endpoints, options["GetVoteData"])
 	makeGetVoteResultsHandler(m, endpoints, options["GetVoteResults"])
 	makeUpdateVoteResultsHandler(m, endpoints, options["UpdateVoteResults"])
+	makeCastBallotHandler(m, endpoints, options["CastBallot"])
+	makeRateContendersHandler(m, endpoints, options["RateContenders"])
+	makeCastEncryptedBallotHandler(m, endpoints, options["CastEncryptedBallot"])
+	makeRetractVoteHandler(m, endpoints, options["RetractVote"])
+	makeGetReceiptHandler(m, endpoints, options["GetReceipt"])
+	makeGetAuditHeadHandler(m, endpoints, options["GetAuditHead"])
+	makeGetCommitmentHandler(m, endpoints, options["GetCommitment"])
+	makeGetReceiptProofHandler(m, endpoints, options["GetReceiptProof"])
 	makeGetServiceStatusHandler(m, endpoints, options["GetServiceStatus"])
+	makeCreateVoteHandler(m, endpoints, options["CreateVote"])
+	makeUpdateVoteHandler(m, endpoints, options["UpdateVote"])
+	makeDeleteVoteHandler(m, endpoints, options["DeleteVote"])
+	makeCloseVoteHandler(m, endpoints, options["CloseVote"])
+	makeReopenVoteHandler(m, endpoints, options["ReopenVote"])
+	makeAddContenderHandler(m, endpoints, options["AddContender"])
+	makeUpdateContenderHandler(m, endpoints, options["UpdateContender"])
+	makeWithdrawContenderHandler(m, endpoints, options["WithdrawContender"])
+	makeSetRollHandler(m, endpoints, options["SetRoll"])
+	makeGenerateCodesHandler(m, endpoints, options["GenerateCodes"])
+	makeRevokeCodesHandler(m, endpoints, options["RevokeCodes"])
 
 	// CORS-related stuff (Cross-Origin Resource Sharing).
 	// This was not auto generated, but it's required;
@@ -41,6 +60,7 @@ func NewHTTPHandler(endpoints endpoint.Endpoints, options map[string][]kithttp.S
 			http.MethodGet,
 			http.MethodPost,
 			http.MethodPut,
+			http.MethodPatch,
 			http.MethodDelete,
 			// http.MethodOptions,
 			// http.MethodHead,
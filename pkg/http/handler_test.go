@@ -23,6 +23,7 @@ import (
 
 const BAD_USER_ID = "5287eb7d-4813-49e7-8e2d-c8a6ce8b3c4c"
 const GOOD_USER_ID = "8e59e85a-66d1-45f6-9816-0560410b61ca"
+const GOOD_RECEIPT = "0d9c2f5e-6a7b-4c1d-8e2f-3a4b5c6d7e8f"
 
 /////////////
 //
@@ -79,19 +80,20 @@ func makeUpdateVoteResultsEndpointMock() endpoint1.Endpoint {
 		req := request.(endpoint.UpdateVoteResultsRequest)
 		if req.VoteId == 1 && req.UserId != BAD_USER_ID {
 			return endpoint.UpdateVoteResultsResponse{
-				E0: nil,
+				R0: &service.Receipt{Id: GOOD_RECEIPT, VoteId: 1},
+				E1: nil,
 			}, nil
 		} else if req.VoteId == 1 && req.UserId == BAD_USER_ID {
 			return endpoint.UpdateVoteResultsResponse{
-				E0: service.ErrForbidden,
+				E1: service.ErrForbidden,
 			}, nil
 		} else if req.VoteId == 2 {
 			return endpoint.UpdateVoteResultsResponse{
-				E0: service.ErrBadRequest,
+				E1: service.ErrBadRequest,
 			}, nil
 		} else {
 			return endpoint.UpdateVoteResultsResponse{
-				E0: service.ErrInternalServerError,
+				E1: service.ErrInternalServerError,
 			}, nil
 		}
 	}
@@ -337,6 +339,14 @@ func TestHttpTransportUpdateVoteResults(t *testing.T) {
 				testinfo, http.MethodPut, u, http.StatusOK, resp.StatusCode)
 		}
 
+		// The receipt of the vote is in the response;
+		var rec struct {
+			R0 *service.Receipt `json:"r0"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&rec); err != nil || rec.R0 == nil || rec.R0.Id != GOOD_RECEIPT {
+			t.Errorf("%s (case # 1) failed, receipt %+v, error: %v", testinfo, rec.R0, err)
+		}
+
 		// Case 2: VoteId is good, but UserId is bad, response must be http.StatusForbidden;
 		u = "/votes"
 		dataToSend = &VoteUpdateDTO{
@@ -434,6 +444,295 @@ func TestHttpTransportUpdateVoteResults(t *testing.T) {
 	})
 }
 
+///////////////////////////////////
+//
+// TEST HTTP TRANSPORT CAST BALLOT
+//
+///////////////////////////////////
+
+func TestHttpTransportCastBallot(t *testing.T) {
+	testinfo := "test # 3a: CastBallot"
+	eps := endpoint.Endpoints{CastBallotEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(endpoint.CastBallotRequest)
+		if req.VoteId != 1 || req.UserId != GOOD_USER_ID {
+			return endpoint.CastBallotResponse{E1: service.ErrForbidden}, nil
+		}
+		if len(req.ContenderIds) > 2 {
+			return endpoint.CastBallotResponse{E1: service.ErrTooManyChoices}, nil
+		}
+		return endpoint.CastBallotResponse{}, nil
+	}}
+	m := http.NewServeMux()
+	makeCastBallotHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	var cases = []struct {
+		method string
+		body   string
+		want   int
+	}{
+		{http.MethodPut, `{"vote_id": 1, "co_ids": [1, 3], "user_id": "` + GOOD_USER_ID + `"}`, http.StatusOK},
+		{http.MethodPut, `{"vote_id": 1, "co_ids": [1, 2, 3], "user_id": "` + GOOD_USER_ID + `"}`, http.StatusBadRequest},
+		{http.MethodPut, `{"vote_id": 1, "co_ids": [1], "user_id": "` + BAD_USER_ID + `"}`, http.StatusForbidden},
+		{http.MethodPut, `{"vote_id": 1, "co_ids": 1}`, http.StatusBadRequest},
+		{http.MethodPost, `{"vote_id": 1, "co_ids": [1]}`, http.StatusMethodNotAllowed},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, "/ballots", bytes.NewBufferString(c.body))
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			if w.Code != c.want {
+				t.Errorf("%s (case # %d) failed, %s /ballots: expected %d, but was %d",
+					testinfo, i+1, c.method, c.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHttpTransportRateContenders(t *testing.T) {
+	testinfo := "test # 3b: RateContenders"
+	eps := endpoint.Endpoints{RateContendersEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(endpoint.RateContendersRequest)
+		if req.VoteId != 1 || req.UserId != GOOD_USER_ID {
+			return endpoint.RateContendersResponse{E1: service.ErrForbidden}, nil
+		}
+		if len(req.Scores) != 2 || req.Scores[1] != 5 {
+			return endpoint.RateContendersResponse{E1: service.ErrBadRequest}, nil
+		}
+		return endpoint.RateContendersResponse{}, nil
+	}}
+	m := http.NewServeMux()
+	makeRateContendersHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	var cases = []struct {
+		method string
+		body   string
+		want   int
+	}{
+		{http.MethodPut, `{"vote_id": 1, "scores": {"1": 5, "2": 3}, "user_id": "` + GOOD_USER_ID + `"}`, http.StatusOK},
+		{http.MethodPut, `{"vote_id": 1, "scores": {"1": 5}, "user_id": "` + GOOD_USER_ID + `"}`, http.StatusBadRequest},
+		{http.MethodPut, `{"vote_id": 1, "scores": {"1": 5, "2": 3}, "user_id": "` + BAD_USER_ID + `"}`, http.StatusForbidden},
+		{http.MethodPut, `{"vote_id": 1, "scores": {"x": 5}}`, http.StatusBadRequest},
+		{http.MethodPost, `{"vote_id": 1, "scores": {"1": 5}}`, http.StatusMethodNotAllowed},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, "/ratings", bytes.NewBufferString(c.body))
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			if w.Code != c.want {
+				t.Errorf("%s (case # %d) failed, %s /ratings: expected %d, but was %d",
+					testinfo, i+1, c.method, c.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHttpTransportRetractVote(t *testing.T) {
+	testinfo := "test # 3c: RetractVote"
+	eps := endpoint.Endpoints{RetractVoteEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(endpoint.RetractVoteRequest)
+		if req.VoteId != 1 {
+			return endpoint.RetractVoteResponse{E0: service.ErrNotRevisable}, nil
+		}
+		if req.UserId != GOOD_USER_ID {
+			return endpoint.RetractVoteResponse{E0: service.ErrNotFound}, nil
+		}
+		return endpoint.RetractVoteResponse{}, nil
+	}}
+	m := http.NewServeMux()
+	makeRetractVoteHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	var cases = []struct {
+		method string
+		url    string
+		want   int
+	}{
+		{http.MethodDelete, "/votes/1?user_id=" + GOOD_USER_ID, http.StatusOK},
+		{http.MethodDelete, "/votes/1?user_id=" + BAD_USER_ID, http.StatusNotFound},
+		{http.MethodDelete, "/votes/2?user_id=" + GOOD_USER_ID, http.StatusForbidden},
+		{http.MethodDelete, "/votes/x", http.StatusBadRequest},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.url, nil)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			if w.Code != c.want {
+				t.Errorf("%s (case # %d) failed, %s %s: expected %d, but was %d",
+					testinfo, i+1, c.method, c.url, c.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHttpTransportGetReceipt(t *testing.T) {
+	testinfo := "test # 3d: GetReceipt"
+	eps := endpoint.Endpoints{GetReceiptEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(endpoint.GetReceiptRequest)
+		if req.VoteId != 1 || req.Receipt != GOOD_RECEIPT {
+			return endpoint.GetReceiptResponse{E1: service.ErrNotFound}, nil
+		}
+		return endpoint.GetReceiptResponse{R0: &service.Receipt{Id: req.Receipt, VoteId: req.VoteId}}, nil
+	}}
+	m := http.NewServeMux()
+	makeGetReceiptHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	var cases = []struct {
+		method string
+		url    string
+		want   int
+	}{
+		{http.MethodGet, "/votes/1/receipts/" + GOOD_RECEIPT, http.StatusOK},
+		{http.MethodGet, "/votes/2/receipts/" + GOOD_RECEIPT, http.StatusNotFound},
+		{http.MethodGet, "/votes/1/receipts/" + BAD_USER_ID, http.StatusNotFound},
+		{http.MethodGet, "/votes/x/receipts/" + GOOD_RECEIPT, http.StatusBadRequest},
+		{http.MethodPut, "/votes/1/receipts/" + GOOD_RECEIPT, http.StatusMethodNotAllowed},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.url, nil)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			if w.Code != c.want {
+				t.Errorf("%s (case # %d) failed, %s %s: expected %d, but was %d",
+					testinfo, i+1, c.method, c.url, c.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHttpTransportGetAuditHead(t *testing.T) {
+	testinfo := "test # 3e: GetAuditHead"
+	eps := endpoint.Endpoints{GetAuditHeadEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(endpoint.GetAuditHeadRequest)
+		if req.VoteId != 1 {
+			return endpoint.GetAuditHeadResponse{E1: service.ErrNotFound}, nil
+		}
+		return endpoint.GetAuditHeadResponse{R0: &service.AuditHead{VoteId: req.VoteId, Hash: service.AUDIT_GENESIS}}, nil
+	}}
+	m := http.NewServeMux()
+	makeGetAuditHeadHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	var cases = []struct {
+		method string
+		url    string
+		want   int
+	}{
+		{http.MethodGet, "/votes/1/audit", http.StatusOK},
+		{http.MethodGet, "/votes/2/audit", http.StatusNotFound},
+		{http.MethodGet, "/votes/x/audit", http.StatusBadRequest},
+		{http.MethodPost, "/votes/1/audit", http.StatusMethodNotAllowed},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.url, nil)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			if w.Code != c.want {
+				t.Errorf("%s (case # %d) failed, %s %s: expected %d, but was %d",
+					testinfo, i+1, c.method, c.url, c.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHttpTransportGetCommitment(t *testing.T) {
+	testinfo := "test # 3f: GetCommitment"
+	eps := endpoint.Endpoints{
+		GetCommitmentEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+			req := request.(endpoint.GetCommitmentRequest)
+			if req.VoteId != 1 {
+				return endpoint.GetCommitmentResponse{E1: service.ErrNotClosed}, nil
+			}
+			return endpoint.GetCommitmentResponse{R0: &service.Commitment{VoteId: req.VoteId}}, nil
+		},
+		GetReceiptProofEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+			req := request.(endpoint.GetReceiptProofRequest)
+			if req.VoteId != 1 || req.Receipt != GOOD_RECEIPT {
+				return endpoint.GetReceiptProofResponse{E1: service.ErrNotFound}, nil
+			}
+			return endpoint.GetReceiptProofResponse{R0: &service.ReceiptProof{VoteId: req.VoteId, Receipt: req.Receipt}}, nil
+		},
+	}
+	m := http.NewServeMux()
+	options := []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)}
+	makeGetCommitmentHandler(m, eps, options)
+	makeGetReceiptProofHandler(m, eps, options)
+
+	var cases = []struct {
+		method string
+		url    string
+		want   int
+	}{
+		{http.MethodGet, "/votes/1/commitment", http.StatusOK},
+		{http.MethodGet, "/votes/2/commitment", http.StatusForbidden},
+		{http.MethodGet, "/votes/x/commitment", http.StatusBadRequest},
+		{http.MethodGet, "/votes/1/receipts/" + GOOD_RECEIPT + "/proof", http.StatusOK},
+		{http.MethodGet, "/votes/2/receipts/" + GOOD_RECEIPT + "/proof", http.StatusNotFound},
+		{http.MethodGet, "/votes/x/receipts/" + GOOD_RECEIPT + "/proof", http.StatusBadRequest},
+		{http.MethodPut, "/votes/1/commitment", http.StatusMethodNotAllowed},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.url, nil)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			if w.Code != c.want {
+				t.Errorf("%s (case # %d) failed, %s %s: expected %d, but was %d",
+					testinfo, i+1, c.method, c.url, c.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestHttpTransportCastEncryptedBallot(t *testing.T) {
+	testinfo := "test # 3g: CastEncryptedBallot"
+	eps := endpoint.Endpoints{CastEncryptedBallotEndpoint: func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(endpoint.CastEncryptedBallotRequest)
+		if req.VoteId != 1 || req.UserId != GOOD_USER_ID {
+			return endpoint.CastEncryptedBallotResponse{E1: service.ErrForbidden}, nil
+		}
+		if len(req.Ballot.Choices) != 2 || req.Ballot.Choices[0].A != "2" || req.Ballot.Proof == nil {
+			return endpoint.CastEncryptedBallotResponse{E1: service.ErrBadRequest}, nil
+		}
+		return endpoint.CastEncryptedBallotResponse{R0: &service.Receipt{Id: "r", VoteId: 1}}, nil
+	}}
+	m := http.NewServeMux()
+	makeCastEncryptedBallotHandler(m, eps, []http1.ServerOption{http1.ServerErrorEncoder(ErrorEncoder)})
+
+	ballot := `{"choices": [{"co_id": 1, "a": "2", "b": "4", "proof": {"c": ["1"], "z": ["2"]}}, {"co_id": 2, "a": "4", "b": "2"}], "proof": {"c": [], "z": []}}`
+	var cases = []struct {
+		method string
+		body   string
+		want   int
+	}{
+		{http.MethodPut, `{"vote_id": 1, "ballot": ` + ballot + `, "user_id": "` + GOOD_USER_ID + `"}`, http.StatusOK},
+		{http.MethodPut, `{"vote_id": 1, "ballot": {"choices": []}, "user_id": "` + GOOD_USER_ID + `"}`, http.StatusBadRequest},
+		{http.MethodPut, `{"vote_id": 1, "ballot": ` + ballot + `, "user_id": "` + BAD_USER_ID + `"}`, http.StatusForbidden},
+		{http.MethodPut, `{"vote_id": 1, "ballot": [1, 0]}`, http.StatusBadRequest},
+		{http.MethodPost, `{"vote_id": 1, "ballot": ` + ballot + `}`, http.StatusMethodNotAllowed},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, "/encrypted-ballots", bytes.NewBufferString(c.body))
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+			if w.Code != c.want {
+				t.Errorf("%s (case # %d) failed, %s /encrypted-ballots: expected %d, but was %d",
+					testinfo, i+1, c.method, c.want, w.Code)
+			}
+		})
+	}
+}
+
 //////////////////////////////////////////
 //
 // TEST HTTP TRANSPORT GET SERVICE STATUS
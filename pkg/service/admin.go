@@ -0,0 +1,485 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Poll administration: the polls are created, changed, and deleted by the admin (see
+// 'WithAdmin'). Only the poll data is changed here, the counts are changed by voting.
+
+// VotePatch describes the changes of the vote data, nil fields are not changed.
+type VotePatch struct {
+	Header       *string    `json:"header"`
+	Message      *string    `json:"message"`
+	Resources    *string    `json:"resources"`
+	Deadline     *time.Time `json:"deadline"`
+	Authenticate *bool      `json:"authenticate"`
+	AllowResults *bool      `json:"allow_results"`
+	State        *string    `json:"state"`
+	OpensAt      *time.Time `json:"opens_at"`
+	RequireCode  *bool      `json:"require_code"`
+	Method       *string    `json:"method"`
+	MaxChoices   *int       `json:"max_choices"`
+	ScoreMin     *int       `json:"score_min"`
+	ScoreMax     *int       `json:"score_max"`
+	Revisable    *bool      `json:"revisable"`
+	PublicKey    *string    `json:"public_key"`
+}
+
+// ContenderPatch describes the changes of the contender data, nil fields are not changed.
+type ContenderPatch struct {
+	Name    *string `json:"name"`
+	Alias   *string `json:"alias"`
+	Info    *string `json:"info"`
+	Picture *string `json:"picture"`
+}
+
+///////////////
+//
+// CREATE VOTE
+//
+///////////////
+
+// It saves a new vote with its contenders. The counts are always zero, whatever the
+// caller sends. It returns ErrConflict if the 'vote_id' is already used.
+
+func (b *basicVoteService) CreateVote(ctx context.Context, vote VoteData) (*VoteData, error) {
+	if !IsAdmin(ctx) {
+		return nil, ErrUnauthorized
+	}
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	if err := validateVote(&vote); err != nil {
+		return nil, err
+	}
+
+	if vote.State == "" {
+		vote.State = VOTE_STATE_OPEN
+	}
+
+	now := time.Now().UTC()
+	vote.Contenders = append([]Contender(nil), vote.Contenders...)
+	for i := range vote.Contenders {
+		vote.Contenders[i].Count = 0
+		vote.Contenders[i].Updated = now
+	}
+
+	if err := b.store.CreateVote(ctx, &vote); err != nil {
+		return nil, err
+	}
+
+	return b.store.LoadVote(ctx, vote.VoteId)
+}
+
+///////////////
+//
+// UPDATE VOTE
+//
+///////////////
+
+// It changes the vote data (header, deadline, etc.), but not the contenders. The state goes
+// forward only (see 'checkTransition'), the closed poll is open again by 'ReopenVote'.
+
+func (b *basicVoteService) UpdateVote(ctx context.Context, vote_id int, patch VotePatch) (*VoteData, error) {
+	return b.updateVote(ctx, vote_id, patch, false)
+}
+
+func (b *basicVoteService) updateVote(ctx context.Context, vote_id int, patch VotePatch, reopen bool) (*VoteData, error) {
+	if !IsAdmin(ctx) {
+		return nil, ErrUnauthorized
+	}
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	vote, err := b.store.LoadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Header != nil {
+		vote.Header = *patch.Header
+	}
+	if patch.Message != nil {
+		vote.Message = *patch.Message
+	}
+	if patch.Resources != nil {
+		vote.Resources = *patch.Resources
+	}
+	if patch.Deadline != nil {
+		vote.Deadline = *patch.Deadline
+	}
+	if patch.Authenticate != nil {
+		vote.Authenticate = *patch.Authenticate
+	}
+	if patch.AllowResults != nil {
+		vote.AllowResults = *patch.AllowResults
+	}
+	if patch.State != nil {
+		if err = checkTransition(vote.CurrentState(time.Now()), *patch.State, reopen); err != nil {
+			return nil, err
+		}
+		vote.State = *patch.State
+	}
+	if patch.OpensAt != nil {
+		vote.OpensAt = *patch.OpensAt
+	}
+	if patch.RequireCode != nil {
+		vote.RequireCode = *patch.RequireCode
+	}
+	if (patch.Method != nil && *patch.Method != vote.Method) || (patch.ScoreMin != nil && *patch.ScoreMin != vote.ScoreMin) ||
+		(patch.ScoreMax != nil && *patch.ScoreMax != vote.ScoreMax) || (patch.PublicKey != nil && *patch.PublicKey != vote.PublicKey) ||
+		(vote.PublicKey != "" && patch.MaxChoices != nil && *patch.MaxChoices != vote.MaxChoices) {
+		// The ballots of the ranked poll are saved, the votes given before would have none.
+		// The ratings of the score poll are on its scale, the histogram would be wrong.
+		// The encrypted ballots are proved for the key and the max choices of the poll.
+		started, err := b.hasVotes(ctx, vote)
+		if err != nil {
+			return nil, err
+		}
+		if started {
+			return nil, fmt.Errorf("%w: method, scale and key cannot be changed after voting has started", ErrConflict)
+		}
+	}
+	if patch.Method != nil {
+		vote.Method = *patch.Method
+	}
+	if patch.MaxChoices != nil {
+		vote.MaxChoices = *patch.MaxChoices
+	}
+	if patch.ScoreMin != nil {
+		vote.ScoreMin = *patch.ScoreMin
+	}
+	if patch.ScoreMax != nil {
+		vote.ScoreMax = *patch.ScoreMax
+	}
+	if patch.Revisable != nil && !*patch.Revisable && vote.Revisable {
+		// The receipts of the changed votes would get into the commitment (see 'commitment.go').
+		started, err := b.hasVotes(ctx, vote)
+		if err != nil {
+			return nil, err
+		}
+		if started {
+			return nil, fmt.Errorf("%w: poll stays revisable after voting has started", ErrConflict)
+		}
+	}
+	if patch.Revisable != nil {
+		vote.Revisable = *patch.Revisable
+	}
+	if patch.PublicKey != nil {
+		vote.PublicKey = *patch.PublicKey
+	}
+
+	if err = validateVote(vote); err != nil {
+		return nil, err
+	}
+	if (patch.RequireCode != nil || patch.Authenticate != nil) && vote.RequireCode && !vote.Authenticate {
+		if err = b.checkNoRoll(ctx, vote_id); err != nil {
+			return nil, err // The codes would be checked against the roll (see 'roll.go');
+		}
+	}
+
+	if err = b.store.UpdateVote(ctx, vote); err != nil {
+		return nil, err
+	}
+
+	// The root of the receipts is not valid if the poll accepts votes again (see 'commitment.go'),
+	// and so is the tally of the encrypted ballots (see 'encrypted.go');
+	switch vote.CurrentState(time.Now()) {
+	case VOTE_STATE_CLOSED, VOTE_STATE_ARCHIVED:
+	default:
+		if err = b.store.RemoveCommitment(ctx, vote_id); err != nil {
+			return nil, err
+		}
+		if err = b.store.RemoveTally(ctx, vote_id); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.store.LoadVote(ctx, vote_id)
+}
+
+// It reports whether anybody has voted: a contender has a count, or a rating in a score poll
+// (the ratings are not always in the counts, see 'VoteStore.LoadScores'), or the encrypted
+// poll has a ballot (its counts are zero).
+func (b *basicVoteService) hasVotes(ctx context.Context, vote *VoteData) (bool, error) {
+	for _, c := range vote.Contenders {
+		if c.Count > 0 {
+			return true, nil
+		}
+	}
+	if vote.PublicKey != "" {
+		ballots, err := b.store.LoadEncryptedBallots(ctx, vote.VoteId)
+		if err != nil || len(ballots) > 0 {
+			return len(ballots) > 0, err
+		}
+	}
+	if vote.Method != VOTE_METHOD_SCORE {
+		return false, nil
+	}
+
+	tallies, err := b.store.LoadScores(ctx, vote.VoteId)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tallies {
+		if t.Count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+///////////////
+//
+// DELETE VOTE
+//
+///////////////
+
+// It deletes the vote with all its contenders and voters, there is no way back.
+
+func (b *basicVoteService) DeleteVote(ctx context.Context, vote_id int) error {
+	if !IsAdmin(ctx) {
+		return ErrUnauthorized
+	}
+	if b.store == nil {
+		return ErrServiceUnavailable
+	}
+
+	if _, err := b.store.LoadVote(ctx, vote_id); err != nil {
+		return err
+	}
+
+	return b.store.DeleteVote(ctx, vote_id)
+}
+
+//////////////
+//
+// CLOSE VOTE
+//
+//////////////
+
+// The poll is closed before the deadline, e.g. if something went wrong. The root of the
+// receipts is saved at once (see 'commitment.go'), unless the poll is revisable; if it fails,
+// it's saved by the first 'GetCommitment' later.
+
+func (b *basicVoteService) CloseVote(ctx context.Context, vote_id int) (*VoteData, error) {
+	state := VOTE_STATE_CLOSED
+	vote, err := b.UpdateVote(ctx, vote_id, VotePatch{State: &state})
+	if err != nil {
+		return nil, err
+	}
+
+	if !vote.Revisable {
+		b.commit(ctx, vote_id)
+	}
+	return vote, nil
+}
+
+///////////////
+//
+// REOPEN VOTE
+//
+///////////////
+
+// The closed poll is open again until the deadline, so the deadline must be in the future
+// (change it by 'UpdateVote' first).
+
+func (b *basicVoteService) ReopenVote(ctx context.Context, vote_id int) (*VoteData, error) {
+	if !IsAdmin(ctx) {
+		return nil, ErrUnauthorized
+	}
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	vote, err := b.store.LoadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+	if vote.Deadline.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: the deadline has passed", ErrBadRequest)
+	}
+
+	state := VOTE_STATE_OPEN
+	return b.updateVote(ctx, vote_id, VotePatch{State: &state}, true)
+}
+
+/////////////////
+//
+// ADD CONTENDER
+//
+/////////////////
+
+// It adds a new contender to the existing vote (e.g. a candidate registered late).
+
+func (b *basicVoteService) AddContender(ctx context.Context, vote_id int, contender Contender) (*VoteData, error) {
+	if !IsAdmin(ctx) {
+		return nil, ErrUnauthorized
+	}
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	if err := validateContender(&contender); err != nil {
+		return nil, err
+	}
+
+	contender.Count = 0
+	contender.Updated = time.Now().UTC()
+	contender.Withdrawn = false
+
+	if err := b.store.AddContender(ctx, vote_id, &contender); err != nil {
+		return nil, err
+	}
+
+	return b.store.LoadVote(ctx, vote_id)
+}
+
+////////////////////
+//
+// UPDATE CONTENDER
+//
+////////////////////
+
+// It changes the contender data (name, alias, info, picture), but not the count.
+
+func (b *basicVoteService) UpdateContender(ctx context.Context, vote_id int, co_id int16, patch ContenderPatch) (*VoteData, error) {
+	return b.changeContender(ctx, vote_id, co_id, func(c *Contender) {
+		if patch.Name != nil {
+			c.Name = *patch.Name
+		}
+		if patch.Alias != nil {
+			c.Alias = *patch.Alias
+		}
+		if patch.Info != nil {
+			c.Info = *patch.Info
+		}
+		if patch.Picture != nil {
+			c.Picture = *patch.Picture
+		}
+	})
+}
+
+//////////////////////
+//
+// WITHDRAW CONTENDER
+//
+//////////////////////
+
+// The withdrawn contender is not deleted: the votes given for it are still in the results,
+// but new votes are rejected (see 'UpdateVoteResults').
+
+func (b *basicVoteService) WithdrawContender(ctx context.Context, vote_id int, co_id int16) (*VoteData, error) {
+	return b.changeContender(ctx, vote_id, co_id, func(c *Contender) {
+		c.Withdrawn = true
+	})
+}
+
+// It loads the contender, changes it by 'change', and saves it.
+func (b *basicVoteService) changeContender(ctx context.Context, vote_id int, co_id int16, change func(*Contender)) (*VoteData, error) {
+	if !IsAdmin(ctx) {
+		return nil, ErrUnauthorized
+	}
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	vote, err := b.store.LoadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+
+	contender := vote.findContender(co_id)
+	if contender == nil {
+		return nil, ErrNotFound
+	}
+
+	change(contender)
+	if err = validateContender(contender); err != nil {
+		return nil, err
+	}
+
+	if err = b.store.UpdateContender(ctx, vote_id, contender); err != nil {
+		return nil, err
+	}
+
+	return b.store.LoadVote(ctx, vote_id)
+}
+
+/////////////////
+//
+// VALIDATE VOTE
+//
+/////////////////
+
+// It returns ErrBadRequest (with the reason) if the vote cannot be saved. There is no sense
+// to have election if you have only one candidate, so two contenders are minimum.
+func validateVote(vote *VoteData) error {
+	if vote.VoteId <= 0 {
+		return fmt.Errorf("%w: vote_id must be positive", ErrBadRequest)
+	}
+	if strings.TrimSpace(vote.Header) == "" {
+		return fmt.Errorf("%w: header is required", ErrBadRequest)
+	}
+	if vote.Deadline.IsZero() {
+		return fmt.Errorf("%w: deadline is required", ErrBadRequest)
+	}
+	if vote.State != "" && !isVoteState(vote.State) {
+		return fmt.Errorf("%w: unknown state %q", ErrBadRequest, vote.State)
+	}
+	if !isVoteMethod(vote.Method) {
+		return fmt.Errorf("%w: unknown method %q", ErrBadRequest, vote.Method)
+	}
+	if vote.State == VOTE_STATE_SCHEDULED && (vote.OpensAt.IsZero() || !vote.OpensAt.Before(vote.Deadline)) {
+		return fmt.Errorf("%w: scheduled poll must open before the deadline", ErrBadRequest)
+	}
+	if len(vote.Contenders) < 2 {
+		return fmt.Errorf("%w: at least two contenders are required", ErrBadRequest)
+	}
+	if vote.MaxChoices < 0 || vote.MaxChoices > len(vote.Contenders) {
+		return fmt.Errorf("%w: max_choices must be 0..%d", ErrBadRequest, len(vote.Contenders))
+	}
+	if err := validateScale(vote); err != nil {
+		return err
+	}
+	if err := validateRevisable(vote); err != nil {
+		return err
+	}
+	if err := validateEncrypted(vote); err != nil {
+		return err
+	}
+
+	ids := map[int16]bool{}
+	for _, c := range vote.Contenders {
+		if err := validateContender(&c); err != nil {
+			return err
+		}
+		if ids[c.Id] {
+			return fmt.Errorf("%w: duplicate contender id %d", ErrBadRequest, c.Id)
+		}
+		ids[c.Id] = true
+	}
+
+	return nil
+}
+
+func validateContender(c *Contender) error {
+	if c.Id <= 0 {
+		return fmt.Errorf("%w: contender id must be positive", ErrBadRequest)
+	}
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("%w: contender %d: name is required", ErrBadRequest, c.Id)
+	}
+	return nil
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,192 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// This 'vote_id' is not used by 'testdata/votes.json'.
+const TESTDATA_NEW_VOTE_ID = 100
+
+func new_vote(vote_id int) VoteData {
+	return VoteData{
+		VoteId:       vote_id,
+		Header:       "The Best Programming Language",
+		Message:      "Vote for the best one",
+		Resources:    "https://ws4/votes/100/images/",
+		Deadline:     time.Date(2030, time.December, 31, 19, 0, 0, 0, time.UTC),
+		AllowResults: true,
+		Contenders: []Contender{
+			{Id: 1, Name: "Go", Alias: "Go", Picture: "go.png", Count: 1000},
+			{Id: 2, Name: "Rust", Alias: "Rust", Picture: "rust.png"},
+		},
+	}
+}
+
+func TestCreateVoteReject(t *testing.T) {
+	testinfo := "test CreateVote reject"
+
+	svc := New(load_store(t), []Middleware{})
+	admin := WithAdmin(context.Background())
+
+	one := new_vote(TESTDATA_NEW_VOTE_ID)
+	one.Contenders = one.Contenders[:1]
+
+	duplicate := new_vote(TESTDATA_NEW_VOTE_ID)
+	duplicate.Contenders[1].Id = 1
+
+	noHeader := new_vote(TESTDATA_NEW_VOTE_ID)
+	noHeader.Header = " "
+
+	var cases = []struct {
+		ctx  context.Context
+		vote VoteData
+		want error
+	}{
+		{context.Background(), new_vote(TESTDATA_NEW_VOTE_ID), ErrUnauthorized}, // not admin;
+		{admin, one, ErrBadRequest},
+		{admin, duplicate, ErrBadRequest},
+		{admin, noHeader, ErrBadRequest},
+		{admin, new_vote(1), ErrConflict}, // vote_id = 1 exists;
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			_, err := svc.CreateVote(c.ctx, c.vote)
+			if !errors.Is(err, c.want) {
+				t.Errorf("test %v (case # %d) failed, err %v, must be %v", testinfo, i+1, err, c.want)
+			}
+		})
+	}
+}
+
+func TestAdminVote(t *testing.T) {
+	testAdminVote(t, load_store(t))
+}
+
+// It creates, updates and deletes the vote, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testAdminVote(t *testing.T, store VoteStore) {
+	testinfo := "test CreateVote, UpdateVote, DeleteVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+
+	t.Run(testinfo, func(t *testing.T) {
+		res, err := svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID))
+		if err != nil {
+			t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+		}
+		if len(res.Contenders) != 2 || res.Contenders[0].Count != 0 {
+			t.Errorf("test %v failed, CreateVote returned %v (counts must be 0)", testinfo, res.Contenders)
+		}
+
+		header := "The Worst Programming Language"
+		res, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Header: &header})
+		if err != nil {
+			t.Fatalf("test %v failed, UpdateVote err %v", testinfo, err)
+		}
+		if res.Header != header || res.Message != "Vote for the best one" || len(res.Contenders) != 2 {
+			t.Errorf("test %v failed, UpdateVote returned %v", testinfo, res)
+		}
+
+		if _, err = svc.UpdateVoteResults(context.Background(), TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID); err != nil {
+			t.Errorf("test %v failed, cannot vote: %v", testinfo, err)
+		}
+
+		if err = svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Errorf("test %v failed, DeleteVote err %v", testinfo, err)
+		}
+		if _, err = svc.GetVoteData(ctx, TESTDATA_NEW_VOTE_ID); err != ErrNotFound {
+			t.Errorf("test %v failed, the vote is not deleted, err %v", testinfo, err)
+		}
+		if err = svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID); err != ErrNotFound {
+			t.Errorf("test %v failed, DeleteVote err %v, must be %v", testinfo, err, ErrNotFound)
+		}
+
+		// The voters are deleted as well, so the new vote with the same id is a new vote.
+		if _, err = svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+			t.Fatalf("test %v failed, CreateVote (again) err %v", testinfo, err)
+		}
+		if _, err = svc.UpdateVoteResults(context.Background(), TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID); err != nil {
+			t.Errorf("test %v failed, cannot vote again: %v", testinfo, err)
+		}
+		svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+	})
+}
+
+func TestAdminContender(t *testing.T) {
+	testAdminContender(t, load_store(t))
+}
+
+// It adds, updates and withdraws the contender, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testAdminContender(t *testing.T, store VoteStore) {
+	testinfo := "test AddContender, UpdateContender, WithdrawContender"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+
+	t.Run(testinfo, func(t *testing.T) {
+		if _, err := svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+			t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+		}
+		defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+		zig := Contender{Id: 3, Name: "Zig", Count: 1000}
+		if _, err := svc.AddContender(context.Background(), TESTDATA_NEW_VOTE_ID, zig); err != ErrUnauthorized {
+			t.Errorf("test %v failed, AddContender (not admin) err %v, must be %v", testinfo, err, ErrUnauthorized)
+		}
+		if _, err := svc.AddContender(ctx, TESTDATA_NEW_VOTE_ID+1, zig); err != ErrNotFound {
+			t.Errorf("test %v failed, AddContender (no vote) err %v, must be %v", testinfo, err, ErrNotFound)
+		}
+
+		res, err := svc.AddContender(ctx, TESTDATA_NEW_VOTE_ID, zig)
+		if err != nil {
+			t.Fatalf("test %v failed, AddContender err %v", testinfo, err)
+		}
+		if len(res.Contenders) != 3 || res.Contenders[2].Name != "Zig" || res.Contenders[2].Count != 0 {
+			t.Errorf("test %v failed, AddContender returned %v", testinfo, res.Contenders)
+		}
+		if _, err = svc.AddContender(ctx, TESTDATA_NEW_VOTE_ID, zig); err != ErrConflict {
+			t.Errorf("test %v failed, AddContender (again) err %v, must be %v", testinfo, err, ErrConflict)
+		}
+
+		alias := "Ziglang"
+		res, err = svc.UpdateContender(ctx, TESTDATA_NEW_VOTE_ID, 3, ContenderPatch{Alias: &alias})
+		if err != nil {
+			t.Fatalf("test %v failed, UpdateContender err %v", testinfo, err)
+		}
+		if c := res.findContender(3); c == nil || c.Alias != alias || c.Name != "Zig" {
+			t.Errorf("test %v failed, UpdateContender returned %v", testinfo, res.Contenders)
+		}
+		if _, err = svc.UpdateContender(ctx, TESTDATA_NEW_VOTE_ID, 4, ContenderPatch{Alias: &alias}); err != ErrNotFound {
+			t.Errorf("test %v failed, UpdateContender (no contender) err %v, must be %v", testinfo, err, ErrNotFound)
+		}
+
+		if _, err = svc.UpdateVoteResults(context.Background(), TESTDATA_NEW_VOTE_ID, 3, TESTDATA_USER_ID); err != nil {
+			t.Errorf("test %v failed, cannot vote: %v", testinfo, err)
+		}
+
+		res, err = svc.WithdrawContender(ctx, TESTDATA_NEW_VOTE_ID, 3)
+		if err != nil {
+			t.Fatalf("test %v failed, WithdrawContender err %v", testinfo, err)
+		}
+		if c := res.findContender(3); c == nil || !c.Withdrawn || c.Count != 1 {
+			t.Errorf("test %v failed, the withdrawn contender must keep its count, got %v", testinfo, res.Contenders)
+		}
+
+		_, err = svc.UpdateVoteResults(context.Background(), TESTDATA_NEW_VOTE_ID, 3, TESTDATA_USER_ID+"-2")
+		if err != ErrWithdrawn {
+			t.Errorf("test %v failed, vote for the withdrawn contender err %v, must be %v", testinfo, err, ErrWithdrawn)
+		}
+		if _, err = svc.UpdateVoteResults(context.Background(), TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID+"-2"); err != nil {
+			t.Errorf("test %v failed, cannot vote for other contender: %v", testinfo, err)
+		}
+	})
+}
+
+// --- END OF FILE ---
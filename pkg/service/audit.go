@@ -0,0 +1,283 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Audit log: each accepted vote appends an entry to the log of the poll, and the entry has
+// the hash of the previous one, so the log cannot be changed without changing all the hashes
+// after the change (the head of the chain is published, see 'GetAuditHead'). The entry has
+// the changes of the counts (co_id -> delta) and the time, it has nothing about the voter.
+// A change of the vote of a revisable poll is an entry too (-1 and +1, see 'revise.go'), and
+// so is a retraction (-1). The tallies are recomputed from the log by 'VerifyAudit'.
+
+// The entries are numbered from 1 without gaps, and the store saves the entry only if its
+// number is not used (see 'VoteStore.AppendAudit'): if two votes go at once, one of them
+// reads the head again and retries. SQL databases append the entry in the transaction of
+// the vote (see 'VoteRecorder'). The other stores append it before the counts are changed,
+// and if the vote fails after that, the entry is reversed by a 'void' entry, the log is
+// never changed.
+
+// These are the actions of the entries.
+const AUDIT_VOTE = "vote"
+const AUDIT_REVISE = "revise"
+const AUDIT_RETRACT = "retract"
+const AUDIT_VOID = "void" // It reverses the entry of a failed vote;
+
+// It's the 'prev' of the first entry, and the head of the empty log.
+const AUDIT_GENESIS = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// The number of attempts to append the entry if the log is changed in the meantime.
+const AUDIT_RETRIES = 10
+
+// ErrAuditConflict is returned by the store if the number of the entry is used already.
+var ErrAuditConflict = fmt.Errorf("%w: audit log has been changed in the meantime", ErrConflict)
+
+// AuditEntry is a record of the audit log of the poll.
+type AuditEntry struct {
+	VoteId int           `json:"vote_id"`
+	Seq    int64         `json:"seq"` // 1, 2, 3, ...
+	At     time.Time     `json:"at"`  // Milliseconds, UTC;
+	Action string        `json:"action"`
+	Counts map[int16]int `json:"counts"` // co_id -> delta of the count;
+	Prev   string        `json:"prev"`   // The hash of the previous entry, or AUDIT_GENESIS;
+	Hash   string        `json:"hash"`   // SHA-256 of the entry (see 'digest'), hex;
+}
+
+// AuditHead is the last entry of the log without the counts, see 'GetAuditHead'.
+type AuditHead struct {
+	VoteId int       `json:"vote_id"`
+	Seq    int64     `json:"seq"` // The number of the entries, 0 if the log is empty;
+	At     time.Time `json:"at"`
+	Hash   string    `json:"hash"`
+}
+
+// It returns a new entry, it's chained by 'next'.
+func newAuditEntry(action string, counts map[int16]int) *AuditEntry {
+	return &AuditEntry{Action: action, Counts: counts}
+}
+
+// It returns the entry following the 'head' (nil if the log is empty), with the time and the hash.
+func (e *AuditEntry) next(vote_id int, head *AuditEntry) *AuditEntry {
+	res := &AuditEntry{
+		VoteId: vote_id,
+		Seq:    1,
+		At:     time.Now().UTC().Truncate(time.Millisecond),
+		Action: e.Action,
+		Counts: e.Counts,
+		Prev:   AUDIT_GENESIS,
+	}
+	if head != nil {
+		res.Seq = head.Seq + 1
+		res.Prev = head.Hash
+	}
+	res.Hash = res.digest()
+	return res
+}
+
+// It's the hash of the entry: SHA-256 of the lines "vote_id", "seq", "at" (RFC 3339),
+// "action", "counts" (see 'formatCounts') and "prev".
+func (e *AuditEntry) digest() string {
+	s := strings.Join([]string{strconv.Itoa(e.VoteId), strconv.FormatInt(e.Seq, 10),
+		e.At.UTC().Format(time.RFC3339Nano), e.Action, formatCounts(e.Counts), e.Prev}, "\n")
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// It's the entry reversing this one, see 'AUDIT_VOID'.
+func (e *AuditEntry) void() *AuditEntry {
+	counts := make(map[int16]int, len(e.Counts))
+	for co_id, n := range e.Counts {
+		counts[co_id] = -n
+	}
+	return newAuditEntry(AUDIT_VOID, counts)
+}
+
+// The counts are sorted by co_id: "1:1,3:-1".
+func formatCounts(counts map[int16]int) string {
+	ids := make([]int, 0, len(counts))
+	for co_id := range counts {
+		ids = append(ids, int(co_id))
+	}
+	sort.Ints(ids)
+
+	s := make([]string, len(ids))
+	for i, co_id := range ids {
+		s[i] = strconv.Itoa(co_id) + ":" + strconv.Itoa(counts[int16(co_id)])
+	}
+	return strings.Join(s, ",")
+}
+
+func parseCounts(s string) (map[int16]int, error) {
+	res := map[int16]int{}
+	if s == "" {
+		return res, nil
+	}
+	for _, p := range strings.Split(s, ",") {
+		co, n, ok := strings.Cut(p, ":")
+		if !ok {
+			return nil, fmt.Errorf("bad counts %q", s)
+		}
+		co_id, err := strconv.ParseInt(co, 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		if res[int16(co_id)], err = strconv.Atoi(n); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// It returns the entry of the vote recorded by 'castVote'.
+func (r *VoteRecord) auditEntry() *AuditEntry {
+	counts := map[int16]int{}
+	if r.Scores != nil {
+		for co_id := range r.Scores {
+			counts[co_id] = 1 // The count of a score poll is the number of the ratings;
+		}
+	} else {
+		for _, co_id := range r.Counts {
+			counts[co_id] = 1
+		}
+	}
+	return newAuditEntry(AUDIT_VOTE, counts)
+}
+
+// It appends the entry to the log, and retries if the log is changed in the meantime.
+func (b *basicVoteService) appendAudit(ctx context.Context, vote_id int, e *AuditEntry) error {
+	for i := 0; i < AUDIT_RETRIES; i++ {
+		head, err := b.store.LoadAuditHead(ctx, vote_id)
+		if err == ErrNotFound {
+			head, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+
+		applied, err := b.store.AppendAudit(ctx, vote_id, e.next(vote_id, head))
+		if err != nil || applied {
+			return err
+		}
+	}
+	return ErrAuditConflict
+}
+
+//////////////////
+//
+// GET AUDIT HEAD
+//
+//////////////////
+
+// It returns the head of the audit log of the poll: the number of the entries, the time and
+// the hash of the last one (AUDIT_GENESIS if the log is empty). The head is public, anybody
+// can save it and check later that the log has not been changed before it (see 'VerifyAudit').
+
+func (b *basicVoteService) GetAuditHead(ctx context.Context, vote_id int) (*AuditHead, error) {
+	if _, err := b.loadVote(ctx, vote_id); err != nil {
+		return nil, err
+	}
+
+	head, err := b.store.LoadAuditHead(ctx, vote_id)
+	if err == ErrNotFound {
+		return &AuditHead{VoteId: vote_id, Hash: AUDIT_GENESIS}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &AuditHead{VoteId: vote_id, Seq: head.Seq, At: head.At, Hash: head.Hash}, nil
+}
+
+////////////////
+//
+// VERIFY AUDIT
+//
+////////////////
+
+// AuditReport is the result of 'VerifyAudit'.
+type AuditReport struct {
+	VoteId      int
+	Entries     int64
+	Head        string          // The hash of the last entry, or AUDIT_GENESIS;
+	Broken      int64           // The number of the first entry breaking the chain, or 0;
+	Reason      string          // Why it's broken;
+	Tallies     map[int16]int64 // The counts recomputed from the log;
+	Divergences []AuditDivergence
+}
+
+// AuditDivergence is the contender whose count is not the one recomputed from the log.
+type AuditDivergence struct {
+	ContenderId int16
+	Logged      int64
+	Counted     int64
+}
+
+// OK is true if the chain is not broken and the counts are the same as in the log.
+func (r *AuditReport) OK() bool {
+	return r.Broken == 0 && len(r.Divergences) == 0
+}
+
+// VerifyAudit checks the audit log of the poll: each entry must follow the previous one
+// and have the right hash. The tallies are recomputed from the log and compared with the
+// counts of the contenders (the numbers of the ratings in a score poll). Note that the
+// votes given before the log was introduced are not in the log, they are divergences.
+// It's used by 'vote-svc audit', see 'cmd/main.go'.
+
+func VerifyAudit(ctx context.Context, store VoteStore, vote_id int) (*AuditReport, error) {
+	b := &basicVoteService{store: store}
+	vote, err := store.LoadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+	if vote.Method == VOTE_METHOD_SCORE {
+		if err = b.scoreStats(ctx, vote); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := store.LoadAudit(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &AuditReport{VoteId: vote_id, Entries: int64(len(entries)), Head: AUDIT_GENESIS,
+		Tallies: map[int16]int64{}}
+	for i, e := range entries {
+		switch {
+		case e.Seq != int64(i+1):
+			res.Reason = fmt.Sprintf("entry # %d is missing", i+1)
+		case e.Prev != res.Head:
+			res.Reason = "previous hash mismatch"
+		case e.VoteId != vote_id || e.Hash != e.digest():
+			res.Reason = "hash mismatch"
+		}
+		if res.Reason != "" {
+			res.Broken = int64(i + 1)
+			break
+		}
+
+		res.Head = e.Hash
+		for co_id, n := range e.Counts {
+			res.Tallies[co_id] += int64(n)
+		}
+	}
+
+	for _, c := range vote.Contenders {
+		if res.Tallies[c.Id] != c.Count {
+			res.Divergences = append(res.Divergences, AuditDivergence{c.Id, res.Tallies[c.Id], c.Count})
+		}
+	}
+	return res, nil
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,143 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAuditVote(t *testing.T) {
+	testAuditVote(t, load_store(t))
+}
+
+// It checks the audit log of the votes, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testAuditVote(t *testing.T, store VoteStore) {
+	testinfo := "test AuditVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	if _, err := svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the log is empty;
+		head, err := svc.GetAuditHead(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || head.Seq != 0 || head.Hash != AUDIT_GENESIS {
+			t.Errorf("test %v (case # 1) failed, head %+v, error: %v", testinfo, head, err)
+		}
+		if _, err = svc.GetAuditHead(public, TESTDATA_NEW_VOTE_ID+1); err != ErrNotFound {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+
+		// Case 2: each vote is an entry, the failed one is voided;
+		for i, co_id := range []int16{1, 2, 1} {
+			if _, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, co_id, TESTDATA_USER_ID+string(rune('a'+i))); err != nil {
+				t.Fatalf("test %v (case # 2) failed, error: %v", testinfo, err)
+			}
+		}
+		broken := New(brokenVoteStore{store}, []Middleware{})
+		if _, err = broken.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID+"-x"); err == nil {
+			t.Errorf("test %v (case # 2) failed, error is nil", testinfo)
+		}
+		entries, err := store.LoadAudit(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || len(entries) != 5 || entries[3].Action != AUDIT_VOTE || entries[4].Action != AUDIT_VOID ||
+			entries[4].Counts[2] != -1 || entries[1].Prev != entries[0].Hash {
+			t.Fatalf("test %v (case # 2) failed, %d entries, error: %v", testinfo, len(entries), err)
+		}
+		head, err = svc.GetAuditHead(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || head.Seq != 5 || head.Hash != entries[4].Hash || !head.At.Equal(entries[4].At) {
+			t.Errorf("test %v (case # 2) failed, head %+v, error: %v", testinfo, head, err)
+		}
+
+		r, err := VerifyAudit(public, store, TESTDATA_NEW_VOTE_ID)
+		if err != nil || !r.OK() || r.Entries != 5 || r.Head != head.Hash || r.Tallies[1] != 2 || r.Tallies[2] != 1 {
+			t.Errorf("test %v (case # 2) failed, report %+v, error: %v", testinfo, r, err)
+		}
+
+		// Case 3: the changes of the revisable votes are entries too;
+		yes := true
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Revisable: &yes}); err != nil {
+			t.Fatalf("test %v (case # 3) failed, UpdateVote err %v", testinfo, err)
+		}
+		for _, co_id := range []int16{1, 2} {
+			if _, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, co_id, TESTDATA_USER_ID+"-r"); err != nil {
+				t.Fatalf("test %v (case # 3) failed, error: %v", testinfo, err)
+			}
+		}
+		if err = svc.RetractVote(public, TESTDATA_NEW_VOTE_ID, TESTDATA_USER_ID+"-r"); err != nil {
+			t.Fatalf("test %v (case # 3) failed, error: %v", testinfo, err)
+		}
+		broken = New(brokenMoveStore{store}, []Middleware{})
+		if _, err = broken.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-r"); err == nil {
+			t.Errorf("test %v (case # 3) failed, error is nil", testinfo)
+		}
+
+		r, err = VerifyAudit(public, store, TESTDATA_NEW_VOTE_ID)
+		if err != nil || !r.OK() || r.Entries != 10 || r.Tallies[1] != 2 || r.Tallies[2] != 1 {
+			t.Errorf("test %v (case # 3) failed, report %+v, error: %v", testinfo, r, err)
+		}
+		if entries, err = store.LoadAudit(public, TESTDATA_NEW_VOTE_ID); err != nil || len(entries) != 10 {
+			t.Fatalf("test %v (case # 3) failed, %d entries, error: %v", testinfo, len(entries), err)
+		}
+		for i, action := range []string{AUDIT_VOTE, AUDIT_REVISE, AUDIT_RETRACT, AUDIT_VOTE, AUDIT_VOID} {
+			if entries[i+5].Action != action {
+				t.Errorf("test %v (case # 3) failed, entry # %d is %q (must be %q)", testinfo, i+6, entries[i+5].Action, action)
+			}
+		}
+
+		// Case 4: the count changed behind the log is a divergence;
+		if _, err = store.IncrementCounts(public, TESTDATA_NEW_VOTE_ID, []int16{1}); err != nil {
+			t.Fatalf("test %v (case # 4) failed, IncrementCounts err %v", testinfo, err)
+		}
+		r, err = VerifyAudit(public, store, TESTDATA_NEW_VOTE_ID)
+		if err != nil || r.OK() || len(r.Divergences) != 1 || r.Divergences[0] != (AuditDivergence{1, 2, 3}) {
+			t.Errorf("test %v (case # 4) failed, report %+v, error: %v", testinfo, r, err)
+		}
+
+		// Case 5: the forged entry breaks the chain;
+		forged := newAuditEntry(AUDIT_VOTE, map[int16]int{1: 1}).next(TESTDATA_NEW_VOTE_ID, entries[9])
+		forged.Counts = map[int16]int{2: 1}
+		if applied, err := store.AppendAudit(public, TESTDATA_NEW_VOTE_ID, forged); err != nil || !applied {
+			t.Fatalf("test %v (case # 5) failed, applied %v, error: %v", testinfo, applied, err)
+		}
+		r, err = VerifyAudit(public, store, TESTDATA_NEW_VOTE_ID)
+		if err != nil || r.OK() || r.Broken != 11 || r.Head != entries[9].Hash {
+			t.Errorf("test %v (case # 5) failed, report %+v, error: %v", testinfo, r, err)
+		}
+		if applied, err := store.AppendAudit(public, TESTDATA_NEW_VOTE_ID, forged); err != nil || applied {
+			t.Errorf("test %v (case # 5) failed, applied %v, error: %v", testinfo, applied, err)
+		}
+	})
+}
+
+func TestAuditCounts(t *testing.T) {
+	testinfo := "test AuditCounts"
+
+	var cases = []struct {
+		counts map[int16]int
+		want   string
+	}{
+		{map[int16]int{}, ""},
+		{map[int16]int{3: -1, 1: 1}, "1:1,3:-1"},
+		{map[int16]int{12: 1, 2: 1, 7: 1}, "2:1,7:1,12:1"},
+	}
+
+	for i, c := range cases {
+		s := formatCounts(c.counts)
+		res, err := parseCounts(s)
+		if s != c.want || err != nil || formatCounts(res) != s {
+			t.Errorf("test %v (case # %d) failed, %q (must be %q), error: %v", testinfo, i+1, s, c.want, err)
+		}
+	}
+	if _, err := parseCounts("1:1,x"); err == nil {
+		t.Errorf("test %v failed, error is nil", testinfo)
+	}
+}
+
+// --- END OF FILE ---
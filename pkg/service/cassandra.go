@@ -0,0 +1,2093 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/gocql/gocql"
+)
+
+// The keyspace used unless it's changed by 'WithKeyspace'.
+const CASSANDRA_DEFAULT_KEYSPACE = "polls"
+
+// This is a single record of the table 'polls.votes'. The columns which can be null
+// (Cassandra has no NOT NULL constraint) are received as pointers, and nil means null.
+// The primary key columns ('vote_id', 'co_id') are never null.
+type cassandraVoteRow struct {
+	voteId       int
+	co_id        int16
+	header       *string
+	message      *string
+	resources    *string
+	deadline     *time.Time
+	authenticate *bool
+	allowresults *bool
+	co_name      *string
+	co_alias     *string
+	co_info      *string
+	co_picture   *string
+	co_count     *int64
+	co_updated   *time.Time
+	co_withdrawn *bool
+	state        *string
+	opens_at     *time.Time
+	require_code *bool
+	method       *string
+	max_choices  *int
+	score_min    *int
+	score_max    *int
+	revisable    *bool
+	public_key   *string
+	poll_version *int64
+}
+
+// The columns of 'polls.votes' in the order they are selected and scanned (see 'dest' below).
+var cassandraVoteColumns = []struct {
+	name string
+	typ  gocql.Type
+}{
+	{"vote_id", gocql.TypeInt},
+	{"co_id", gocql.TypeSmallInt},
+	{"header", gocql.TypeText},
+	{"message", gocql.TypeText},
+	{"resources", gocql.TypeText},
+	{"deadline", gocql.TypeTimestamp},
+	{"authenticate", gocql.TypeBoolean},
+	{"allowresults", gocql.TypeBoolean},
+	{"co_name", gocql.TypeText},
+	{"co_alias", gocql.TypeText},
+	{"co_info", gocql.TypeText},
+	{"co_picture", gocql.TypeText},
+	{"co_count", gocql.TypeBigInt},
+	{"co_updated", gocql.TypeTimestamp},
+	{"co_withdrawn", gocql.TypeBoolean},
+	{"state", gocql.TypeText},
+	{"opens_at", gocql.TypeTimestamp},
+	{"require_code", gocql.TypeBoolean},
+	{"method", gocql.TypeText},
+	{"max_choices", gocql.TypeInt},
+	{"score_min", gocql.TypeInt},
+	{"score_max", gocql.TypeInt},
+	{"revisable", gocql.TypeBoolean},
+	{"public_key", gocql.TypeText},
+	{"poll_version", gocql.TypeBigInt},
+}
+
+func (r *cassandraVoteRow) dest() []interface{} {
+	return []interface{}{
+		&r.voteId, &r.co_id, &r.header, &r.message, &r.resources, &r.deadline, &r.authenticate,
+		&r.allowresults, &r.co_name, &r.co_alias, &r.co_info, &r.co_picture, &r.co_count, &r.co_updated,
+		&r.co_withdrawn, &r.state, &r.opens_at, &r.require_code, &r.method, &r.max_choices,
+		&r.score_min, &r.score_max, &r.revisable, &r.public_key, &r.poll_version,
+	}
+}
+
+// CQL statements, they are built once and reused by all requests. The driver prepares each
+// statement on the first use and keeps it in the cache of prepared statements, so the
+// following requests send only the statement id and the values.
+type cassandraStatements struct {
+	loadVote       string
+	addVoter       string
+	incrementCount string
+	removeVoter    string
+	ping           string
+
+	// Poll administration (see 'admin.go').
+	insertContender string
+	updateVote      string
+	guardVote       string
+	guardContender  string
+	updateContender string
+	deleteVote      string
+	deleteVoters    string
+	deleteCounts    string
+
+	// The counter table 'polls.vote_counts' (see 'WithCounterTable').
+	loadCounts     string
+	loadCount      string
+	addCount       string
+	touchContender string
+	copyCounts     string
+
+	// The eligibility rolls 'polls.rolls' (see 'roll.go').
+	insertRoll string
+	deleteRoll string
+	checkRoll  string
+	anyRoll    string
+	loadRoll   string
+	loadVoters string
+
+	// The invitation codes 'polls.codes' (see 'code.go').
+	insertCode  string
+	burnCode    string
+	restoreCode string
+	revokeCode  string
+	unusedCodes string
+	deleteCodes string
+
+	// The ranked ballots 'polls.ballots' (see 'ranked.go').
+	insertBallot  string
+	deleteBallot  string
+	loadBallots   string
+	deleteBallots string
+
+	// The ratings of the score polls, the counter table 'polls.scores' (see 'score.go').
+	rateContender string
+	addScore      string
+	loadScores    string
+	deleteScores  string
+
+	// The choices of the voters of the revisable polls (see 'revise.go').
+	loadChoice     string
+	insertChoice   string
+	updateChoice   string
+	deleteChoice   string
+	decrementCount string
+
+	// The receipts of the votes 'polls.receipts' (see 'receipt.go').
+	insertReceipt  string
+	deleteReceipt  string
+	loadReceipt    string
+	deleteReceipts string
+	sealReceipts   string
+	unsealReceipts string
+
+	// The receipts of the poll and their root 'polls.commitments' (see 'commitment.go').
+	loadReceipts     string
+	insertCommitment string
+	loadCommitment   string
+	deleteCommitment string
+
+	// The audit log 'polls.audit' (see 'audit.go').
+	insertAudit   string
+	loadAuditHead string
+	loadAudit     string
+	deleteAudit   string
+
+	// The encrypted ballots 'polls.encrypted_ballots' and their tally 'polls.tallies' (see 'encrypted.go').
+	insertEncryptedBallot  string
+	deleteEncryptedBallot  string
+	loadEncryptedBallots   string
+	deleteEncryptedBallots string
+	insertTally            string
+	loadTally              string
+	deleteTally            string
+}
+
+// The keyspace cannot be a bind marker, it's a part of the statement. It's not supposed to
+// come from the users, but anyway it's checked by 'WithKeyspace'.
+func newCassandraStatements(keyspace string) cassandraStatements {
+	names := make([]string, len(cassandraVoteColumns))
+	for i, col := range cassandraVoteColumns {
+		names[i] = col.name
+	}
+
+	votes := keyspace + ".votes"
+	voters := keyspace + ".voters"
+	counts := keyspace + ".vote_counts"
+	rolls := keyspace + ".rolls"
+	codes := keyspace + ".codes"
+	ballots := keyspace + ".ballots"
+	scores := keyspace + ".scores"
+	receipts := keyspace + ".receipts"
+	audit := keyspace + ".audit"
+	commitments := keyspace + ".commitments"
+	encrypted := keyspace + ".encrypted_ballots"
+	tallies := keyspace + ".tallies"
+
+	// Note that these are CQL statements (Cassandra Query Language), not SQL.
+	return cassandraStatements{
+		loadVote: "SELECT " + strings.Join(names, ", ") + " FROM " + votes + " WHERE vote_id = ?",
+		addVoter: "INSERT INTO " + voters + " (vote_id, user_id, created) VALUES(?, ?, toTimeStamp(now())) IF NOT EXISTS",
+		incrementCount: "UPDATE " + votes + ` SET co_count = co_count + 1, co_updated = toTimeStamp(now())
+		WHERE vote_id = ? AND co_id = ? IF EXISTS`,
+		removeVoter: "DELETE FROM " + voters + " WHERE vote_id = ? AND user_id = ?",
+		ping:        "SELECT release_version FROM system.local",
+
+		insertContender: "INSERT INTO " + votes + " (" + strings.Join(names, ", ") + ") VALUES(" +
+			strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ") + ") IF NOT EXISTS",
+		updateVote: "UPDATE " + votes + ` SET header = ?, message = ?, resources = ?, deadline = ?,
+		authenticate = ?, allowresults = ?, state = ?, opens_at = ?, require_code = ?, method = ?,
+		max_choices = ?, score_min = ?, score_max = ?, revisable = ?, public_key = ? WHERE vote_id = ? AND co_id = ?
+		IF EXISTS`,
+		guardVote:      "UPDATE " + votes + " SET poll_version = ? WHERE vote_id = ? IF poll_version = ?",
+		guardContender: "UPDATE " + votes + " SET poll_version = ? WHERE vote_id = ? AND co_id = ? IF EXISTS",
+		updateContender: "UPDATE " + votes + ` SET co_name = ?, co_alias = ?, co_info = ?, co_picture = ?,
+		co_withdrawn = ? WHERE vote_id = ? AND co_id = ? IF EXISTS`,
+		deleteVote:   "DELETE FROM " + votes + " WHERE vote_id = ?",
+		deleteVoters: "DELETE FROM " + voters + " WHERE vote_id = ?",
+		deleteCounts: "DELETE FROM " + counts + " WHERE vote_id = ?",
+
+		loadCounts:     "SELECT co_id, co_count FROM " + counts + " WHERE vote_id = ?",
+		loadCount:      "SELECT co_count FROM " + counts + " WHERE vote_id = ? AND co_id = ?",
+		addCount:       "UPDATE " + counts + " SET co_count = co_count + ? WHERE vote_id = ? AND co_id = ?",
+		touchContender: "UPDATE " + votes + " SET co_updated = toTimeStamp(now()) WHERE vote_id = ? AND co_id = ? IF EXISTS",
+		copyCounts:     "SELECT vote_id, co_id, co_count FROM " + votes,
+
+		insertRoll: "INSERT INTO " + rolls + " (vote_id, entry) VALUES(?, ?)",
+		deleteRoll: "DELETE FROM " + rolls + " WHERE vote_id = ?",
+		checkRoll:  "SELECT entry FROM " + rolls + " WHERE vote_id = ? AND entry = ?",
+		anyRoll:    "SELECT entry FROM " + rolls + " WHERE vote_id = ? LIMIT 1",
+		loadRoll:   "SELECT entry FROM " + rolls + " WHERE vote_id = ?",
+		loadVoters: "SELECT user_id FROM " + voters + " WHERE vote_id = ?",
+
+		insertCode: "INSERT INTO " + codes + " (vote_id, code, created, revoked) VALUES(?, ?, toTimeStamp(now()), false) IF NOT EXISTS",
+		burnCode: "UPDATE " + codes + ` SET used = toTimeStamp(now()) WHERE vote_id = ? AND code = ?
+		IF used = null AND revoked = false`,
+		restoreCode: "UPDATE " + codes + " SET used = null WHERE vote_id = ? AND code = ? IF EXISTS",
+		revokeCode: "UPDATE " + codes + ` SET revoked = true WHERE vote_id = ? AND code = ?
+		IF used = null AND revoked = false`,
+		unusedCodes: "SELECT code, used, revoked FROM " + codes + " WHERE vote_id = ?",
+		deleteCodes: "DELETE FROM " + codes + " WHERE vote_id = ?",
+
+		insertBallot: "INSERT INTO " + ballots + ` (vote_id, ballot_id, ranking, created)
+		VALUES(?, ?, ?, toTimeStamp(now())) IF NOT EXISTS`,
+		deleteBallot:  "DELETE FROM " + ballots + " WHERE vote_id = ? AND ballot_id = ?",
+		loadBallots:   "SELECT ranking FROM " + ballots + " WHERE vote_id = ?",
+		deleteBallots: "DELETE FROM " + ballots + " WHERE vote_id = ?",
+
+		rateContender: "UPDATE " + votes + " SET co_updated = toTimeStamp(now()) WHERE vote_id = ? AND co_id = ? IF EXISTS",
+		addScore:      "UPDATE " + scores + " SET ratings = ratings + 1 WHERE vote_id = ? AND co_id = ? AND score = ?",
+		loadScores:    "SELECT co_id, score, ratings FROM " + scores + " WHERE vote_id = ?",
+		deleteScores:  "DELETE FROM " + scores + " WHERE vote_id = ?",
+
+		loadChoice:   "SELECT co_id FROM " + voters + " WHERE vote_id = ? AND user_id = ?",
+		insertChoice: "INSERT INTO " + voters + " (vote_id, user_id, created, co_id) VALUES(?, ?, toTimeStamp(now()), ?) IF NOT EXISTS",
+		updateChoice: "UPDATE " + voters + " SET co_id = ? WHERE vote_id = ? AND user_id = ? IF co_id = ?",
+		deleteChoice: "DELETE FROM " + voters + " WHERE vote_id = ? AND user_id = ? IF co_id = ?",
+		decrementCount: "UPDATE " + votes + ` SET co_count = co_count - 1, co_updated = toTimeStamp(now())
+		WHERE vote_id = ? AND co_id = ? IF EXISTS`,
+
+		insertReceipt: "UPDATE " + receipts + ` SET cast_at = ?, user_id = ? WHERE vote_id = ? AND receipt = ?
+		IF cast_at = null AND sealed != true`,
+		deleteReceipt:  "DELETE FROM " + receipts + " WHERE vote_id = ? AND receipt = ?",
+		loadReceipt:    "SELECT cast_at, user_id FROM " + receipts + " WHERE vote_id = ? AND receipt = ?",
+		deleteReceipts: "DELETE FROM " + receipts + " WHERE vote_id = ?",
+		sealReceipts:   "UPDATE " + receipts + " SET sealed = true WHERE vote_id = ? IF sealed != true",
+		unsealReceipts: "UPDATE " + receipts + " SET sealed = false WHERE vote_id = ? IF sealed = true",
+
+		loadReceipts:     "SELECT receipt, cast_at FROM " + receipts + " WHERE vote_id = ?",
+		insertCommitment: "INSERT INTO " + commitments + " (vote_id, root, leaves, committed_at) VALUES(?, ?, ?, ?) IF NOT EXISTS",
+		loadCommitment:   "SELECT root, leaves, committed_at FROM " + commitments + " WHERE vote_id = ?",
+		deleteCommitment: "DELETE FROM " + commitments + " WHERE vote_id = ?",
+
+		insertAudit: "INSERT INTO " + audit + ` (vote_id, seq, at, action, counts, prev, hash)
+		VALUES(?, ?, ?, ?, ?, ?, ?) IF NOT EXISTS`,
+		loadAuditHead: "SELECT seq, at, action, counts, prev, hash FROM " + audit + " WHERE vote_id = ? ORDER BY seq DESC LIMIT 1",
+		loadAudit:     "SELECT seq, at, action, counts, prev, hash FROM " + audit + " WHERE vote_id = ?",
+		deleteAudit:   "DELETE FROM " + audit + " WHERE vote_id = ?",
+
+		insertEncryptedBallot: "INSERT INTO " + encrypted + ` (vote_id, ballot_id, ballot, created)
+		VALUES(?, ?, ?, toTimeStamp(now())) IF NOT EXISTS`,
+		deleteEncryptedBallot:  "DELETE FROM " + encrypted + " WHERE vote_id = ? AND ballot_id = ?",
+		loadEncryptedBallots:   "SELECT ballot FROM " + encrypted + " WHERE vote_id = ?",
+		deleteEncryptedBallots: "DELETE FROM " + encrypted + " WHERE vote_id = ?",
+		insertTally:            "INSERT INTO " + tallies + " (vote_id, tally, tallied_at) VALUES(?, ?, ?) IF NOT EXISTS",
+		loadTally:              "SELECT tally FROM " + tallies + " WHERE vote_id = ?",
+		deleteTally:            "DELETE FROM " + tallies + " WHERE vote_id = ?",
+	}
+}
+
+// Unquoted CQL names are case insensitive: letters, digits and underscores, up to 48 chars.
+var cassandraNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,47}$`)
+
+// The database is Apache Cassandra noSQL/CQL. The store owns a single session: it is created
+// at startup and shared by all requests (gocql session is safe for concurrent use and keeps
+// the pool of connections to each node). If the session cannot be created (e.g. database is
+// down) or it's broken, a new one is created on the next request.
+type cassandraVoteStore struct {
+	db       *gocql.ClusterConfig
+	metrics  *CassandraMetrics
+	stmt     cassandraStatements
+	keyspace string
+	readCL   gocql.Consistency // Consistency of the queries reading the data;
+	writeCL  gocql.Consistency // ... and writing the data, see 'WithConsistency';
+	counters bool              // The counts are in 'polls.vote_counts', see 'WithCounterTable';
+
+	mu      sync.Mutex
+	session *gocql.Session
+	closed  bool
+
+	hostsMu sync.Mutex
+	hosts   map[string]*gocql.HostInfo // All nodes seen by the driver;
+}
+
+// CassandraMetrics describes the database connection pool, see 'getCassandraMetrics' in 'cmd/main.go'.
+// Any of the fields can be nil.
+type CassandraMetrics struct {
+	Sessions  metrics.Counter   // The number of sessions created, i.e. (re)connects to the cluster;
+	SessionUp metrics.Gauge     // It's 1 if the session is open, and 0 if it's not;
+	Connects  metrics.Counter   // Connections to the nodes, label "success";
+	HostsUp   metrics.Gauge     // The number of nodes which are up;
+	HostsDown metrics.Gauge     // The number of nodes which are known, but down;
+	Queries   metrics.Histogram // Query duration in seconds, label "success";
+}
+
+// CassandraOption changes the default behavior of the store, see 'NewCassandraVoteStore'.
+type CassandraOption func(*cassandraVoteStore)
+
+// WithKeyspace sets the keyspace of the tables, the default is "polls". It panics if the name
+// is not a valid CQL name (it's a part of the CQL statements, see 'newCassandraStatements'),
+// use 'CheckCassandraKeyspace' to check the name which comes from the config.
+func WithKeyspace(keyspace string) CassandraOption {
+	if err := CheckCassandraKeyspace(keyspace); err != nil {
+		panic(err)
+	}
+	return func(c *cassandraVoteStore) {
+		c.keyspace = keyspace
+	}
+}
+
+// CheckCassandraKeyspace returns an error if the keyspace is not a valid unquoted CQL name.
+func CheckCassandraKeyspace(keyspace string) error {
+	if !cassandraNameRegexp.MatchString(keyspace) {
+		return fmt.Errorf("invalid Cassandra keyspace %q", keyspace)
+	}
+	return nil
+}
+
+// WithConsistency sets the consistency levels of the queries reading and writing the data,
+// the default for both is the consistency of the cluster config. For example, QUORUM for
+// writes and ONE for reads is faster, but the results may be a bit outdated.
+func WithConsistency(read, write gocql.Consistency) CassandraOption {
+	return func(c *cassandraVoteStore) {
+		c.readCL = read
+		c.writeCL = write
+	}
+}
+
+// WithCounterTable makes the store keep the counts in the counter table 'polls.vote_counts'
+// (see 'scripts/table3.cql') instead of the column 'co_count' of 'polls.votes'. The column is
+// updated by a lightweight transaction (UPDATE ... IF EXISTS), and all votes of a poll wait
+// for each other (Paxos). The counter is updated without any transaction, it's much faster.
+// The existing counts can be copied to the counter table by 'MigrateCassandraCounts'.
+func WithCounterTable() CassandraOption {
+	return func(c *cassandraVoteStore) {
+		c.counters = true
+	}
+}
+
+/////////////
+//
+// LOAD VOTE
+//
+/////////////
+
+// The number of records related to a specific 'vote_id' is equal to the number of
+// contenders which is not supposed to be large, maybe 2..20). This data is packed into
+// a single struct 'VoteData' containing an array (i.e. slice) of the type struct 'Contenders'
+// with the data about contenders (id, name, picture, ...).
+
+func (c *cassandraVoteStore) LoadVote(ctx context.Context, vote_id int) (*VoteData, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	vote, _, err := c.loadVote(ctx, session, vote_id)
+	return vote, err
+}
+
+// It returns the vote and the version of the poll-level columns ('poll_version' is static, the
+// same for all the records of the vote; it's null for the votes created before the column).
+// The version is the condition of the writes changing these columns (see 'UpdateVote').
+func (c *cassandraVoteStore) loadVote(ctx context.Context, session *gocql.Session, vote_id int) (*VoteData, *int64, error) {
+	// In general, it's supposed to fetch at least two records (two contenders are minimum,
+	// there is no sense to have election if you have only one candidate). Once again, this
+	// is not SQL database, the tables are not normalized and some data is duplicated.
+
+	var err error
+	iterable := c.read(ctx, session, c.stmt.loadVote, vote_id).Iter()
+
+	// The columns are checked before scanning, it's better than an error about some
+	// value which cannot be unmarshalled (or a zero value silently received).
+	if err = checkVoteColumns(c.keyspace+".votes", iterable.Columns()); err != nil {
+		iterable.Close()
+		return nil, nil, err
+	}
+
+	records := []cassandraVoteRow{} // This is an intermediate slice to receive the database records;
+	scanner := iterable.Scanner()
+
+	for scanner.Next() {
+		var r cassandraVoteRow
+		if err = scanner.Scan(r.dest()...); err != nil {
+			scanner.Err() // It closes the iterator;
+			return nil, nil, c.cassandraError(session, err)
+		}
+		records = append(records, r)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, nil, c.cassandraError(session, err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil, ErrNotFound
+	}
+
+	// The 'co_count' column is not used with the counter table.
+	if c.counters {
+		counts, err := c.loadCounts(ctx, session, vote_id)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range records {
+			count := counts[records[i].co_id]
+			records[i].co_count = &count
+		}
+	}
+
+	// Let's repack database records into a single struct 'VoteData'. Null values become
+	// zero values, e.g. a contender without 'co_count' has no votes yet, and a vote without
+	// 'deadline' is closed (the deadline is 0001-01-01).
+	var contenders []Contender
+	for _, r := range records {
+		contenders = append(contenders, Contender{
+			Id:      r.co_id,
+			Name:    nullString(r.co_name),
+			Alias:   nullString(r.co_alias),
+			Info:    nullString(r.co_info),
+			Count:   nullInt64(r.co_count),
+			Updated: nullTime(r.co_updated),
+			Picture: nullString(r.co_picture),
+
+			Withdrawn: nullBool(r.co_withdrawn),
+		})
+	}
+
+	var res VoteData = VoteData{
+		VoteId:       records[0].voteId,
+		Header:       nullString(records[0].header),
+		Message:      nullString(records[0].message),
+		Resources:    nullString(records[0].resources),
+		Deadline:     nullTime(records[0].deadline),
+		Authenticate: nullBool(records[0].authenticate),
+		AllowResults: nullBool(records[0].allowresults),
+		State:        nullString(records[0].state),
+		OpensAt:      nullTime(records[0].opens_at),
+		RequireCode:  nullBool(records[0].require_code),
+		Method:       nullString(records[0].method),
+		MaxChoices:   nullInt(records[0].max_choices),
+		ScoreMin:     nullInt(records[0].score_min),
+		ScoreMax:     nullInt(records[0].score_max),
+		Revisable:    nullBool(records[0].revisable),
+		PublicKey:    nullString(records[0].public_key),
+		Contenders:   contenders,
+	}
+
+	return &res, records[0].poll_version, nil
+}
+
+// It returns the counts of the contenders from the counter table. A contender without votes
+// may have no record there (counters are created by the first update).
+func (c *cassandraVoteStore) loadCounts(ctx context.Context, session *gocql.Session, vote_id int) (map[int16]int64, error) {
+	counts := map[int16]int64{}
+	scanner := c.read(ctx, session, c.stmt.loadCounts, vote_id).Iter().Scanner()
+
+	for scanner.Next() {
+		var co_id int16
+		var co_count int64
+		if err := scanner.Scan(&co_id, &co_count); err != nil {
+			scanner.Err() // It closes the iterator;
+			return nil, c.cassandraError(session, err)
+		}
+		counts[co_id] = co_count
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, c.cassandraError(session, err)
+	}
+	return counts, nil
+}
+
+// It returns ErrSchemaMismatch if the columns received from the database are not the
+// ones expected by 'cassandraVoteRow' (the number, the names, and the types).
+func checkVoteColumns(table string, columns []gocql.ColumnInfo) error {
+	if len(columns) == 0 {
+		return nil // The query failed, the error is returned by the iterator;
+	}
+
+	if len(columns) != len(cassandraVoteColumns) {
+		return fmt.Errorf("%w: %s: got %d columns, want %d", ErrSchemaMismatch, table, len(columns), len(cassandraVoteColumns))
+	}
+
+	for i, col := range columns {
+		want := cassandraVoteColumns[i]
+		if col.Name != want.name {
+			return fmt.Errorf("%w: %s: got column %s, want %s", ErrSchemaMismatch, table, col.Name, want.name)
+		}
+
+		got := col.TypeInfo.Type()
+		if got != want.typ && !(isTextType(got) && isTextType(want.typ)) {
+			return fmt.Errorf("%w: %s: column %s is %s, want %s", ErrSchemaMismatch, table, col.Name, got, want.typ)
+		}
+	}
+	return nil
+}
+
+// 'text' and 'varchar' are the same CQL type, the driver may report any of them.
+func isTextType(t gocql.Type) bool {
+	return t == gocql.TypeText || t == gocql.TypeVarchar
+}
+
+// The zero time is null in the database (see 'nullTime').
+func cassandraTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+func nullString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func nullBool(v *bool) bool {
+	if v == nil {
+		return false
+	}
+	return *v
+}
+
+func nullInt(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func nullInt64(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func nullTime(v *time.Time) time.Time {
+	if v == nil {
+		return time.Time{}
+	}
+	return *v
+}
+
+/////////////
+//
+// ADD VOTER
+//
+/////////////
+
+// It tries to insert a new record into the 'voters' table (new 'user_id'). The record
+// is not inserted if this user has voted earlier, and 'applied' is false in that case.
+
+func (c *cassandraVoteStore) AddVoter(ctx context.Context, vote_id int, user_id string) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	m := make(map[string]interface{})
+	applied, err := c.write(ctx, session, c.stmt.addVoter, vote_id, user_id).MapScanCAS(m)
+	return applied, c.cassandraError(session, err)
+}
+
+////////////////////
+//
+// INCREMENT COUNTS
+//
+////////////////////
+
+// All the contenders of the vote are in the same partition ('vote_id'), so the counts are
+// updated by a single conditional batch (IF EXISTS): either all of them, or none.
+
+func (c *cassandraVoteStore) IncrementCounts(ctx context.Context, vote_id int, co_ids []int16) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	// The counter cannot be updated conditionally (IF EXISTS), so the timestamps go first, by
+	// the conditional batch: if a contender does not exist (e.g. the poll has been deleted in
+	// the meantime), nothing is counted and 'applied' is false, and the counters are never
+	// incremented twice. The counter batch is atomic, its updates are in one partition.
+	if c.counters {
+		applied, err := c.touchContenders(ctx, session, vote_id, co_ids...)
+		if err != nil || !applied {
+			return false, err
+		}
+
+		batch := session.NewBatch(gocql.CounterBatch).WithContext(ctx)
+		batch.SetConsistency(c.writeCL)
+		for _, co_id := range co_ids {
+			batch.Query(c.stmt.addCount, int64(1), vote_id, co_id)
+		}
+		err = session.ExecuteBatch(batch)
+		return err == nil, c.cassandraError(session, err)
+	}
+
+	batch := c.batch(ctx, session)
+	for _, co_id := range co_ids {
+		batch.Query(c.stmt.incrementCount, vote_id, co_id)
+	}
+
+	applied, iter, err := session.MapExecuteBatchCAS(batch, map[string]interface{}{})
+	if iter != nil {
+		iter.Close()
+	}
+	return applied, c.cassandraError(session, err)
+}
+
+// It updates the timestamps of the contenders by a conditional batch (IF EXISTS), before their
+// counters are updated; 'applied' is false if any of them does not exist.
+func (c *cassandraVoteStore) touchContenders(ctx context.Context, session *gocql.Session, vote_id int,
+	co_ids ...int16) (bool, error) {
+	if len(co_ids) == 0 {
+		return true, nil
+	}
+
+	batch := c.batch(ctx, session)
+	for _, co_id := range co_ids {
+		batch.Query(c.stmt.touchContender, vote_id, co_id)
+	}
+
+	applied, iter, err := session.MapExecuteBatchCAS(batch, map[string]interface{}{})
+	if iter != nil {
+		iter.Close()
+	}
+	return applied, c.cassandraError(session, err)
+}
+
+////////////////
+//
+// REMOVE VOTER
+//
+////////////////
+
+func (c *cassandraVoteStore) RemoveVoter(ctx context.Context, vote_id int, user_id string) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	return c.cassandraError(session, c.write(ctx, session, c.stmt.removeVoter, vote_id, user_id).Exec())
+}
+
+///////////////
+//
+// CREATE VOTE
+//
+///////////////
+
+// The vote data is repeated in each contender record (the table is not normalized). All the
+// records go to the same partition ('vote_id'), so they are inserted by a single batch, and
+// either all of them are inserted, or none. The batch is conditional (IF NOT EXISTS), and
+// a vote with other 'co_id' values is checked before.
+
+func (c *cassandraVoteStore) CreateVote(ctx context.Context, vote *VoteData) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.LoadVote(ctx, vote.VoteId); err == nil {
+		return ErrConflict
+	} else if err != ErrNotFound {
+		return err
+	}
+
+	batch := c.batch(ctx, session)
+	for i := range vote.Contenders {
+		co := &vote.Contenders[i]
+		batch.Query(c.stmt.insertContender, vote.VoteId, co.Id, vote.Header, vote.Message, vote.Resources,
+			vote.Deadline, vote.Authenticate, vote.AllowResults, co.Name, co.Alias, co.Info, co.Picture,
+			co.Count, co.Updated, co.Withdrawn, vote.State, cassandraTime(vote.OpensAt), vote.RequireCode,
+			vote.Method, vote.MaxChoices, vote.ScoreMin, vote.ScoreMax, vote.Revisable, vote.PublicKey, int64(1))
+	}
+
+	applied, iter, err := session.MapExecuteBatchCAS(batch, map[string]interface{}{})
+	if iter != nil {
+		iter.Close()
+	}
+	if err != nil {
+		return c.cassandraError(session, err)
+	}
+	if !applied {
+		return ErrConflict
+	}
+	return nil
+}
+
+///////////////
+//
+// UPDATE VOTE
+//
+///////////////
+
+// The vote data is updated in each contender record by a single batch (see 'CreateVote'). The
+// batch is conditional: each record must exist, so a deleted vote is not created again, and the
+// version of the vote must be the loaded one. Otherwise the vote has been changed after it was
+// loaded (e.g. a contender has been added), and the batch would write the stale data back.
+
+func (c *cassandraVoteStore) UpdateVote(ctx context.Context, vote *VoteData) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	stored, version, err := c.loadVote(ctx, session, vote.VoteId)
+	if err != nil {
+		return err
+	}
+
+	batch := c.batch(ctx, session)
+	batch.Query(c.stmt.guardVote, nullInt64(version)+1, vote.VoteId, version)
+	for _, co := range stored.Contenders {
+		batch.Query(c.stmt.updateVote, vote.Header, vote.Message, vote.Resources, vote.Deadline,
+			vote.Authenticate, vote.AllowResults, vote.State, cassandraTime(vote.OpensAt), vote.RequireCode,
+			vote.Method, vote.MaxChoices, vote.ScoreMin, vote.ScoreMax, vote.Revisable, vote.PublicKey, vote.VoteId, co.Id)
+	}
+	return c.executeGuarded(ctx, session, batch, vote.VoteId)
+}
+
+/////////////////
+//
+// ADD CONTENDER
+//
+/////////////////
+
+// The new record repeats the vote data of the stored records (see 'CreateVote'). It's inserted
+// by the same conditional batch as 'UpdateVote' uses: the version of the vote must be the loaded
+// one, and one of the stored records must exist (a vote created before the version column has
+// no version, so the version alone does not tell a deleted vote).
+
+func (c *cassandraVoteStore) AddContender(ctx context.Context, vote_id int, contender *Contender) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	vote, version, err := c.loadVote(ctx, session, vote_id)
+	if err != nil {
+		return err
+	}
+
+	co := contender
+	next := nullInt64(version) + 1
+	batch := c.batch(ctx, session)
+	batch.Query(c.stmt.guardVote, next, vote_id, version)
+	batch.Query(c.stmt.guardContender, next, vote_id, vote.Contenders[0].Id)
+	batch.Query(c.stmt.insertContender, vote_id, co.Id, vote.Header, vote.Message, vote.Resources,
+		vote.Deadline, vote.Authenticate, vote.AllowResults, co.Name, co.Alias, co.Info, co.Picture,
+		co.Count, co.Updated, co.Withdrawn, vote.State, cassandraTime(vote.OpensAt), vote.RequireCode,
+		vote.Method, vote.MaxChoices, vote.ScoreMin, vote.ScoreMax, vote.Revisable, vote.PublicKey, next)
+	return c.executeGuarded(ctx, session, batch, vote_id)
+}
+
+// It executes the conditional batch of 'UpdateVote' or 'AddContender'. If the batch is not
+// applied, the vote is loaded again: ErrNotFound means it has been deleted, otherwise it has
+// been changed (or the contender exists), and it's ErrConflict.
+func (c *cassandraVoteStore) executeGuarded(ctx context.Context, session *gocql.Session, batch *gocql.Batch, vote_id int) error {
+	applied, iter, err := session.MapExecuteBatchCAS(batch, map[string]interface{}{})
+	if iter != nil {
+		iter.Close()
+	}
+	if err != nil {
+		return c.cassandraError(session, err)
+	}
+	if applied {
+		return nil
+	}
+
+	if _, _, err = c.loadVote(ctx, session, vote_id); err != nil {
+		return err
+	}
+	return ErrConflict
+}
+
+////////////////////
+//
+// UPDATE CONTENDER
+//
+////////////////////
+
+// The condition (IF EXISTS) does not let the update create a record without vote data.
+
+func (c *cassandraVoteStore) UpdateContender(ctx context.Context, vote_id int, contender *Contender) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	co := contender
+	m := map[string]interface{}{}
+	applied, err := c.write(ctx, session, c.stmt.updateContender, co.Name, co.Alias, co.Info, co.Picture,
+		co.Withdrawn, vote_id, co.Id).MapScanCAS(m)
+	if err != nil {
+		return c.cassandraError(session, err)
+	}
+	if !applied {
+		return ErrNotFound
+	}
+	return nil
+}
+
+///////////////
+//
+// DELETE VOTE
+//
+///////////////
+
+// The counter tables ('polls.scores' and 'polls.vote_counts') cannot be updated by the same
+// batch (counter and non-counter updates cannot be mixed). Note that the deleted counters should
+// not be used again, i.e. do not reuse 'vote_id' of a deleted vote with the counter table or
+// for a score poll (Cassandra does not guarantee what happens with a counter updated after delete).
+
+func (c *cassandraVoteStore) DeleteVote(ctx context.Context, vote_id int) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	batch := c.batch(ctx, session)
+	batch.Query(c.stmt.deleteVote, vote_id)
+	batch.Query(c.stmt.deleteVoters, vote_id)
+	batch.Query(c.stmt.deleteRoll, vote_id)
+	batch.Query(c.stmt.deleteCodes, vote_id)
+	batch.Query(c.stmt.deleteBallots, vote_id)
+	batch.Query(c.stmt.deleteReceipts, vote_id)
+	batch.Query(c.stmt.deleteAudit, vote_id)
+	batch.Query(c.stmt.deleteCommitment, vote_id)
+	batch.Query(c.stmt.deleteEncryptedBallots, vote_id)
+	batch.Query(c.stmt.deleteTally, vote_id)
+	if err = session.ExecuteBatch(batch); err != nil {
+		return c.cassandraError(session, err)
+	}
+
+	if err = c.write(ctx, session, c.stmt.deleteScores, vote_id).Exec(); err != nil {
+		return c.cassandraError(session, err)
+	}
+
+	if c.counters {
+		return c.cassandraError(session, c.write(ctx, session, c.stmt.deleteCounts, vote_id).Exec())
+	}
+	return nil
+}
+
+////////////
+//
+// SET ROLL
+//
+////////////
+
+// The roll may be large, so it's inserted by unlogged batches (a batch of a single partition is
+// applied at once, and it's not logged anyway). The old roll is deleted before, and the new
+// records are written with a later timestamp, otherwise the tombstone could hide some of them.
+// Unlike SQL stores, if it fails, a part of the new roll can be saved; upload it again.
+
+const CASSANDRA_ROLL_BATCH_SIZE = 500
+
+func (c *cassandraVoteStore) SetRoll(ctx context.Context, vote_id int, entries []string) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.LoadVote(ctx, vote_id); err != nil {
+		return err
+	}
+
+	ts := time.Now().UnixMicro()
+	if err = c.write(ctx, session, c.stmt.deleteRoll, vote_id).WithTimestamp(ts).Exec(); err != nil {
+		return c.cassandraError(session, err)
+	}
+
+	for i := 0; i < len(entries); i += CASSANDRA_ROLL_BATCH_SIZE {
+		batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx).WithTimestamp(ts + 1)
+		batch.SetConsistency(c.writeCL)
+		for _, e := range entries[i:min(i+CASSANDRA_ROLL_BATCH_SIZE, len(entries))] {
+			batch.Query(c.stmt.insertRoll, vote_id, e)
+		}
+		if err = session.ExecuteBatch(batch); err != nil {
+			return c.cassandraError(session, err)
+		}
+	}
+	return nil
+}
+
+//////////////
+//
+// CHECK ROLL
+//
+//////////////
+
+// The entry is looked up first, it's the usual case for the polls with a roll. If it's not
+// there, the poll may have no roll at all.
+
+func (c *cassandraVoteStore) CheckRoll(ctx context.Context, vote_id int, entry string) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	var e string
+	err = c.read(ctx, session, c.stmt.checkRoll, vote_id, entry).Scan(&e)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, gocql.ErrNotFound) {
+		return false, c.cassandraError(session, err)
+	}
+
+	err = c.read(ctx, session, c.stmt.anyRoll, vote_id).Scan(&e)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return true, nil // No roll;
+	}
+	return false, c.cassandraError(session, err)
+}
+
+//////////////
+//
+// ROLL STATS
+//
+//////////////
+
+// Both the roll and the voters are single partitions, they are read and compared here.
+
+func (c *cassandraVoteStore) RollStats(ctx context.Context, vote_id int) (*RollStats, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	roll := map[string]bool{}
+	err = c.scanStrings(ctx, session, c.stmt.loadRoll, vote_id, func(e string) { roll[e] = true })
+	if err != nil || len(roll) == 0 {
+		return &RollStats{}, err
+	}
+
+	stats := RollStats{Size: int64(len(roll))}
+	err = c.scanStrings(ctx, session, c.stmt.loadVoters, vote_id, func(user_id string) {
+		if roll[rollEntry(user_id)] {
+			stats.Voted++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// It calls 'f' for the first column of each row.
+func (c *cassandraVoteStore) scanStrings(ctx context.Context, session *gocql.Session, stmt string, vote_id int, f func(string)) error {
+	scanner := c.read(ctx, session, stmt, vote_id).Iter().Scanner()
+	for scanner.Next() {
+		var v string
+		if err := scanner.Scan(&v); err != nil {
+			scanner.Err() // It closes the iterator;
+			return c.cassandraError(session, err)
+		}
+		f(v)
+	}
+	return c.cassandraError(session, scanner.Err())
+}
+
+/////////////
+//
+// ADD CODES
+//
+/////////////
+
+// Each code is inserted by a lightweight transaction (IF NOT EXISTS), the random codes are
+// not supposed to collide, but if they do, the used code must not become unused.
+
+func (c *cassandraVoteStore) AddCodes(ctx context.Context, vote_id int, codes []string) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.LoadVote(ctx, vote_id); err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		applied, err := c.write(ctx, session, c.stmt.insertCode, vote_id, code).MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			return c.cassandraError(session, err)
+		}
+		if !applied {
+			return ErrConflict
+		}
+	}
+	return nil
+}
+
+/////////////
+//
+// BURN CODE
+//
+/////////////
+
+// The condition is false for an unknown code as well ('revoked' is null there).
+
+func (c *cassandraVoteStore) BurnCode(ctx context.Context, vote_id int, code string) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	applied, err := c.write(ctx, session, c.stmt.burnCode, vote_id, code).MapScanCAS(map[string]interface{}{})
+	return applied, c.cassandraError(session, err)
+}
+
+////////////////
+//
+// RESTORE CODE
+//
+////////////////
+
+func (c *cassandraVoteStore) RestoreCode(ctx context.Context, vote_id int, code string) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.write(ctx, session, c.stmt.restoreCode, vote_id, code).MapScanCAS(map[string]interface{}{})
+	return c.cassandraError(session, err)
+}
+
+////////////////
+//
+// REVOKE CODES
+//
+////////////////
+
+// The codes are revoked one by one, by the same condition as 'BurnCode', so a code is either
+// used or revoked, never both.
+
+func (c *cassandraVoteStore) RevokeCodes(ctx context.Context, vote_id int, codes []string) (int64, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return 0, err
+	}
+
+	if codes == nil {
+		scanner := c.read(ctx, session, c.stmt.unusedCodes, vote_id).Iter().Scanner()
+		for scanner.Next() {
+			var code string
+			var used *time.Time
+			var revoked *bool
+			if err = scanner.Scan(&code, &used, &revoked); err != nil {
+				scanner.Err() // It closes the iterator;
+				return 0, c.cassandraError(session, err)
+			}
+			if used == nil && !nullBool(revoked) {
+				codes = append(codes, code)
+			}
+		}
+		if err = scanner.Err(); err != nil {
+			return 0, c.cassandraError(session, err)
+		}
+	}
+
+	var n int64
+	for _, code := range codes {
+		applied, err := c.write(ctx, session, c.stmt.revokeCode, vote_id, code).MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			return n, c.cassandraError(session, err)
+		}
+		if applied {
+			n++
+		}
+	}
+	return n, nil
+}
+
+//////////////
+//
+// ADD BALLOT
+//
+//////////////
+
+// The ballots are in the partition of the vote, they are read all at once (see 'LoadBallots').
+
+func (c *cassandraVoteStore) AddBallot(ctx context.Context, vote_id int, ballot_id string, ranking []int16) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	m := map[string]interface{}{}
+	applied, err := c.write(ctx, session, c.stmt.insertBallot, vote_id, ballot_id, ranking).MapScanCAS(m)
+	if err != nil {
+		return c.cassandraError(session, err)
+	}
+	if !applied {
+		return ErrConflict
+	}
+	return nil
+}
+
+/////////////////
+//
+// REMOVE BALLOT
+//
+/////////////////
+
+func (c *cassandraVoteStore) RemoveBallot(ctx context.Context, vote_id int, ballot_id string) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	return c.cassandraError(session, c.write(ctx, session, c.stmt.deleteBallot, vote_id, ballot_id).Exec())
+}
+
+////////////////
+//
+// LOAD BALLOTS
+//
+////////////////
+
+func (c *cassandraVoteStore) LoadBallots(ctx context.Context, vote_id int) ([][]int16, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	var res [][]int16
+	scanner := c.read(ctx, session, c.stmt.loadBallots, vote_id).Iter().Scanner()
+	for scanner.Next() {
+		var ranking []int16
+		if err = scanner.Scan(&ranking); err != nil {
+			scanner.Err() // It closes the iterator;
+			return nil, c.cassandraError(session, err)
+		}
+		res = append(res, ranking)
+	}
+	return res, c.cassandraError(session, scanner.Err())
+}
+
+//////////////
+//
+// ADD SCORES
+//
+//////////////
+
+// The ratings are counters of 'polls.scores' (one for each score of each contender), so the
+// sum and the number of the ratings are not saved, they are computed from the histogram (see
+// 'LoadScores'). The counts of the contenders are not changed. The counter cannot be updated
+// conditionally, so the contenders are checked by a conditional batch (IF EXISTS) which
+// touches them first: if it fails, nothing is counted. The counter batch is atomic, its
+// updates are in one partition.
+
+func (c *cassandraVoteStore) AddScores(ctx context.Context, vote_id int, scores map[int16]int) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	batch := c.batch(ctx, session)
+	for co_id := range scores {
+		batch.Query(c.stmt.rateContender, vote_id, co_id)
+	}
+
+	applied, iter, err := session.MapExecuteBatchCAS(batch, map[string]interface{}{})
+	if iter != nil {
+		iter.Close()
+	}
+	if err != nil || !applied {
+		return false, c.cassandraError(session, err)
+	}
+
+	batch = session.NewBatch(gocql.CounterBatch).WithContext(ctx)
+	batch.SetConsistency(c.writeCL)
+	for co_id, score := range scores {
+		batch.Query(c.stmt.addScore, vote_id, co_id, score)
+	}
+	err = session.ExecuteBatch(batch)
+	return err == nil, c.cassandraError(session, err)
+}
+
+///////////////
+//
+// LOAD SCORES
+//
+///////////////
+
+func (c *cassandraVoteStore) LoadScores(ctx context.Context, vote_id int) (map[int16]*ScoreTally, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[int16]*ScoreTally{}
+	scanner := c.read(ctx, session, c.stmt.loadScores, vote_id).Iter().Scanner()
+	for scanner.Next() {
+		var co_id int16
+		var score int
+		var n int64
+		if err = scanner.Scan(&co_id, &score, &n); err != nil {
+			scanner.Err() // It closes the iterator;
+			return nil, c.cassandraError(session, err)
+		}
+
+		t, ok := res[co_id]
+		if !ok {
+			t = &ScoreTally{Ratings: map[int]int64{}}
+			res[co_id] = t
+		}
+		t.Sum += int64(score) * n
+		t.Count += n
+		t.Ratings[score] = n
+	}
+	return res, c.cassandraError(session, scanner.Err())
+}
+
+///////////////
+//
+// LOAD CHOICE
+//
+///////////////
+
+func (c *cassandraVoteStore) LoadChoice(ctx context.Context, vote_id int, user_id string) (int16, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return 0, err
+	}
+
+	var co_id *int16
+	if err = c.read(ctx, session, c.stmt.loadChoice, vote_id, user_id).Scan(&co_id); err != nil {
+		if err == gocql.ErrNotFound {
+			return 0, ErrNotFound
+		}
+		return 0, c.cassandraError(session, err)
+	}
+	if co_id == nil {
+		return 0, nil
+	}
+	return *co_id, nil
+}
+
+/////////////////
+//
+// CHANGE CHOICE
+//
+/////////////////
+
+// All three are lightweight transactions, so the concurrent changes of the same voter
+// are applied one by one, and the late ones are not applied.
+
+func (c *cassandraVoteStore) ChangeChoice(ctx context.Context, vote_id int, user_id string, from, to int16) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	var q *gocql.Query
+	switch {
+	case from == 0:
+		q = c.write(ctx, session, c.stmt.insertChoice, vote_id, user_id, to)
+	case to == 0:
+		q = c.write(ctx, session, c.stmt.deleteChoice, vote_id, user_id, from)
+	default:
+		q = c.write(ctx, session, c.stmt.updateChoice, to, vote_id, user_id, from)
+	}
+
+	applied, err := q.MapScanCAS(map[string]interface{}{})
+	return applied, c.cassandraError(session, err)
+}
+
+//////////////
+//
+// MOVE COUNT
+//
+//////////////
+
+// Both contenders are in the same partition, so the counts are moved by a single batch, as
+// they are incremented (see 'IncrementCounts').
+
+func (c *cassandraVoteStore) MoveCount(ctx context.Context, vote_id int, from, to int16) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	if c.counters {
+		var co_ids []int16
+		for _, co_id := range []int16{from, to} {
+			if co_id != 0 {
+				co_ids = append(co_ids, co_id)
+			}
+		}
+		applied, err := c.touchContenders(ctx, session, vote_id, co_ids...)
+		if err != nil || !applied {
+			return false, err
+		}
+
+		batch := session.NewBatch(gocql.CounterBatch).WithContext(ctx)
+		batch.SetConsistency(c.writeCL)
+		if from != 0 {
+			batch.Query(c.stmt.addCount, int64(-1), vote_id, from)
+		}
+		if to != 0 {
+			batch.Query(c.stmt.addCount, int64(1), vote_id, to)
+		}
+		err = session.ExecuteBatch(batch)
+		return err == nil, c.cassandraError(session, err)
+	}
+
+	batch := c.batch(ctx, session)
+	if from != 0 {
+		batch.Query(c.stmt.decrementCount, vote_id, from)
+	}
+	if to != 0 {
+		batch.Query(c.stmt.incrementCount, vote_id, to)
+	}
+
+	applied, iter, err := session.MapExecuteBatchCAS(batch, map[string]interface{}{})
+	if iter != nil {
+		iter.Close()
+	}
+	return applied, c.cassandraError(session, err)
+}
+
+///////////////
+//
+// ADD RECEIPT
+//
+///////////////
+
+// The receipts are in the partition of the vote, as the ballots are. The static column 'sealed'
+// of the partition is set before the root is computed (see 'SealReceipts'), and the receipt is
+// inserted by a lightweight transaction checking it, so both are ordered by Paxos: the receipt
+// is either saved before the seal (and loaded by the commitment), or refused (ErrClosed).
+
+func (c *cassandraVoteStore) AddReceipt(ctx context.Context, vote_id int, receipt *Receipt) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	m := map[string]interface{}{}
+	applied, err := c.write(ctx, session, c.stmt.insertReceipt, receipt.CastAt, receipt.UserId, vote_id,
+		receipt.Id).MapScanCAS(m)
+	if err != nil {
+		return c.cassandraError(session, err)
+	}
+	if !applied {
+		if sealed, _ := m["sealed"].(bool); sealed {
+			return ErrClosed
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+//////////////////
+//
+// REMOVE RECEIPT
+//
+//////////////////
+
+func (c *cassandraVoteStore) RemoveReceipt(ctx context.Context, vote_id int, receipt string) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	return c.cassandraError(session, c.write(ctx, session, c.stmt.deleteReceipt, vote_id, receipt).Exec())
+}
+
+////////////////
+//
+// LOAD RECEIPT
+//
+////////////////
+
+func (c *cassandraVoteStore) LoadReceipt(ctx context.Context, vote_id int, receipt string) (*Receipt, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	res := Receipt{Id: receipt, VoteId: vote_id}
+	if err = c.read(ctx, session, c.stmt.loadReceipt, vote_id, receipt).Scan(&res.CastAt, &res.UserId); err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, c.cassandraError(session, err)
+	}
+	res.CastAt = res.CastAt.UTC()
+	return &res, nil
+}
+
+/////////////////
+//
+// LOAD RECEIPTS
+//
+/////////////////
+
+func (c *cassandraVoteStore) LoadReceipts(ctx context.Context, vote_id int) ([]*Receipt, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*Receipt
+	scanner := c.read(ctx, session, c.stmt.loadReceipts, vote_id).Iter().Scanner()
+	for scanner.Next() {
+		r := &Receipt{VoteId: vote_id}
+		if err = scanner.Scan(&r.Id, &r.CastAt); err != nil {
+			scanner.Err() // It closes the iterator;
+			return nil, c.cassandraError(session, err)
+		}
+		if r.Id == "" {
+			continue // The partition with the static column only (see 'SealReceipts');
+		}
+		r.CastAt = r.CastAt.UTC()
+		res = append(res, r)
+	}
+	return res, c.cassandraError(session, scanner.Err())
+}
+
+/////////////////
+//
+// SEAL RECEIPTS
+//
+/////////////////
+
+// It's a lightweight transaction, as the insert of the receipt (see 'AddReceipt'). The receipts
+// sealed already are not an error.
+
+func (c *cassandraVoteStore) SealReceipts(ctx context.Context, vote_id int) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	m := map[string]interface{}{}
+	_, err = c.write(ctx, session, c.stmt.sealReceipts, vote_id).MapScanCAS(m)
+	return c.cassandraError(session, err)
+}
+
+///////////////////
+//
+// SAVE COMMITMENT
+//
+///////////////////
+
+// The root is inserted by a lightweight transaction, it's never replaced (see 'commitment.go').
+
+func (c *cassandraVoteStore) SaveCommitment(ctx context.Context, vote_id int, commitment *Commitment) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	m := map[string]interface{}{}
+	applied, err := c.write(ctx, session, c.stmt.insertCommitment, vote_id, commitment.Root, commitment.Leaves,
+		commitment.CommittedAt).MapScanCAS(m)
+	return applied, c.cassandraError(session, err)
+}
+
+///////////////////
+//
+// LOAD COMMITMENT
+//
+///////////////////
+
+func (c *cassandraVoteStore) LoadCommitment(ctx context.Context, vote_id int) (*Commitment, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	res := Commitment{VoteId: vote_id}
+	err = c.read(ctx, session, c.stmt.loadCommitment, vote_id).Scan(&res.Root, &res.Leaves, &res.CommittedAt)
+	if err == gocql.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, c.cassandraError(session, err)
+	}
+	res.CommittedAt = res.CommittedAt.UTC()
+	return &res, nil
+}
+
+/////////////////////
+//
+// REMOVE COMMITMENT
+//
+/////////////////////
+
+func (c *cassandraVoteStore) RemoveCommitment(ctx context.Context, vote_id int) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	if err = c.write(ctx, session, c.stmt.deleteCommitment, vote_id).Exec(); err != nil {
+		return c.cassandraError(session, err)
+	}
+
+	m := map[string]interface{}{}
+	_, err = c.write(ctx, session, c.stmt.unsealReceipts, vote_id).MapScanCAS(m)
+	return c.cassandraError(session, err)
+}
+
+////////////////////////
+//
+// ADD ENCRYPTED BALLOT
+//
+////////////////////////
+
+// The ballot is saved as JSON text in the partition of the vote, as the ranked ballots are.
+
+func (c *cassandraVoteStore) AddEncryptedBallot(ctx context.Context, vote_id int, ballot_id string, ballot *EncryptedBallot) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(ballot)
+	if err != nil {
+		return err
+	}
+
+	m := map[string]interface{}{}
+	applied, err := c.write(ctx, session, c.stmt.insertEncryptedBallot, vote_id, ballot_id, string(data)).MapScanCAS(m)
+	if err != nil {
+		return c.cassandraError(session, err)
+	}
+	if !applied {
+		return ErrConflict
+	}
+	return nil
+}
+
+///////////////////////////
+//
+// REMOVE ENCRYPTED BALLOT
+//
+///////////////////////////
+
+func (c *cassandraVoteStore) RemoveEncryptedBallot(ctx context.Context, vote_id int, ballot_id string) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	return c.cassandraError(session, c.write(ctx, session, c.stmt.deleteEncryptedBallot, vote_id, ballot_id).Exec())
+}
+
+//////////////////////////
+//
+// LOAD ENCRYPTED BALLOTS
+//
+//////////////////////////
+
+func (c *cassandraVoteStore) LoadEncryptedBallots(ctx context.Context, vote_id int) ([]*EncryptedBallot, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*EncryptedBallot
+	scanner := c.read(ctx, session, c.stmt.loadEncryptedBallots, vote_id).Iter().Scanner()
+	for scanner.Next() {
+		var data string
+		var ballot EncryptedBallot
+		if err = scanner.Scan(&data); err == nil {
+			err = json.Unmarshal([]byte(data), &ballot)
+		}
+		if err != nil {
+			scanner.Err() // It closes the iterator;
+			return nil, c.cassandraError(session, err)
+		}
+		res = append(res, &ballot)
+	}
+	return res, c.cassandraError(session, scanner.Err())
+}
+
+//////////////
+//
+// SAVE TALLY
+//
+//////////////
+
+// The tally is inserted by a lightweight transaction, as the root of the receipts.
+
+func (c *cassandraVoteStore) SaveTally(ctx context.Context, vote_id int, tally *EncryptedTally) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	data, err := json.Marshal(tally)
+	if err != nil {
+		return false, err
+	}
+
+	m := map[string]interface{}{}
+	applied, err := c.write(ctx, session, c.stmt.insertTally, vote_id, string(data), tally.TalliedAt).MapScanCAS(m)
+	return applied, c.cassandraError(session, err)
+}
+
+//////////////
+//
+// LOAD TALLY
+//
+//////////////
+
+func (c *cassandraVoteStore) LoadTally(ctx context.Context, vote_id int) (*EncryptedTally, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	var data string
+	err = c.read(ctx, session, c.stmt.loadTally, vote_id).Scan(&data)
+	if err == gocql.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, c.cassandraError(session, err)
+	}
+
+	var res EncryptedTally
+	if err = json.Unmarshal([]byte(data), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+////////////////
+//
+// REMOVE TALLY
+//
+////////////////
+
+func (c *cassandraVoteStore) RemoveTally(ctx context.Context, vote_id int) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	return c.cassandraError(session, c.write(ctx, session, c.stmt.deleteTally, vote_id).Exec())
+}
+
+////////////////
+//
+// APPEND AUDIT
+//
+////////////////
+
+// The log is in the partition of the vote, ordered by 'seq'. The entry is inserted by a
+// lightweight transaction, so two entries cannot have the same 'seq'.
+
+func (c *cassandraVoteStore) AppendAudit(ctx context.Context, vote_id int, entry *AuditEntry) (bool, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return false, err
+	}
+
+	m := map[string]interface{}{}
+	applied, err := c.write(ctx, session, c.stmt.insertAudit, vote_id, entry.Seq, entry.At, entry.Action,
+		entry.Counts, entry.Prev, entry.Hash).MapScanCAS(m)
+	return applied, c.cassandraError(session, err)
+}
+
+///////////////////
+//
+// LOAD AUDIT HEAD
+//
+///////////////////
+
+func (c *cassandraVoteStore) LoadAuditHead(ctx context.Context, vote_id int) (*AuditEntry, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &AuditEntry{VoteId: vote_id}
+	err = c.read(ctx, session, c.stmt.loadAuditHead, vote_id).Scan(&e.Seq, &e.At, &e.Action, &e.Counts, &e.Prev, &e.Hash)
+	if err == gocql.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, c.cassandraError(session, err)
+	}
+	e.At = e.At.UTC()
+	return e, nil
+}
+
+//////////////
+//
+// LOAD AUDIT
+//
+//////////////
+
+func (c *cassandraVoteStore) LoadAudit(ctx context.Context, vote_id int) ([]*AuditEntry, error) {
+	session, err := c.getSession()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*AuditEntry
+	scanner := c.read(ctx, session, c.stmt.loadAudit, vote_id).Iter().Scanner()
+	for scanner.Next() {
+		e := &AuditEntry{VoteId: vote_id}
+		if err = scanner.Scan(&e.Seq, &e.At, &e.Action, &e.Counts, &e.Prev, &e.Hash); err != nil {
+			scanner.Err() // It closes the iterator;
+			return nil, c.cassandraError(session, err)
+		}
+		e.At = e.At.UTC()
+		res = append(res, e)
+	}
+	return res, c.cassandraError(session, scanner.Err())
+}
+
+////////
+//
+// PING
+//
+////////
+
+// Let's check if the database responds (the session itself may be open,
+// while all nodes are down).
+func (c *cassandraVoteStore) Ping(ctx context.Context) error {
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+
+	var version string
+	return c.cassandraError(session, session.Query(c.stmt.ping).WithContext(ctx).Scan(&version))
+}
+
+/////////
+//
+// CLOSE
+//
+/////////
+
+// It closes the session, the store cannot be used after this.
+func (c *cassandraVoteStore) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.session != nil {
+		c.session.Close()
+		c.session = nil
+		c.metrics.SessionUp.Set(0)
+	}
+	return nil
+}
+
+///////////////
+//
+// GET SESSION
+//
+///////////////
+
+// It returns the current session, or creates a new one if there is no session. Only one
+// goroutine at a time tries to connect, others wait for it (it's better than a crowd of
+// sessions doing the same handshake and topology discovery).
+func (c *cassandraVoteStore) getSession() (*gocql.Session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, ErrServiceUnavailable
+	}
+
+	if c.session != nil && !c.session.Closed() {
+		return c.session, nil
+	}
+
+	session, err := c.db.CreateSession()
+	if err != nil {
+		c.metrics.SessionUp.Set(0)
+		return nil, err
+	}
+
+	c.session = session
+	c.metrics.Sessions.Add(1)
+	c.metrics.SessionUp.Set(1)
+	return session, nil
+}
+
+///////////////////
+//
+// CASSANDRA ERROR
+//
+///////////////////
+
+// It converts the database errors of the 'session': if the table doesn't match the statements
+// (e.g. unknown column or table, or the value of a column cannot be unmarshalled into the Go
+// type), it's ErrSchemaMismatch. Other errors are returned as is.
+func (c *cassandraVoteStore) cassandraError(session *gocql.Session, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var reqErr gocql.RequestError
+	if errors.As(err, &reqErr) && reqErr.Code() == gocql.ErrCodeInvalid {
+		return fmt.Errorf("%w: %s", ErrSchemaMismatch, reqErr.Message())
+	}
+
+	var unmarshalErr gocql.UnmarshalError
+	if errors.As(err, &unmarshalErr) {
+		return fmt.Errorf("%w: %s", ErrSchemaMismatch, unmarshalErr)
+	}
+
+	return c.checkSession(session, err)
+}
+
+//////////////
+//
+// READ/WRITE
+//
+//////////////
+
+// They create a query with the consistency level for reading or writing the data.
+
+func (c *cassandraVoteStore) read(ctx context.Context, session *gocql.Session, stmt string, values ...interface{}) *gocql.Query {
+	return session.Query(stmt, values...).WithContext(ctx).Consistency(c.readCL)
+}
+
+func (c *cassandraVoteStore) write(ctx context.Context, session *gocql.Session, stmt string, values ...interface{}) *gocql.Query {
+	return session.Query(stmt, values...).WithContext(ctx).Consistency(c.writeCL)
+}
+
+// A logged batch: either all its statements are applied, or none of them.
+func (c *cassandraVoteStore) batch(ctx context.Context, session *gocql.Session) *gocql.Batch {
+	batch := session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.SetConsistency(c.writeCL)
+	return batch
+}
+
+/////////////////
+//
+// CHECK SESSION
+//
+/////////////////
+
+// If the error means that the 'failed' session is no longer usable, the session is dropped
+// and a new one is created by the next 'getSession'. It returns the same error. The session
+// may be replaced already (the error of a request which ran on the old one comes late), then
+// the current session is not touched.
+func (c *cassandraVoteStore) checkSession(failed *gocql.Session, err error) error {
+	if !(errors.Is(err, gocql.ErrNoConnections) || errors.Is(err, gocql.ErrSessionClosed)) {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.session != nil && c.session == failed {
+		c.session.Close()
+		c.session = nil
+		c.metrics.SessionUp.Set(0)
+	}
+	return err
+}
+
+/////////////////////////
+//
+// OBSERVE CONNECT/QUERY
+//
+/////////////////////////
+
+// These are gocql.ConnectObserver and gocql.QueryObserver, they update the metrics.
+
+func (c *cassandraVoteStore) ObserveConnect(o gocql.ObservedConnect) {
+	c.metrics.Connects.With("success", strconv.FormatBool(o.Err == nil)).Add(1)
+	c.observeHost(o.Host)
+}
+
+func (c *cassandraVoteStore) ObserveQuery(_ context.Context, o gocql.ObservedQuery) {
+	c.metrics.Queries.With("success", strconv.FormatBool(o.Err == nil)).Observe(o.End.Sub(o.Start).Seconds())
+	c.observeHost(o.Host)
+}
+
+// NodeStatus returns all the nodes seen by the driver, sorted by the data center and address.
+func (c *cassandraVoteStore) NodeStatus() []NodeStatus {
+	c.hostsMu.Lock()
+	defer c.hostsMu.Unlock()
+
+	nodes := make([]NodeStatus, 0, len(c.hosts))
+	for addr, h := range c.hosts {
+		nodes = append(nodes, NodeStatus{
+			Address:    addr,
+			DataCenter: h.DataCenter(),
+			Rack:       h.Rack(),
+			Up:         h.IsUp(),
+		})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].DataCenter != nodes[j].DataCenter {
+			return nodes[i].DataCenter < nodes[j].DataCenter
+		}
+		return nodes[i].Address < nodes[j].Address
+	})
+	return nodes
+}
+
+// It adds the node to the list of known nodes and updates the number of nodes up/down.
+func (c *cassandraVoteStore) observeHost(host *gocql.HostInfo) {
+	if host == nil {
+		return
+	}
+
+	c.hostsMu.Lock()
+	defer c.hostsMu.Unlock()
+
+	c.hosts[host.ConnectAddressAndPort()] = host
+
+	up := 0
+	for _, h := range c.hosts {
+		if h.IsUp() {
+			up++
+		}
+	}
+	c.metrics.HostsUp.Set(float64(up))
+	c.metrics.HostsDown.Set(float64(len(c.hosts) - up))
+}
+
+////////////////////////////
+//
+// NEW CASSANDRA VOTE STORE
+//
+////////////////////////////
+
+// NewCassandraVoteStore returns a VoteStore keeping the data in Apache Cassandra. It sets the
+// connect and query observers of 'db' to collect the metrics ('m' can be nil), and tries to
+// connect to the database. If it fails, it will try again when the data is needed, so the
+// service can start before the database, and the 'Ping' reports the database status.
+func NewCassandraVoteStore(db *gocql.ClusterConfig, m *CassandraMetrics, opts ...CassandraOption) VoteStore {
+	c := newCassandraVoteStore(db, m, opts)
+
+	db.ConnectObserver = c
+	db.QueryObserver = c
+
+	c.getSession()
+	return c
+}
+
+////////////////////////////
+//
+// MIGRATE CASSANDRA COUNTS
+//
+////////////////////////////
+
+// MigrateCassandraCounts copies the counts from the column 'co_count' of 'polls.votes' to the
+// counter table 'polls.vote_counts' (see 'WithCounterTable') and returns the number of the
+// contenders copied. A counter can only be incremented, so the difference is added, and it's
+// safe to run it again. But the service must be stopped, or the new votes may be lost.
+func MigrateCassandraCounts(ctx context.Context, db *gocql.ClusterConfig, opts ...CassandraOption) (int, error) {
+	session, err := db.CreateSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+
+	c := newCassandraVoteStore(db, nil, opts)
+	n := 0
+
+	scanner := c.read(ctx, session, c.stmt.copyCounts).Iter().Scanner()
+	for scanner.Next() {
+		var vote_id int
+		var co_id int16
+		var co_count *int64
+		if err = scanner.Scan(&vote_id, &co_id, &co_count); err != nil {
+			scanner.Err() // It closes the iterator;
+			return n, c.cassandraError(session, err)
+		}
+
+		var current int64
+		err = c.read(ctx, session, c.stmt.loadCount, vote_id, co_id).Scan(&current)
+		if err != nil && !errors.Is(err, gocql.ErrNotFound) {
+			scanner.Err()
+			return n, c.cassandraError(session, err)
+		}
+
+		if delta := nullInt64(co_count) - current; delta != 0 {
+			err = c.write(ctx, session, c.stmt.addCount, delta, vote_id, co_id).Exec()
+			if err != nil {
+				scanner.Err()
+				return n, c.cassandraError(session, err)
+			}
+		}
+		n++
+	}
+
+	return n, c.cassandraError(session, scanner.Err())
+}
+
+func newCassandraVoteStore(db *gocql.ClusterConfig, m *CassandraMetrics, opts []CassandraOption) *cassandraVoteStore {
+	c := &cassandraVoteStore{
+		db:       db,
+		metrics:  withDefaultMetrics(m),
+		keyspace: CASSANDRA_DEFAULT_KEYSPACE,
+		readCL:   db.Consistency,
+		writeCL:  db.Consistency,
+		hosts:    map[string]*gocql.HostInfo{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.stmt = newCassandraStatements(c.keyspace)
+	return c
+}
+
+// It returns a copy of 'm' where nil metrics are replaced with the ones doing nothing.
+func withDefaultMetrics(m *CassandraMetrics) *CassandraMetrics {
+	var res CassandraMetrics
+	if m != nil {
+		res = *m
+	}
+
+	if res.Sessions == nil {
+		res.Sessions = discard.NewCounter()
+	}
+	if res.SessionUp == nil {
+		res.SessionUp = discard.NewGauge()
+	}
+	if res.Connects == nil {
+		res.Connects = discard.NewCounter()
+	}
+	if res.HostsUp == nil {
+		res.HostsUp = discard.NewGauge()
+	}
+	if res.HostsDown == nil {
+		res.HostsDown = discard.NewGauge()
+	}
+	if res.Queries == nil {
+		res.Queries = discard.NewHistogram()
+	}
+	return &res
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,176 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Invitation codes are an alternative to the voters' accounts: the admin generates N codes
+// for the poll and hands them out (e.g. exported as CSV), each code can be used once. If
+// the poll has 'RequireCode', the voter sends the code with the vote (see 'WithVoteCode'),
+// the code is burned and the vote is recorded at once. The 'user_id' of such a voter is the
+// hash of the code, unless the poll requires authentication as well (see 'jwt.go'), so such
+// a poll cannot have a roll of the voters (see 'roll.go').
+
+// The store keeps the hashes of the codes only (see 'codeHash'), so the codes are seen by
+// the admin once, when they are generated. A code which is not used yet can be revoked.
+
+// The code is 80 random bits, 16 chars of base32 (A-Z, 2-7) in groups of 4: XXXX-XXXX-XXXX-XXXX.
+const CODE_BYTES = 10
+const CODE_GROUP_LEN = 4
+
+// The number of codes generated by a single request.
+const MAX_CODES_PER_REQUEST = 10000
+
+const ERR_MSG_CODE_REQUIRED = "invitation code is required"
+const ERR_MSG_INVALID_CODE = "invitation code is invalid, used or revoked"
+
+var (
+	ErrCodeRequired = fmt.Errorf("%w: %s", ErrUnauthorized, ERR_MSG_CODE_REQUIRED)
+	ErrInvalidCode  = fmt.Errorf("%w: %s", ErrForbidden, ERR_MSG_INVALID_CODE)
+)
+
+// CodeRevocation describes the codes to be revoked: the listed ones, or all unused codes.
+type CodeRevocation struct {
+	Codes []string `json:"codes"`
+	All   bool     `json:"all"`
+}
+
+//////////////////
+//
+// GENERATE CODES
+//
+//////////////////
+
+// It generates 'n' new codes and makes the poll require them (if it does not yet). The codes
+// are returned to the caller and never again, the store has the hashes only. It returns
+// ErrCodeRoll if the poll has a roll, and does not require authentication.
+
+func (b *basicVoteService) GenerateCodes(ctx context.Context, vote_id int, n int) ([]string, error) {
+	if !IsAdmin(ctx) {
+		return nil, ErrUnauthorized
+	}
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	if n <= 0 || n > MAX_CODES_PER_REQUEST {
+		return nil, fmt.Errorf("%w: count must be 1..%d", ErrBadRequest, MAX_CODES_PER_REQUEST)
+	}
+
+	vote, err := b.store.LoadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+	if vote.Revisable {
+		return nil, fmt.Errorf("%w: revisable poll cannot require codes", ErrConflict)
+	}
+	if !vote.Authenticate {
+		if err = b.checkNoRoll(ctx, vote_id); err != nil {
+			return nil, err
+		}
+	}
+
+	codes := make([]string, n)
+	hashes := make([]string, n)
+	for i := range codes {
+		if codes[i], err = newCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = codeHash(codes[i])
+	}
+
+	if err = b.store.AddCodes(ctx, vote_id, hashes); err != nil {
+		return nil, err
+	}
+
+	if !vote.RequireCode {
+		vote.RequireCode = true
+		if err = b.store.UpdateVote(ctx, vote); err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+////////////////
+//
+// REVOKE CODES
+//
+////////////////
+
+// It revokes the codes which are not used yet, and returns their number. The used codes
+// are not changed, the votes given with them are counted anyway.
+
+func (b *basicVoteService) RevokeCodes(ctx context.Context, vote_id int, r CodeRevocation) (int64, error) {
+	if !IsAdmin(ctx) {
+		return 0, ErrUnauthorized
+	}
+	if b.store == nil {
+		return 0, ErrServiceUnavailable
+	}
+
+	if r.All == (len(r.Codes) > 0) {
+		return 0, fmt.Errorf("%w: either codes or all is required", ErrBadRequest)
+	}
+
+	var hashes []string
+	for _, c := range r.Codes {
+		h := codeHash(c)
+		if h == "" {
+			return 0, ErrBadRequest
+		}
+		hashes = append(hashes, h)
+	}
+
+	if _, err := b.store.LoadVote(ctx, vote_id); err != nil {
+		return 0, err
+	}
+	return b.store.RevokeCodes(ctx, vote_id, hashes)
+}
+
+// It returns the hash of the code from the context, or ErrCodeRequired.
+func voteCode(ctx context.Context) (string, error) {
+	h := codeHash(VoteCode(ctx))
+	if h == "" {
+		return "", ErrCodeRequired
+	}
+	return h, nil
+}
+
+// It returns a new random code, see 'CODE_BYTES'.
+func newCode() (string, error) {
+	b := make([]byte, CODE_BYTES)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	s := base32.StdEncoding.EncodeToString(b) // No padding, 10 bytes are 16 chars;
+	var groups []string
+	for i := 0; i < len(s); i += CODE_GROUP_LEN {
+		groups = append(groups, s[i:i+CODE_GROUP_LEN])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// It returns the hash of the code as it's kept by the store, or an empty string if there is
+// no code. The dashes and spaces are ignored, and so is the case.
+func codeHash(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if code == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// --- END OF FILE ---
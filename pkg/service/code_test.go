@@ -0,0 +1,144 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var codeRegexp = regexp.MustCompile(`^[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}$`)
+
+func TestCodeHash(t *testing.T) {
+	testinfo := "test CodeHash"
+
+	code, err := newCode()
+	if err != nil || !codeRegexp.MatchString(code) {
+		t.Fatalf("test %v failed, code %q, error: %v", testinfo, code, err)
+	}
+
+	h := codeHash(code)
+	for i, c := range []string{strings.ToLower(code), strings.ReplaceAll(code, "-", ""), " " + code + " "} {
+		if codeHash(c) != h {
+			t.Errorf("test %v (case # %d) failed, %q has other hash than %q", testinfo, i+1, c, code)
+		}
+	}
+	if codeHash(" - ") != "" {
+		t.Errorf("test %v failed, no code must have no hash", testinfo)
+	}
+}
+
+func TestVoteCodes(t *testing.T) {
+	testVoteCodes(t, load_store(t))
+}
+
+// It generates the codes and votes with them, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testVoteCodes(t *testing.T, store VoteStore) {
+	testinfo := "test VoteCodes"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	if _, err := svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the codes are generated by the admin only, 1..MAX_CODES_PER_REQUEST;
+		if _, err := svc.GenerateCodes(public, TESTDATA_NEW_VOTE_ID, 3); err != ErrUnauthorized {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrUnauthorized)
+		}
+		if _, err := svc.GenerateCodes(ctx, TESTDATA_NEW_VOTE_ID, MAX_CODES_PER_REQUEST+1); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+		}
+
+		// Case 2: the poll requires the codes now;
+		codes, err := svc.GenerateCodes(ctx, TESTDATA_NEW_VOTE_ID, 3)
+		if err != nil || len(codes) != 3 || codes[0] == codes[1] {
+			t.Fatalf("test %v (case # 2) failed, codes %v, error: %v", testinfo, codes, err)
+		}
+		if res, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID); err != nil || !res.RequireCode {
+			t.Errorf("test %v (case # 2) failed, vote data %+v, error: %v", testinfo, res, err)
+		}
+
+		// Case 3: no code, or unknown code;
+		_, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID)
+		if err != ErrCodeRequired || !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrCodeRequired)
+		}
+		_, err = svc.UpdateVoteResults(WithVoteCode(public, "AAAA-AAAA-AAAA-AAAA"), TESTDATA_NEW_VOTE_ID, 1, "")
+		if err != ErrInvalidCode || !errors.Is(err, ErrForbidden) {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrInvalidCode)
+		}
+
+		// Case 4: the code is used once, the 'user_id' does not matter;
+		if _, err = svc.UpdateVoteResults(WithVoteCode(public, strings.ToLower(codes[0])), TESTDATA_NEW_VOTE_ID, 1, ""); err != nil {
+			t.Errorf("test %v (case # 4) failed, error: %v", testinfo, err)
+		}
+		_, err = svc.UpdateVoteResults(WithVoteCode(public, codes[0]), TESTDATA_NEW_VOTE_ID, 2, "other")
+		if err != ErrInvalidCode {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrInvalidCode)
+		}
+
+		// Case 5: the used code is not revoked, the unused one is;
+		n, err := svc.RevokeCodes(ctx, TESTDATA_NEW_VOTE_ID, CodeRevocation{Codes: codes[:2]})
+		if err != nil || n != 1 {
+			t.Errorf("test %v (case # 5) failed, %v codes revoked (must be 1), error: %v", testinfo, n, err)
+		}
+		_, err = svc.UpdateVoteResults(WithVoteCode(public, codes[1]), TESTDATA_NEW_VOTE_ID, 1, "")
+		if err != ErrInvalidCode {
+			t.Errorf("test %v (case # 5) failed, error: %v (must be %v)", testinfo, err, ErrInvalidCode)
+		}
+
+		// Case 6: either the codes or all of them;
+		if _, err = svc.RevokeCodes(ctx, TESTDATA_NEW_VOTE_ID, CodeRevocation{}); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("test %v (case # 6) failed, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+		}
+		if n, err = svc.RevokeCodes(ctx, TESTDATA_NEW_VOTE_ID, CodeRevocation{All: true}); err != nil || n != 1 {
+			t.Errorf("test %v (case # 6) failed, %v codes revoked (must be 1), error: %v", testinfo, n, err)
+		}
+
+		// Case 7: one vote is counted;
+		res, err := svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || res.Contenders[0].Count != 1 || res.Contenders[1].Count != 0 {
+			t.Errorf("test %v (case # 7) failed, results %+v, error: %v", testinfo, res, err)
+		}
+	})
+}
+
+func TestVoteCodeCompensate(t *testing.T) {
+	// If the vote is not recorded, the code must be unused again;
+	testinfo := "test VoteCodeCompensate"
+	store := load_store(t)
+	ctx := WithAdmin(context.Background())
+
+	if _, err := New(store, []Middleware{}).CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+
+	svc := New(brokenVoteStore{store}, []Middleware{})
+	codes, err := svc.GenerateCodes(ctx, TESTDATA_NEW_VOTE_ID, 1)
+	if err != nil {
+		t.Fatalf("test %v failed, GenerateCodes err %v", testinfo, err)
+	}
+
+	t.Run(testinfo, func(t *testing.T) {
+		if _, err := svc.UpdateVoteResults(WithVoteCode(ctx, codes[0]), TESTDATA_NEW_VOTE_ID, 1, ""); err == nil {
+			t.Errorf("test %v failed, error is nil", testinfo)
+			return
+		}
+
+		applied, err := store.BurnCode(ctx, TESTDATA_NEW_VOTE_ID, codeHash(codes[0]))
+		if err != nil || !applied {
+			t.Errorf("test %v failed, the code was not restored (err: %v)", testinfo, err)
+		}
+	})
+}
+
+// --- END OF FILE ---
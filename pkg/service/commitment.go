@@ -0,0 +1,292 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ballot commitments: when the poll is closed, the receipts of its votes (see 'receipt.go')
+// are the leaves of a Merkle tree, and the root of the tree is published (see 'GetCommitment').
+// The voter gets the proof that the receipt is in the tree (see 'GetReceiptProof'): the hashes
+// of the siblings on the path from the leaf to the root. Anybody can check the proof against
+// the published root (see 'VerifyReceiptProof'), and the proof tells nothing about the other
+// receipts, the hashes only. The choices are not in the tree at all.
+
+// The leaf is SHA-256 of 0x00 and the lines "vote_id", "receipt" and "cast_at" (RFC 3339),
+// the node is SHA-256 of 0x01 and both children (32 bytes each), so a leaf is never taken for
+// a node. The leaves are sorted by the receipt; the last node of the level without a pair goes
+// to the next level as it is.
+
+// The root is saved when the admin closes the poll, or when it's requested the first time
+// after the deadline. It's never replaced: the receipts of the closed poll cannot change, and
+// the proofs are checked against the saved root. If the poll is reopened, the root is removed.
+
+// A vote which has found the poll open may be still saving its receipt when the poll is closed.
+// So the receipts are sealed before they are loaded (see 'VoteStore.SealReceipts'): the receipt
+// being saved either gets into the tree, or it's refused, and the vote fails with ErrClosed.
+
+// The receipts of a revisable poll are not committed (ErrRevisableCommitment): each change of
+// the vote gets a new receipt, and a retracted vote keeps its receipt (see 'revise.go'), so
+// they are not the final set of the votes. Such a poll stays revisable once there are votes
+// (see 'UpdateVote'), so the old receipts never get into a commitment.
+
+const ERR_MSG_NOT_CLOSED = "poll is not closed yet"
+const ERR_MSG_COMMITMENT_MISMATCH = "receipts do not match the commitment"
+const ERR_MSG_REVISABLE_COMMITMENT = "receipts of a revisable poll are not committed"
+
+var (
+	ErrNotClosed           = fmt.Errorf("%w: %s", ErrForbidden, ERR_MSG_NOT_CLOSED)
+	ErrCommitmentMismatch  = fmt.Errorf("%w: %s", ErrConflict, ERR_MSG_COMMITMENT_MISMATCH)
+	ErrRevisableCommitment = fmt.Errorf("%w: %s", ErrConflict, ERR_MSG_REVISABLE_COMMITMENT)
+)
+
+// Commitment is the root of the Merkle tree of the receipts of the closed poll.
+type Commitment struct {
+	VoteId      int       `json:"vote_id"`
+	Root        string    `json:"root"`   // SHA-256, hex; the hash of nothing if there are no receipts;
+	Leaves      int64     `json:"leaves"` // The number of the receipts;
+	CommittedAt time.Time `json:"committed_at"`
+}
+
+// ReceiptProof is the proof that the receipt is in the tree with the 'Root'.
+type ReceiptProof struct {
+	VoteId  int         `json:"vote_id"`
+	Receipt string      `json:"receipt"`
+	CastAt  time.Time   `json:"cast_at"`
+	Leaf    string      `json:"leaf"` // The hash of the receipt;
+	Index   int64       `json:"index"`
+	Leaves  int64       `json:"leaves"`
+	Path    []ProofStep `json:"path"` // From the leaf to the root;
+	Root    string      `json:"root"`
+}
+
+// ProofStep is the sibling of the node on the path, 'Left' if it goes first.
+type ProofStep struct {
+	Hash string `json:"hash"`
+	Left bool   `json:"left,omitempty"`
+}
+
+// It's the leaf of the receipt, see above.
+func receiptLeaf(vote_id int, id string, cast_at time.Time) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(vote_id) + "\n" + id + "\n" + cast_at.UTC().Format(time.RFC3339Nano)))
+	return h.Sum(nil)
+}
+
+func merkleNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// It returns the root of the tree and the path of the leaf # 'index' (nil if it's -1).
+func merkleTree(leaves [][]byte, index int) ([]byte, []ProofStep) {
+	if len(leaves) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:], nil
+	}
+
+	var path []ProofStep
+	level := leaves
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i]) // No pair;
+				continue
+			}
+			if index == i {
+				path = append(path, ProofStep{Hash: hex.EncodeToString(level[i+1])})
+			} else if index == i+1 {
+				path = append(path, ProofStep{Hash: hex.EncodeToString(level[i]), Left: true})
+			}
+			next = append(next, merkleNode(level[i], level[i+1]))
+		}
+		if index >= 0 {
+			index /= 2
+		}
+		level = next
+	}
+	return level[0], path
+}
+
+// It loads the receipts of the poll sorted by the id, and returns their leaves.
+func (b *basicVoteService) receiptLeaves(ctx context.Context, vote_id int) ([]*Receipt, [][]byte, error) {
+	receipts, err := b.store.LoadReceipts(ctx, vote_id)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(receipts, func(i, j int) bool { return receipts[i].Id < receipts[j].Id })
+
+	leaves := make([][]byte, len(receipts))
+	for i, r := range receipts {
+		leaves[i] = receiptLeaf(vote_id, r.Id, r.CastAt)
+	}
+	return receipts, leaves, nil
+}
+
+// It returns the saved commitment of the closed poll, or computes and saves it.
+func (b *basicVoteService) commit(ctx context.Context, vote_id int) (*Commitment, error) {
+	c, err := b.store.LoadCommitment(ctx, vote_id)
+	if err != ErrNotFound {
+		return c, err
+	}
+
+	if err = b.store.SealReceipts(ctx, vote_id); err != nil {
+		return nil, err
+	}
+	_, leaves, err := b.receiptLeaves(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+	root, _ := merkleTree(leaves, -1)
+	c = &Commitment{
+		VoteId:      vote_id,
+		Root:        hex.EncodeToString(root),
+		Leaves:      int64(len(leaves)),
+		CommittedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	applied, err := b.store.SaveCommitment(ctx, vote_id, c)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return b.store.LoadCommitment(ctx, vote_id) // It's saved by another request in the meantime;
+	}
+	return c, nil
+}
+
+// It loads the vote, it must be closed (or archived).
+func (b *basicVoteService) loadClosedVote(ctx context.Context, vote_id int) (*VoteData, error) {
+	vote, err := b.loadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch vote.State {
+	case VOTE_STATE_CLOSED, VOTE_STATE_ARCHIVED:
+		return vote, nil
+	}
+	return nil, ErrNotClosed
+}
+
+// It loads the vote, it must be closed and not revisable (see above).
+func (b *basicVoteService) loadCommittedVote(ctx context.Context, vote_id int) (*VoteData, error) {
+	vote, err := b.loadClosedVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+	if vote.Revisable {
+		return nil, ErrRevisableCommitment
+	}
+	return vote, nil
+}
+
+//////////////////
+//
+// GET COMMITMENT
+//
+//////////////////
+
+// It returns the root of the Merkle tree of the receipts, or ErrNotClosed if the poll is
+// not closed yet, or ErrRevisableCommitment if it's revisable.
+
+func (b *basicVoteService) GetCommitment(ctx context.Context, vote_id int) (*Commitment, error) {
+	if _, err := b.loadCommittedVote(ctx, vote_id); err != nil {
+		return nil, err
+	}
+	return b.commit(ctx, vote_id)
+}
+
+/////////////////////
+//
+// GET RECEIPT PROOF
+//
+/////////////////////
+
+// It returns the proof that the receipt is in the tree of the closed poll. The tree is built
+// again from the receipts, and ErrCommitmentMismatch means that the receipts have been changed
+// after the root was saved. As 'GetReceipt', it needs no authentication, the receipt is the secret.
+
+func (b *basicVoteService) GetReceiptProof(ctx context.Context, vote_id int, receipt string) (*ReceiptProof, error) {
+	if _, err := uuid.Parse(receipt); err != nil {
+		return nil, ErrBadRequest
+	}
+
+	if _, err := b.loadCommittedVote(ctx, vote_id); err != nil {
+		return nil, err
+	}
+
+	c, err := b.commit(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, leaves, err := b.receiptLeaves(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+	index := sort.Search(len(receipts), func(i int) bool { return receipts[i].Id >= receipt })
+	if index == len(receipts) || receipts[index].Id != receipt {
+		return nil, ErrNotFound
+	}
+
+	root, path := merkleTree(leaves, index)
+	if hex.EncodeToString(root) != c.Root || int64(len(leaves)) != c.Leaves {
+		return nil, ErrCommitmentMismatch
+	}
+
+	return &ReceiptProof{
+		VoteId:  vote_id,
+		Receipt: receipt,
+		CastAt:  receipts[index].CastAt,
+		Leaf:    hex.EncodeToString(leaves[index]),
+		Index:   int64(index),
+		Leaves:  c.Leaves,
+		Path:    path,
+		Root:    c.Root,
+	}, nil
+}
+
+// VerifyReceiptProof checks the proof: the leaf is the hash of the receipt, and the path
+// leads from the leaf to the root. It needs nothing else, the root must be compared with the
+// published one (see 'GetCommitment') by the caller.
+func VerifyReceiptProof(p *ReceiptProof) bool {
+	leaf := receiptLeaf(p.VoteId, p.Receipt, p.CastAt)
+	if hex.EncodeToString(leaf) != p.Leaf {
+		return false
+	}
+
+	node := leaf
+	for _, step := range p.Path {
+		sibling, err := hex.DecodeString(step.Hash)
+		if err != nil || len(sibling) != sha256.Size {
+			return false
+		}
+		if step.Left {
+			node = merkleNode(sibling, node)
+		} else {
+			node = merkleNode(node, sibling)
+		}
+	}
+
+	root, err := hex.DecodeString(p.Root)
+	return err == nil && bytes.Equal(node, root)
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,241 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMerkleTree(t *testing.T) {
+	testinfo := "test MerkleTree"
+	cast_at := time.Date(2026, time.October, 18, 9, 30, 0, 0, time.UTC)
+
+	for n := 1; n <= 9; n++ {
+		ids := make([]string, n)
+		leaves := make([][]byte, n)
+		for i := range ids {
+			ids[i] = uuid.NewString()
+			leaves[i] = receiptLeaf(1, ids[i], cast_at)
+		}
+		root, path := merkleTree(leaves, -1)
+		if path != nil {
+			t.Errorf("test %v (case # %d) failed, the path without a leaf", testinfo, n)
+		}
+
+		for i := range ids {
+			r, path := merkleTree(leaves, i)
+			p := &ReceiptProof{VoteId: 1, Receipt: ids[i], CastAt: cast_at, Leaf: hex.EncodeToString(leaves[i]),
+				Path: path, Root: hex.EncodeToString(root)}
+			if hex.EncodeToString(r) != p.Root || !VerifyReceiptProof(p) {
+				t.Errorf("test %v (case # %d) failed, the proof of the leaf # %d", testinfo, n, i)
+			}
+
+			p.VoteId = 2 // The receipt of another poll;
+			if VerifyReceiptProof(p) {
+				t.Errorf("test %v (case # %d) failed, the proof of the leaf # %d of another poll", testinfo, n, i)
+			}
+			p.VoteId = 1
+			if len(p.Path) > 0 {
+				p.Path[0].Left = !p.Path[0].Left
+				if VerifyReceiptProof(p) {
+					t.Errorf("test %v (case # %d) failed, the wrong proof of the leaf # %d", testinfo, n, i)
+				}
+			}
+		}
+	}
+}
+
+// The poll is closed while the vote is saving its receipt: right before the receipt is saved
+// ('before'), or right after it ('after'). Each func is called once.
+type closingReceiptStore struct {
+	VoteStore
+	before *func()
+	after  *func()
+}
+
+func (s closingReceiptStore) AddReceipt(ctx context.Context, vote_id int, receipt *Receipt) error {
+	before, after := *s.before, *s.after
+	*s.before, *s.after = nil, nil
+
+	if before != nil {
+		before()
+	}
+	err := s.VoteStore.AddReceipt(ctx, vote_id, receipt)
+	if after != nil {
+		after()
+	}
+	return err
+}
+
+func TestCommitmentVote(t *testing.T) {
+	testCommitmentVote(t, load_store(t))
+}
+
+// It checks the commitments of the receipts, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testCommitmentVote(t *testing.T, store VoteStore) {
+	testinfo := "test CommitmentVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	if _, err := svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	var receipts []string
+	vote := func(user_id string) {
+		r, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, user_id)
+		if err != nil {
+			t.Fatalf("test %v failed, UpdateVoteResults err %v", testinfo, err)
+		}
+		receipts = append(receipts, r.Id)
+	}
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the poll is open, there is no commitment;
+		for i := 0; i < 5; i++ {
+			vote(TESTDATA_USER_ID + string(rune('a'+i)))
+		}
+		if _, err := svc.GetCommitment(public, TESTDATA_NEW_VOTE_ID); err != ErrNotClosed {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrNotClosed)
+		}
+		if _, err := svc.GetReceiptProof(public, TESTDATA_NEW_VOTE_ID, receipts[0]); err != ErrNotClosed {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrNotClosed)
+		}
+
+		// Case 2: the root is saved at close, each receipt has the proof;
+		if _, err := svc.CloseVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Fatalf("test %v (case # 2) failed, CloseVote err %v", testinfo, err)
+		}
+		c, err := svc.GetCommitment(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || c.Leaves != 5 || len(c.Root) != 64 || c.CommittedAt.IsZero() {
+			t.Fatalf("test %v (case # 2) failed, commitment %+v, error: %v", testinfo, c, err)
+		}
+		for _, id := range receipts {
+			p, err := svc.GetReceiptProof(public, TESTDATA_NEW_VOTE_ID, id)
+			if err != nil || p.Root != c.Root || p.Leaves != 5 || !VerifyReceiptProof(p) {
+				t.Errorf("test %v (case # 2) failed, proof %+v, error: %v", testinfo, p, err)
+			}
+		}
+
+		// Case 3: the unknown receipts;
+		if _, err = svc.GetReceiptProof(public, TESTDATA_NEW_VOTE_ID, uuid.NewString()); err != ErrNotFound {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+		if _, err = svc.GetReceiptProof(public, TESTDATA_NEW_VOTE_ID, "root"); err != ErrBadRequest {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+		}
+
+		// Case 4: the receipt cannot be added after the close, and the removed one does not match the root;
+		if err = store.AddReceipt(public, TESTDATA_NEW_VOTE_ID, &Receipt{Id: uuid.NewString(), CastAt: time.Now().UTC()}); err != ErrClosed {
+			t.Fatalf("test %v (case # 4) failed, AddReceipt err %v (must be %v)", testinfo, err, ErrClosed)
+		}
+		if err = store.RemoveReceipt(public, TESTDATA_NEW_VOTE_ID, receipts[4]); err != nil {
+			t.Fatalf("test %v (case # 4) failed, RemoveReceipt err %v", testinfo, err)
+		}
+		if _, err = svc.GetReceiptProof(public, TESTDATA_NEW_VOTE_ID, receipts[0]); err != ErrCommitmentMismatch {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrCommitmentMismatch)
+		}
+		if res, err := svc.GetCommitment(public, TESTDATA_NEW_VOTE_ID); err != nil || *res != *c {
+			t.Errorf("test %v (case # 4) failed, commitment %+v (must be %+v), error: %v", testinfo, res, c, err)
+		}
+
+		// Case 5: the reopened poll has no commitment, the new one is saved at the next close;
+		if _, err = svc.ReopenVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Fatalf("test %v (case # 5) failed, ReopenVote err %v", testinfo, err)
+		}
+		if _, err = store.LoadCommitment(public, TESTDATA_NEW_VOTE_ID); err != ErrNotFound {
+			t.Errorf("test %v (case # 5) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+		vote(TESTDATA_USER_ID + "-z")
+		if _, err = svc.CloseVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Fatalf("test %v (case # 5) failed, CloseVote err %v", testinfo, err)
+		}
+		if c, err = svc.GetCommitment(public, TESTDATA_NEW_VOTE_ID); err != nil || c.Leaves != 5 {
+			t.Fatalf("test %v (case # 5) failed, commitment %+v, error: %v", testinfo, c, err)
+		}
+		p, err := svc.GetReceiptProof(public, TESTDATA_NEW_VOTE_ID, receipts[5])
+		if err != nil || p.Root != c.Root || !VerifyReceiptProof(p) {
+			t.Errorf("test %v (case # 5) failed, proof %+v, error: %v", testinfo, p, err)
+		}
+	})
+}
+
+func TestCommitmentRace(t *testing.T) {
+	testCommitmentRace(t, load_store(t))
+}
+
+// It closes the poll while the votes are being saved, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testCommitmentRace(t *testing.T, store VoteStore) {
+	testinfo := "test CommitmentRace"
+
+	var before, after func()
+	svc := New(closingReceiptStore{VoteStore: store, before: &before, after: &after}, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	if _, err := svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	close := func() {
+		if _, err := svc.CloseVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Errorf("test %v failed, CloseVote err %v", testinfo, err)
+		}
+	}
+
+	// Each receipt has the proof against the root, and the root has 'leaves' receipts;
+	check := func(n int, leaves int64, receipts ...string) {
+		c, err := svc.GetCommitment(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || c.Leaves != leaves {
+			t.Fatalf("test %v (case # %d) failed, commitment %+v (must have %d leaves), error: %v", testinfo, n, c, leaves, err)
+		}
+		for _, id := range receipts {
+			p, err := svc.GetReceiptProof(public, TESTDATA_NEW_VOTE_ID, id)
+			if err != nil || p.Root != c.Root || !VerifyReceiptProof(p) {
+				t.Errorf("test %v (case # %d) failed, proof %+v, error: %v", testinfo, n, p, err)
+			}
+		}
+	}
+
+	t.Run(testinfo, func(t *testing.T) {
+		first, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-a")
+		if err != nil {
+			t.Fatalf("test %v failed, UpdateVoteResults err %v", testinfo, err)
+		}
+
+		// Case 1: the receipt is saved before the close, it's in the root;
+		after = close
+		second, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-b")
+		if err != nil {
+			t.Fatalf("test %v (case # 1) failed, UpdateVoteResults err %v", testinfo, err)
+		}
+		check(1, 2, first.Id, second.Id)
+
+		// Case 2: the vote has found the poll open, but it's closed before the receipt is saved;
+		if _, err = svc.ReopenVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Fatalf("test %v (case # 2) failed, ReopenVote err %v", testinfo, err)
+		}
+		before = close
+		if _, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-c"); err != ErrClosed {
+			t.Errorf("test %v (case # 2) failed, UpdateVoteResults err %v (must be %v)", testinfo, err, ErrClosed)
+		}
+		check(2, 2, first.Id, second.Id)
+
+		// The vote is not counted either;
+		if res, err := svc.GetVoteResults(ctx, TESTDATA_NEW_VOTE_ID); err != nil || res.Contenders[0].Count != 2 {
+			t.Errorf("test %v (case # 2) failed, results %+v, error: %v", testinfo, res, err)
+		}
+	})
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,149 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"sort"
+)
+
+// Condorcet polls: the ballots are the same as in the ranked polls (see 'ranked.go'), but
+// they are counted by pairs of contenders. The pairwise matrix has the number of the ballots
+// preferring one contender to another: a ranked contender is preferred to the ones ranked
+// after it and to the unranked ones, the unranked contenders are not preferred to each other.
+// The Condorcet winner beats every other contender, but there may be none (a cycle), so the
+// winners are found by the Schulze method:
+
+// 1. The strength of a path of contenders is its weakest link (a link is a pairwise win).
+// 2. The strength of the strongest path from each contender to each other one is computed.
+// 3. A contender is a winner if its strongest paths to all the others are at least as strong
+// as their strongest paths back.
+
+// There is always at least one Schulze winner, and the Condorcet winner (if any) is the only
+// one. The withdrawn contenders are not counted, the ballots skip them.
+
+// CondorcetResult is the pairwise tally of the ranked ballots, see 'GetVoteResults'. The rows
+// and the columns of the matrices are in the order of 'Contenders'.
+type CondorcetResult struct {
+	Ballots    int64     `json:"ballots"` // Number of the ballots;
+	Contenders []int16   `json:"contenders"`
+	Pairwise   [][]int64 `json:"pairwise"`         // [i][j] is the number of the ballots preferring i to j;
+	Paths      [][]int64 `json:"paths"`            // [i][j] is the strength of the strongest path from i to j;
+	Condorcet  int16     `json:"condorcet_winner"` // The contender id, or 0 if there is no Condorcet winner;
+	Winners    []int16   `json:"winners"`          // The Schulze winners, more than one if they are tied;
+	Winner     int16     `json:"winner"`           // The only Schulze winner, or 0 if they are tied (or no ballots);
+}
+
+// It loads the ballots of the Condorcet poll and runs the tally.
+func (b *basicVoteService) condorcet(ctx context.Context, vote *VoteData) (*CondorcetResult, error) {
+	ballots, err := b.store.LoadBallots(ctx, vote.VoteId)
+	if err != nil {
+		return nil, err
+	}
+	return condorcet(vote.Contenders, ballots), nil
+}
+
+///////////
+//
+// SCHULZE
+//
+///////////
+
+// It fills the pairwise matrix and finds the winners, see above. The ballots are not changed.
+func condorcet(contenders []Contender, ballots [][]int16) *CondorcetResult {
+	res := &CondorcetResult{Ballots: int64(len(ballots))}
+
+	index := map[int16]int{}
+	for _, c := range contenders {
+		if !c.Withdrawn {
+			res.Contenders = append(res.Contenders, c.Id)
+		}
+	}
+	sort.Slice(res.Contenders, func(i, j int) bool { return res.Contenders[i] < res.Contenders[j] })
+	for i, id := range res.Contenders {
+		index[id] = i
+	}
+
+	n := len(res.Contenders)
+	res.Pairwise = newMatrix(n)
+	res.Paths = newMatrix(n)
+
+	for _, ballot := range ballots {
+		ranked := make([]bool, n)
+		for _, co_id := range ballot {
+			i, ok := index[co_id]
+			if !ok || ranked[i] {
+				continue
+			}
+			ranked[i] = true
+
+			// It's preferred to everybody not ranked yet;
+			for j := 0; j < n; j++ {
+				if !ranked[j] {
+					res.Pairwise[i][j]++
+				}
+			}
+		}
+	}
+
+	// The strongest paths (Floyd–Warshall, the widest path variant);
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && res.Pairwise[i][j] > res.Pairwise[j][i] {
+				res.Paths[i][j] = res.Pairwise[i][j]
+			}
+		}
+	}
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				if j == i || j == k {
+					continue
+				}
+				if p := min(res.Paths[i][k], res.Paths[k][j]); p > res.Paths[i][j] {
+					res.Paths[i][j] = p
+				}
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		condorcet, schulze := true, true
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+			if res.Pairwise[i][j] <= res.Pairwise[j][i] {
+				condorcet = false
+			}
+			if res.Paths[i][j] < res.Paths[j][i] {
+				schulze = false
+			}
+		}
+		if condorcet && n > 1 {
+			res.Condorcet = res.Contenders[i]
+		}
+		if schulze {
+			res.Winners = append(res.Winners, res.Contenders[i])
+		}
+	}
+
+	if len(res.Winners) == 1 && len(ballots) > 0 {
+		res.Winner = res.Winners[0]
+	}
+	return res
+}
+
+func newMatrix(n int) [][]int64 {
+	m := make([][]int64, n)
+	for i := range m {
+		m[i] = make([]int64, n)
+	}
+	return m
+}
+
+// --- END OF FILE ---
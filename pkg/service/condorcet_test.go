@@ -0,0 +1,129 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// It repeats the ranking 'n' times.
+func repeat_ballot(n int, ranking ...int16) [][]int16 {
+	res := make([][]int16, n)
+	for i := range res {
+		res[i] = ranking
+	}
+	return res
+}
+
+func TestSchulze(t *testing.T) {
+	testinfo := "test Schulze"
+
+	three := []Contender{{Id: 1}, {Id: 2}, {Id: 3}}
+	five := []Contender{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}, {Id: 5}}
+	withdrawn := []Contender{{Id: 1}, {Id: 2}, {Id: 3, Withdrawn: true}}
+
+	// The example of the Schulze method in Wikipedia (A..E are 1..5), the winner is E;
+	var wiki [][]int16
+	for _, b := range []struct {
+		n       int
+		ranking []int16
+	}{
+		{5, []int16{1, 3, 2, 5, 4}}, {5, []int16{1, 4, 5, 3, 2}}, {8, []int16{2, 5, 4, 1, 3}}, {3, []int16{3, 1, 2, 5, 4}},
+		{7, []int16{3, 1, 5, 2, 4}}, {2, []int16{3, 2, 1, 4, 5}}, {7, []int16{4, 3, 5, 2, 1}}, {8, []int16{5, 2, 1, 4, 3}},
+	} {
+		wiki = append(wiki, repeat_ballot(b.n, b.ranking...)...)
+	}
+
+	// A cycle: 1 beats 2, 2 beats 3, 3 beats 1, but 1 has the strongest win;
+	var cycle [][]int16
+	cycle = append(cycle, repeat_ballot(4, 1, 2, 3)...)
+	cycle = append(cycle, repeat_ballot(3, 2, 3, 1)...)
+	cycle = append(cycle, repeat_ballot(2, 3, 1, 2)...)
+
+	var cases = []struct {
+		contenders []Contender
+		ballots    [][]int16
+		condorcet  int16
+		winners    []int16
+		winner     int16
+	}{
+		{five, wiki, 0, []int16{5}, 5},
+		{three, cycle, 0, []int16{1}, 1},
+		// The Condorcet winner, the unranked contenders are preferred by nobody;
+		{three, [][]int16{{1}, {2, 1}, {1, 3}}, 1, []int16{1}, 1},
+		// A tie;
+		{three, [][]int16{{1, 2}, {2, 1}}, 0, []int16{1, 2}, 0},
+		// The withdrawn contender is skipped;
+		{withdrawn, [][]int16{{3, 2, 1}, {3, 1}, {2}}, 2, []int16{2}, 2},
+		// No ballots, no winner;
+		{three, nil, 0, []int16{1, 2, 3}, 0},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			res := condorcet(c.contenders, c.ballots)
+			if res.Condorcet != c.condorcet || res.Winner != c.winner || !reflect.DeepEqual(res.Winners, c.winners) {
+				t.Errorf("test %v (case # %d) failed, Condorcet winner %d, winners %v, winner %d (must be %d, %v, %d)",
+					testinfo, i+1, res.Condorcet, res.Winners, res.Winner, c.condorcet, c.winners, c.winner)
+			}
+			if len(res.Pairwise) != len(res.Contenders) || res.Ballots != int64(len(c.ballots)) {
+				t.Errorf("test %v (case # %d) failed, %d x %d matrix, %d ballots", testinfo, i+1,
+					len(res.Pairwise), len(res.Contenders), res.Ballots)
+			}
+		})
+	}
+
+	// The pairwise matrix of the example, A:B is 20:25;
+	res := condorcet(five, wiki)
+	if res.Pairwise[0][1] != 20 || res.Pairwise[1][0] != 25 || res.Paths[4][0] != 25 {
+		t.Errorf("test %v failed, pairwise %v, paths %v", testinfo, res.Pairwise, res.Paths)
+	}
+}
+
+func TestCondorcetVote(t *testing.T) {
+	testCondorcetVote(t, load_store(t))
+}
+
+// It votes in a Condorcet poll, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testCondorcetVote(t *testing.T, store VoteStore) {
+	testinfo := "test CondorcetVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	vote := new_vote(TESTDATA_NEW_VOTE_ID)
+	vote.Contenders = append(vote.Contenders, Contender{Id: 3, Name: "Zig", Alias: "Zig", Picture: "zig.png"})
+	vote.Method = VOTE_METHOD_CONDORCET
+	if _, err := svc.CreateVote(ctx, vote); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the full rankings are accepted, the first preferences are counted;
+		for i, ranking := range [][]int16{{1, 2, 3}, {3, 2, 1}, {2, 3, 1}} {
+			if _, err := svc.CastBallot(public, TESTDATA_NEW_VOTE_ID, ranking, TESTDATA_USER_ID+string(rune('a'+i))); err != nil {
+				t.Errorf("test %v (case # 1) failed, ballot %v, error: %v", testinfo, ranking, err)
+			}
+		}
+
+		// Case 2: 2 beats 1 and 3 (2:1 both), it's the Condorcet winner;
+		res, err := svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || res.Condorcet == nil || res.Runoff != nil {
+			t.Fatalf("test %v (case # 2) failed, results %+v, error: %v", testinfo, res, err)
+		}
+		if res.Contenders[0].Count != 1 || res.Contenders[1].Count != 1 || res.Contenders[2].Count != 1 {
+			t.Errorf("test %v (case # 2) failed, contenders %+v", testinfo, res.Contenders)
+		}
+		want := [][]int64{{0, 1, 1}, {2, 0, 2}, {2, 1, 0}}
+		if res.Condorcet.Condorcet != 2 || res.Condorcet.Winner != 2 || !reflect.DeepEqual(res.Condorcet.Pairwise, want) {
+			t.Errorf("test %v (case # 2) failed, Condorcet %+v", testinfo, res.Condorcet)
+		}
+	})
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,55 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+)
+
+// The transport layer puts the credentials of the caller into the context, the endpoint
+// middleware checks them, and the service decides what the caller is allowed to do.
+
+type contextKey int
+
+const (
+	bearerTokenKey contextKey = iota
+	adminKey
+	voteCodeKey
+)
+
+// WithBearerToken returns a copy of 'ctx' with the token from 'Authorization: Bearer ...'.
+func WithBearerToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, bearerTokenKey, token)
+}
+
+// BearerToken returns the token set by 'WithBearerToken', or "" if there is none.
+func BearerToken(ctx context.Context) string {
+	token, _ := ctx.Value(bearerTokenKey).(string)
+	return token
+}
+
+// WithVoteCode returns a copy of 'ctx' with the invitation code sent by the voter (see 'code.go').
+func WithVoteCode(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, voteCodeKey, code)
+}
+
+// VoteCode returns the code set by 'WithVoteCode', or "" if there is none.
+func VoteCode(ctx context.Context) string {
+	code, _ := ctx.Value(voteCodeKey).(string)
+	return code
+}
+
+// WithAdmin returns a copy of 'ctx' marking the caller as the admin. It must be used only
+// after the caller is authenticated (see 'AdminMiddleware' in 'pkg/endpoint').
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey, true)
+}
+
+// IsAdmin reports whether the caller is the admin.
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey).(bool)
+	return admin
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,285 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Exponential ElGamal: the choice 'm' (0 or 1) is encrypted with the public key 'h' of the
+// poll as (a, b) = (g^r, g^m * h^r), where 'r' is random. The product of the ciphertexts is
+// the ciphertext of the sum of the choices, so the ballots are added up without decryption,
+// and the sum is decrypted once (see 'encrypted.go'): g^sum = b / a^x, where 'x' is the
+// private key, and the sum is found by trying 0, 1, 2, ... (it's not more than the ballots).
+
+// The group is the subgroup of the quadratic residues modulo the 2048-bit safe prime 'p' of
+// RFC 3526 (group 14), its order is q = (p - 1) / 2, and g = 2 generates it. The numbers are
+// hex strings in JSON, the elements must be in the subgroup (see 'parseElement').
+
+// The zero-knowledge proofs are Chaum-Pedersen proofs of equal discrete logarithms, OR-ed for
+// several values (Cramer, Damgard, Schoenmakers), non-interactive by Fiat-Shamir: the challenge
+// is SHA-256 of everything (see 'challenge'), the challenges of the values add up to it modulo
+// 2^256. The proof of a choice tells that it's 0 or 1, the proof of the ballot tells that the
+// sum is 1..'maxChoices', the proof of the tally tells that it's decrypted by the private key
+// of the poll. The proofs are bound to the poll (and the contender), so they cannot be copied
+// to another poll.
+
+const elgamalPrime = "FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22514A08798E3404DD" +
+	"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED" +
+	"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3DC2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F" +
+	"83655D23DCA3AD961C62F356208552BB9ED529077096966D670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B" +
+	"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9DE2BCBF6955817183995497CEA956AE515D2261898FA0510" +
+	"15728E5A8AACAA68FFFFFFFFFFFFFFFF"
+
+var (
+	elgamalP, _ = new(big.Int).SetString(elgamalPrime, 16)
+	elgamalQ    = new(big.Int).Rsh(elgamalP, 1)
+	elgamalG    = big.NewInt(2)
+	challengeN  = new(big.Int).Lsh(big.NewInt(1), 256) // The challenges are 0..2^256-1 (SHA-256);
+)
+
+var errBadElement = errors.New("not an element of the group")
+
+// Ciphertext is the encrypted choice (or the sum of them), see above.
+type Ciphertext struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// ZKProof is the challenge and the response of each value of the OR-proof, see 'proveOr'.
+type ZKProof struct {
+	C []string `json:"c"`
+	Z []string `json:"z"`
+}
+
+// GenerateElGamalKey returns a new private key and its public key ('VoteData.PublicKey'), hex.
+func GenerateElGamalKey() (private, public string, err error) {
+	x, err := randomScalar()
+	if err != nil {
+		return "", "", err
+	}
+	return x.Text(16), new(big.Int).Exp(elgamalG, x, elgamalP).Text(16), nil
+}
+
+// It returns a random number 1..q-1.
+func randomScalar() (*big.Int, error) {
+	for {
+		x, err := rand.Int(rand.Reader, elgamalQ)
+		if err != nil {
+			return nil, err
+		}
+		if x.Sign() > 0 {
+			return x, nil
+		}
+	}
+}
+
+// It parses the element of the group: 1 < e < p and e^q = 1, i.e. a quadratic residue (the
+// Jacobi symbol is 1, it's the same for the prime 'p' and much faster).
+func parseElement(s string) (*big.Int, error) {
+	e, ok := new(big.Int).SetString(s, 16)
+	if !ok || e.Cmp(big.NewInt(1)) <= 0 || e.Cmp(elgamalP) >= 0 {
+		return nil, errBadElement
+	}
+	if big.Jacobi(e, elgamalP) != 1 {
+		return nil, errBadElement
+	}
+	return e, nil
+}
+
+// It parses the number 0..q-1.
+func parseScalar(s string) (*big.Int, error) {
+	return parseBelow(s, elgamalQ)
+}
+
+// It parses the number 0..n-1.
+func parseBelow(s string, n *big.Int) (*big.Int, error) {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok || x.Sign() < 0 || x.Cmp(n) >= 0 {
+		return nil, errBadElement
+	}
+	return x, nil
+}
+
+func (c *Ciphertext) parse() (a, b *big.Int, err error) {
+	if a, err = parseElement(c.A); err != nil {
+		return nil, nil, err
+	}
+	if b, err = parseElement(c.B); err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+func newCiphertext(a, b *big.Int) Ciphertext {
+	return Ciphertext{A: a.Text(16), B: b.Text(16)}
+}
+
+// It encrypts 'm' with the public key 'h' and the random 'r'.
+func encrypt(h *big.Int, m int64, r *big.Int) (a, b *big.Int) {
+	a = new(big.Int).Exp(elgamalG, r, elgamalP)
+	b = new(big.Int).Exp(h, r, elgamalP)
+	b.Mul(b, gPow(m)).Mod(b, elgamalP)
+	return a, b
+}
+
+// It returns g^m (m may be negative, then it's the inverse of g^-m).
+func gPow(m int64) *big.Int {
+	if m < 0 {
+		res := gPow(-m)
+		return res.ModInverse(res, elgamalP)
+	}
+	return new(big.Int).Exp(elgamalG, big.NewInt(m), elgamalP)
+}
+
+// It returns x * y^-e mod p, the commitment of the proof recomputed from the response.
+func expDiv(x, y, e *big.Int) *big.Int {
+	res := new(big.Int).Exp(y, e, elgamalP)
+	res.ModInverse(res, elgamalP)
+	return res.Mul(res, x).Mod(res, elgamalP)
+}
+
+// It's the Fiat-Shamir challenge: SHA-256 of the 'label' and the numbers.
+func challenge(label string, values ...*big.Int) *big.Int {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = v.Text(16)
+	}
+	sum := sha256.Sum256([]byte("vote_svc/elgamal\n" + label + "\n" + strings.Join(s, "\n")))
+	return new(big.Int).SetBytes(sum[:])
+}
+
+// It proves that y1 = g1^s and y2s[k] = g2^s for one of 'k' (the 'real' one), without telling
+// which one: the other branches are simulated, and the challenges add up to the Fiat-Shamir one.
+func proveOr(label string, g1, g2, y1 *big.Int, y2s []*big.Int, real int, s *big.Int) (*ZKProof, error) {
+	n := len(y2s)
+	cs := make([]*big.Int, n)
+	zs := make([]*big.Int, n)
+	values := []*big.Int{g1, g2, y1}
+	values = append(values, y2s...)
+
+	w, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	sum := new(big.Int)
+	for k := 0; k < n; k++ {
+		if k == real {
+			values = append(values, new(big.Int).Exp(g1, w, elgamalP), new(big.Int).Exp(g2, w, elgamalP))
+			continue
+		}
+		if cs[k], err = rand.Int(rand.Reader, challengeN); err != nil {
+			return nil, err
+		}
+		if zs[k], err = randomScalar(); err != nil {
+			return nil, err
+		}
+		sum.Add(sum, cs[k])
+		values = append(values, expDiv(new(big.Int).Exp(g1, zs[k], elgamalP), y1, cs[k]),
+			expDiv(new(big.Int).Exp(g2, zs[k], elgamalP), y2s[k], cs[k]))
+	}
+
+	c := challenge(label, values...)
+	cs[real] = sum.Sub(c, sum).Mod(sum, challengeN)
+	zs[real] = new(big.Int).Mul(cs[real], s)
+	zs[real].Add(zs[real], w).Mod(zs[real], elgamalQ)
+
+	p := &ZKProof{C: make([]string, n), Z: make([]string, n)}
+	for k := 0; k < n; k++ {
+		p.C[k], p.Z[k] = cs[k].Text(16), zs[k].Text(16)
+	}
+	return p, nil
+}
+
+// It verifies the proof made by 'proveOr'.
+func verifyOr(label string, g1, g2, y1 *big.Int, y2s []*big.Int, p *ZKProof) bool {
+	n := len(y2s)
+	if p == nil || len(p.C) != n || len(p.Z) != n {
+		return false
+	}
+
+	values := []*big.Int{g1, g2, y1}
+	values = append(values, y2s...)
+	sum := new(big.Int)
+	for k := 0; k < n; k++ {
+		c, err := parseBelow(p.C[k], challengeN)
+		if err != nil {
+			return false
+		}
+		z, err := parseScalar(p.Z[k])
+		if err != nil {
+			return false
+		}
+		sum.Add(sum, c)
+		values = append(values, expDiv(new(big.Int).Exp(g1, z, elgamalP), y1, c),
+			expDiv(new(big.Int).Exp(g2, z, elgamalP), y2s[k], c))
+	}
+	return sum.Mod(sum, challengeN).Cmp(challenge(label, values...)) == 0
+}
+
+// The values of the ciphertext (a, b) are b / g^m for each 'm': one of them is h^r.
+func valueTargets(b *big.Int, values []int64) []*big.Int {
+	res := make([]*big.Int, len(values))
+	for k, m := range values {
+		res[k] = new(big.Int).Mul(b, gPow(-m))
+		res[k].Mod(res[k], elgamalP)
+	}
+	return res
+}
+
+// It proves that (a, b) = encrypt(h, values[real], r).
+func proveValue(label string, h, a, b *big.Int, values []int64, real int, r *big.Int) (*ZKProof, error) {
+	return proveOr(label, elgamalG, h, a, valueTargets(b, values), real, r)
+}
+
+// It verifies that (a, b) is the ciphertext of one of the 'values'.
+func verifyValue(label string, h, a, b *big.Int, values []int64, p *ZKProof) bool {
+	return verifyOr(label, elgamalG, h, a, valueTargets(b, values), p)
+}
+
+// It proves that g^m = b / a^x, i.e. (a, b) is decrypted to 'm' by the private key of h = g^x.
+func proveDecryption(label string, h, a, b *big.Int, m int64, x *big.Int) (*ZKProof, error) {
+	return proveOr(label, elgamalG, a, h, valueTargets(b, []int64{m}), 0, x)
+}
+
+func verifyDecryption(label string, h, a, b *big.Int, m int64, p *ZKProof) bool {
+	return verifyOr(label, elgamalG, a, h, valueTargets(b, []int64{m}), p)
+}
+
+// It decrypts (a, b) to 'm' = 0..max, or returns an error if it's not there.
+func decrypt(x, a, b *big.Int, max int64) (int64, error) {
+	gm := new(big.Int).Exp(a, x, elgamalP)
+	gm.ModInverse(gm, elgamalP).Mul(gm, b).Mod(gm, elgamalP)
+
+	e := big.NewInt(1)
+	for m := int64(0); m <= max; m++ {
+		if e.Cmp(gm) == 0 {
+			return m, nil
+		}
+		e.Mul(e, elgamalG).Mod(e, elgamalP)
+	}
+	return 0, fmt.Errorf("%w: the sum is out of range", ErrBadRequest)
+}
+
+// The values 'from'..'to'.
+func valueRange(from, to int64) []int64 {
+	res := make([]int64, 0, to-from+1)
+	for m := from; m <= to; m++ {
+		res = append(res, m)
+	}
+	return res
+}
+
+// The label of the proof binds it to the poll and the contender (0 for the whole ballot).
+func proofLabel(kind string, vote_id int, co_id int16) string {
+	return kind + "/" + strconv.Itoa(vote_id) + "/" + strconv.Itoa(int(co_id))
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,429 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// Encrypted polls: the poll has the ElGamal public key ('VoteData.PublicKey', see 'elgamal.go'),
+// and the voter sends a ciphertext of 0 or 1 for each contender in the race, with the proofs
+// that each one is 0 or 1, and that the sum is 1..'maxChoices' (see 'EncryptBallot'). The
+// service checks the proofs and saves the ballot as it is (see 'VoteStore.AddEncryptedBallot'),
+// the counts of the contenders are not changed: nobody can see them before the close, even
+// with the access to the database.
+
+// The private key is not known to the service. When the poll is closed, the key holder runs
+// 'vote-svc tally' (see 'cmd/main.go'): the ciphertexts of each contender are multiplied (it's
+// the ciphertext of the count), the products are decrypted, and the counts are saved with the
+// proofs of the decryption (see 'TallyEncryptedVote'). Then the results are the counts of the
+// tally, and anybody with the ballots can check it (see 'VerifyTally').
+
+// The ballot id is the hash of the ciphertexts (see 'id'), so the copy of somebody's ballot is
+// rejected (ErrConflict), even if its choices are reordered or re-encoded. An encrypted poll is
+// a plurality (or approval) poll, and it's not revisable.
+
+const ERR_MSG_NOT_ENCRYPTED = "poll is not encrypted"
+const ERR_MSG_KEY_MISMATCH = "private key does not match the poll"
+
+var (
+	ErrNotEncrypted = fmt.Errorf("%w: %s", ErrBadRequest, ERR_MSG_NOT_ENCRYPTED)
+	ErrKeyMismatch  = fmt.Errorf("%w: %s", ErrForbidden, ERR_MSG_KEY_MISMATCH)
+)
+
+// These are the kinds of the proofs, see 'proofLabel'.
+const proofChoice = "choice"
+const proofBallot = "ballot"
+const proofTally = "tally"
+
+// EncryptedChoice is the ciphertext of 0 or 1 for the contender, with the proof.
+type EncryptedChoice struct {
+	ContenderId int16 `json:"co_id"`
+	Ciphertext
+	Proof *ZKProof `json:"proof"`
+}
+
+// EncryptedBallot has a choice for each contender in the race, and the proof of their sum.
+type EncryptedBallot struct {
+	Choices []EncryptedChoice `json:"choices"`
+	Proof   *ZKProof          `json:"proof"` // The sum is 1..'maxChoices';
+}
+
+// EncryptedTally is the decrypted result of the encrypted poll, see 'TallyEncryptedVote'.
+type EncryptedTally struct {
+	VoteId     int              `json:"vote_id"`
+	Ballots    int64            `json:"ballots"`  // The counted ballots;
+	Rejected   int64            `json:"rejected"` // The ballots with wrong proofs, they are not counted;
+	Contenders []TallyContender `json:"contenders"`
+	TalliedAt  time.Time        `json:"tallied_at"`
+}
+
+// TallyContender is the product of the choices of the contender, its count, and the proof
+// that the count is decrypted by the private key of the poll.
+type TallyContender struct {
+	ContenderId int16 `json:"co_id"`
+	Ciphertext
+	Count int64    `json:"count"`
+	Proof *ZKProof `json:"proof"`
+}
+
+// It returns the public key of the encrypted poll.
+func (v *VoteData) publicKey() (*big.Int, error) {
+	if v.PublicKey == "" {
+		return nil, ErrNotEncrypted
+	}
+	h, err := parseElement(v.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public key is %v", ErrBadRequest, err)
+	}
+	return h, nil
+}
+
+// The id of the ballot is SHA-256 of its ciphertexts, hex. They are taken in the canonical
+// form: sorted by the contender, the numbers in lowercase without leading zeros, so the copy
+// of a ballot with the choices reordered or the numbers written otherwise has the same id.
+func (e *EncryptedBallot) id() string {
+	choices := append([]EncryptedChoice(nil), e.Choices...)
+	sort.Slice(choices, func(i, j int) bool { return choices[i].ContenderId < choices[j].ContenderId })
+
+	h := sha256.New()
+	for _, c := range choices {
+		fmt.Fprintf(h, "%d:%s:%s\n", c.ContenderId, canonicalHex(c.A), canonicalHex(c.B))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// It returns the hex number as 'big.Int.Text' does, or the string as it is if it's not a number
+// (such a ballot is rejected before, see 'checkEncrypted').
+func canonicalHex(s string) string {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		return s
+	}
+	return x.Text(16)
+}
+
+//////////////////
+//
+// ENCRYPT BALLOT
+//
+//////////////////
+
+// EncryptBallot returns the encrypted ballot of the poll choosing 'co_ids', it's what the
+// client sends (see 'CastEncryptedBallot'). It's here for the Go clients and the tests, the
+// other clients must do the same (see 'elgamal.go').
+func EncryptBallot(vote *VoteData, co_ids []int16) (*EncryptedBallot, error) {
+	h, err := vote.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	if err = vote.checkChoices(co_ids); err != nil {
+		return nil, err
+	}
+
+	chosen := map[int16]bool{}
+	for _, co_id := range co_ids {
+		chosen[co_id] = true
+	}
+
+	res := &EncryptedBallot{}
+	sumA, sumB, sumR := big.NewInt(1), big.NewInt(1), new(big.Int)
+	for _, c := range vote.Contenders {
+		if c.Withdrawn {
+			continue
+		}
+		var m int64
+		if chosen[c.Id] {
+			m = 1
+		}
+		r, err := randomScalar()
+		if err != nil {
+			return nil, err
+		}
+		a, b := encrypt(h, m, r)
+		proof, err := proveValue(proofLabel(proofChoice, vote.VoteId, c.Id), h, a, b, []int64{0, 1}, int(m), r)
+		if err != nil {
+			return nil, err
+		}
+		res.Choices = append(res.Choices, EncryptedChoice{ContenderId: c.Id, Ciphertext: newCiphertext(a, b), Proof: proof})
+
+		sumA.Mul(sumA, a).Mod(sumA, elgamalP)
+		sumB.Mul(sumB, b).Mod(sumB, elgamalP)
+		sumR.Add(sumR, r).Mod(sumR, elgamalQ)
+	}
+
+	values := valueRange(1, int64(vote.maxChoices()))
+	if res.Proof, err = proveValue(proofLabel(proofBallot, vote.VoteId, 0), h, sumA, sumB, values, len(co_ids)-1, sumR); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// It checks the proofs of the ballot, and returns the ciphertexts of the choices (co_id ->
+// a, b). The contenders must exist, but they may be withdrawn after the ballot was cast (see
+// 'checkEncrypted').
+func (v *VoteData) verifyEncrypted(h *big.Int, e *EncryptedBallot) (map[int16][2]*big.Int, error) {
+	res := make(map[int16][2]*big.Int, len(e.Choices))
+	sumA, sumB := big.NewInt(1), big.NewInt(1)
+	for _, c := range e.Choices {
+		if v.findContender(c.ContenderId) == nil {
+			return nil, ErrBadRequest
+		}
+		if _, ok := res[c.ContenderId]; ok {
+			return nil, fmt.Errorf("%w: duplicate contender %d", ErrBadRequest, c.ContenderId)
+		}
+
+		a, b, err := c.parse()
+		if err != nil {
+			return nil, fmt.Errorf("%w: contender %d: %v", ErrBadRequest, c.ContenderId, err)
+		}
+		if !verifyValue(proofLabel(proofChoice, v.VoteId, c.ContenderId), h, a, b, []int64{0, 1}, c.Proof) {
+			return nil, fmt.Errorf("%w: contender %d: wrong proof", ErrBadRequest, c.ContenderId)
+		}
+		res[c.ContenderId] = [2]*big.Int{a, b}
+
+		sumA.Mul(sumA, a).Mod(sumA, elgamalP)
+		sumB.Mul(sumB, b).Mod(sumB, elgamalP)
+	}
+
+	values := valueRange(1, int64(v.maxChoices()))
+	if !verifyValue(proofLabel(proofBallot, v.VoteId, 0), h, sumA, sumB, values, e.Proof) {
+		return nil, fmt.Errorf("%w: wrong proof of the ballot", ErrBadRequest)
+	}
+	return res, nil
+}
+
+// It returns an error if the ballot cannot be cast: each contender in the race must have
+// a choice, the withdrawn ones cannot (ErrWithdrawn), and the proofs must be valid.
+func (v *VoteData) checkEncrypted(e *EncryptedBallot) error {
+	h, err := v.publicKey()
+	if err != nil {
+		return err
+	}
+
+	race := 0
+	for _, c := range v.Contenders {
+		if !c.Withdrawn {
+			race++
+		}
+	}
+	for _, c := range e.Choices {
+		if contender := v.findContender(c.ContenderId); contender != nil && contender.Withdrawn {
+			return ErrWithdrawn
+		}
+	}
+	if len(e.Choices) != race {
+		return fmt.Errorf("%w: every contender must have a choice", ErrBadRequest)
+	}
+
+	_, err = v.verifyEncrypted(h, e)
+	return err
+}
+
+/////////////////////////
+//
+// CAST ENCRYPTED BALLOT
+//
+/////////////////////////
+
+// It's a ballot of the encrypted poll, see above. The rest is the same as 'CastBallot': the poll
+// must be open, the voter votes once, etc. The receipt and the audit entry have no choices.
+
+func (b *basicVoteService) CastEncryptedBallot(ctx context.Context, vote_id int, ballot EncryptedBallot, user_id string) (*Receipt, error) {
+	return b.castVote(ctx, vote_id, user_id, func(vote *VoteData) (*VoteRecord, error) {
+		if err := vote.checkEncrypted(&ballot); err != nil {
+			return nil, err
+		}
+		return &VoteRecord{BallotId: ballot.id(), Encrypted: &ballot}, nil
+	})
+}
+
+// It sets the counts of the tallied encrypted poll, or returns ErrResultsHidden (see
+// 'GetVoteResults').
+func (b *basicVoteService) encryptedResults(ctx context.Context, vote *VoteData) error {
+	t, err := b.store.LoadTally(ctx, vote.VoteId)
+	if err == ErrNotFound {
+		return ErrResultsHidden // Even for the admin, nobody knows the counts;
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, c := range t.Contenders {
+		if contender := vote.findContender(c.ContenderId); contender != nil {
+			contender.Count = c.Count
+			contender.Updated = t.TalliedAt
+		}
+	}
+	vote.Tally = t
+	return nil
+}
+
+////////////////////////
+//
+// TALLY ENCRYPTED VOTE
+//
+////////////////////////
+
+// TallyEncryptedVote decrypts the counts of the closed encrypted poll with the private key
+// (hex, see 'GenerateElGamalKey'), and saves them. The proofs of the ballots are checked again,
+// the ballots with wrong ones are not counted (they cannot be saved by the service, so it's
+// a sign that the database has been changed). It returns ErrNotClosed before the close,
+// ErrKeyMismatch if it's not the key of the poll, and ErrConflict if it's tallied already.
+// It's used by 'vote-svc tally', see 'cmd/main.go'.
+
+func TallyEncryptedVote(ctx context.Context, store VoteStore, vote_id int, private string) (*EncryptedTally, error) {
+	b := &basicVoteService{store: store}
+	vote, err := b.loadClosedVote(WithAdmin(ctx), vote_id)
+	if err != nil {
+		return nil, err
+	}
+	h, err := vote.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := parseScalar(private)
+	if err != nil || new(big.Int).Exp(elgamalG, x, elgamalP).Cmp(h) != 0 {
+		return nil, ErrKeyMismatch
+	}
+
+	if _, err = store.LoadTally(ctx, vote_id); err != ErrNotFound {
+		if err == nil {
+			err = fmt.Errorf("%w: the poll is tallied already", ErrConflict)
+		}
+		return nil, err
+	}
+
+	res, products, err := aggregate(ctx, store, vote, h)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range res.Contenders {
+		c := &res.Contenders[i]
+		a, b := products[c.ContenderId][0], products[c.ContenderId][1]
+		if c.Count, err = decrypt(x, a, b, res.Ballots); err != nil {
+			return nil, err
+		}
+		if c.Proof, err = proveDecryption(proofLabel(proofTally, vote_id, c.ContenderId), h, a, b, c.Count, x); err != nil {
+			return nil, err
+		}
+	}
+	res.TalliedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	applied, err := store.SaveTally(ctx, vote_id, res)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return nil, fmt.Errorf("%w: the poll is tallied already", ErrConflict)
+	}
+	return res, nil
+}
+
+// It loads the ballots of the poll, and multiplies the ciphertexts of each contender. The
+// tally has the products, without the counts.
+func aggregate(ctx context.Context, store VoteStore, vote *VoteData, h *big.Int) (*EncryptedTally, map[int16][2]*big.Int, error) {
+	ballots, err := store.LoadEncryptedBallots(ctx, vote.VoteId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	products := make(map[int16][2]*big.Int, len(vote.Contenders))
+	for _, c := range vote.Contenders {
+		products[c.Id] = [2]*big.Int{big.NewInt(1), big.NewInt(1)}
+	}
+
+	res := &EncryptedTally{VoteId: vote.VoteId}
+	for _, ballot := range ballots {
+		choices, err := vote.verifyEncrypted(h, ballot)
+		if err != nil {
+			res.Rejected++
+			continue
+		}
+		res.Ballots++
+		for co_id, c := range choices {
+			p := products[co_id]
+			p[0].Mul(p[0], c[0]).Mod(p[0], elgamalP)
+			p[1].Mul(p[1], c[1]).Mod(p[1], elgamalP)
+		}
+	}
+
+	for _, c := range vote.Contenders {
+		p := products[c.Id]
+		res.Contenders = append(res.Contenders, TallyContender{ContenderId: c.Id, Ciphertext: newCiphertext(p[0], p[1])})
+	}
+	return res, products, nil
+}
+
+////////////////
+//
+// VERIFY TALLY
+//
+////////////////
+
+// VerifyTally checks the saved tally of the encrypted poll against its ballots: the products
+// of the ciphertexts are computed again, and the proof of each count must be valid for the
+// public key of the poll. It needs no private key, it returns nil if the tally is right.
+
+func VerifyTally(ctx context.Context, store VoteStore, vote_id int) error {
+	vote, err := store.LoadVote(ctx, vote_id)
+	if err != nil {
+		return err
+	}
+	h, err := vote.publicKey()
+	if err != nil {
+		return err
+	}
+
+	t, err := store.LoadTally(ctx, vote_id)
+	if err != nil {
+		return err
+	}
+	res, _, err := aggregate(ctx, store, vote, h)
+	if err != nil {
+		return err
+	}
+
+	if t.Ballots != res.Ballots || t.Rejected != res.Rejected || len(t.Contenders) != len(res.Contenders) {
+		return fmt.Errorf("%w: %d ballots in the tally, %d counted", ErrConflict, t.Ballots, res.Ballots)
+	}
+	for i, c := range t.Contenders {
+		if c.ContenderId != res.Contenders[i].ContenderId || c.Ciphertext != res.Contenders[i].Ciphertext {
+			return fmt.Errorf("%w: contender %d: the ballots do not match the tally", ErrConflict, c.ContenderId)
+		}
+		a, _ := new(big.Int).SetString(c.A, 16)
+		b, _ := new(big.Int).SetString(c.B, 16)
+		if !verifyDecryption(proofLabel(proofTally, vote_id, c.ContenderId), h, a, b, c.Count, c.Proof) {
+			return fmt.Errorf("%w: contender %d: wrong proof of the count", ErrConflict, c.ContenderId)
+		}
+	}
+	return nil
+}
+
+// It returns ErrBadRequest if the encrypted poll cannot be saved, see 'validateVote'.
+func validateEncrypted(vote *VoteData) error {
+	if vote.PublicKey == "" {
+		return nil
+	}
+	if _, err := vote.publicKey(); err != nil {
+		return err
+	}
+	if vote.Method != "" && vote.Method != VOTE_METHOD_PLURALITY {
+		return fmt.Errorf("%w: encrypted poll must be a plurality poll", ErrBadRequest)
+	}
+	if vote.Revisable {
+		return fmt.Errorf("%w: encrypted poll cannot be revisable", ErrBadRequest)
+	}
+	return nil
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,238 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestElGamalProofs(t *testing.T) {
+	testinfo := "test ElGamalProofs"
+
+	if !elgamalP.ProbablyPrime(20) || !elgamalQ.ProbablyPrime(20) {
+		t.Fatalf("test %v failed, p is not a safe prime", testinfo)
+	}
+	if _, err := parseElement(elgamalG.Text(16)); err != nil {
+		t.Fatalf("test %v failed, g is not in the group: %v", testinfo, err)
+	}
+
+	private, public, err := GenerateElGamalKey()
+	if err != nil {
+		t.Fatalf("test %v failed, GenerateElGamalKey err %v", testinfo, err)
+	}
+	x, _ := parseScalar(private)
+	h, err := parseElement(public)
+	if err != nil {
+		t.Fatalf("test %v failed, the public key: %v", testinfo, err)
+	}
+
+	// Case 1: the proof of 0 or 1, it's bound to the label;
+	sumA, sumB := big.NewInt(1), big.NewInt(1)
+	for i, m := range []int64{0, 1, 1} {
+		r, _ := randomScalar()
+		a, b := encrypt(h, m, r)
+		p, err := proveValue("test", h, a, b, []int64{0, 1}, int(m), r)
+		if err != nil || !verifyValue("test", h, a, b, []int64{0, 1}, p) {
+			t.Errorf("test %v (case # 1) failed, the proof of %d, error: %v", testinfo, m, err)
+		}
+		if verifyValue("other", h, a, b, []int64{0, 1}, p) || verifyValue("test", h, b, a, []int64{0, 1}, p) {
+			t.Errorf("test %v (case # 1) failed, the proof of %d is accepted for %d", testinfo, m, i)
+		}
+		sumA.Mul(sumA, a).Mod(sumA, elgamalP)
+		sumB.Mul(sumB, b).Mod(sumB, elgamalP)
+	}
+
+	// Case 2: 2 is not 0 or 1, the proof of 0..2 is not the proof of 0 or 1;
+	r, _ := randomScalar()
+	a, b := encrypt(h, 2, r)
+	p, err := proveValue("test", h, a, b, []int64{0, 1, 2}, 2, r)
+	if err != nil || !verifyValue("test", h, a, b, []int64{0, 1, 2}, p) || verifyValue("test", h, a, b, []int64{0, 1}, p) {
+		t.Errorf("test %v (case # 2) failed, error: %v", testinfo, err)
+	}
+	p.C[0], p.C[1] = p.C[1], p.C[0]
+	if verifyValue("test", h, a, b, []int64{0, 1, 2}, p) {
+		t.Errorf("test %v (case # 2) failed, the changed proof is accepted", testinfo)
+	}
+
+	// Case 3: the product is decrypted to the sum, with the proof;
+	m, err := decrypt(x, sumA, sumB, 3)
+	if err != nil || m != 2 {
+		t.Fatalf("test %v (case # 3) failed, the sum %d (must be 2), error: %v", testinfo, m, err)
+	}
+	p, err = proveDecryption("test", h, sumA, sumB, m, x)
+	if err != nil || !verifyDecryption("test", h, sumA, sumB, 2, p) || verifyDecryption("test", h, sumA, sumB, 1, p) {
+		t.Errorf("test %v (case # 3) failed, the proof of the decryption, error: %v", testinfo, err)
+	}
+	if _, err = decrypt(x, sumA, sumB, 1); err == nil {
+		t.Errorf("test %v (case # 3) failed, the sum out of range is decrypted", testinfo)
+	}
+
+	// Case 4: the elements must be in the group;
+	for _, s := range []string{"0", "1", elgamalP.Text(16), "x", new(big.Int).Sub(elgamalP, big.NewInt(1)).Text(16)} {
+		if _, err = parseElement(s); err == nil {
+			t.Errorf("test %v (case # 4) failed, %q is accepted", testinfo, s)
+		}
+	}
+}
+
+func TestEncryptedVote(t *testing.T) {
+	testEncryptedVote(t, load_store(t))
+}
+
+// It checks the encrypted polls, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testEncryptedVote(t *testing.T, store VoteStore) {
+	testinfo := "test EncryptedVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	private, key, err := GenerateElGamalKey()
+	if err != nil {
+		t.Fatalf("test %v failed, GenerateElGamalKey err %v", testinfo, err)
+	}
+	other, other_key, _ := GenerateElGamalKey()
+
+	// Case 1: the key must be valid, and the poll is not revisable;
+	vote := new_vote(TESTDATA_NEW_VOTE_ID)
+	vote.MaxChoices = 2
+	vote.Contenders = append(vote.Contenders, Contender{Id: 3, Name: "Zig"})
+	for _, v := range []VoteData{{PublicKey: "1"}, {PublicKey: "key"}, {PublicKey: key, Revisable: true},
+		{PublicKey: key, Method: VOTE_METHOD_SCORE}} {
+		bad := vote
+		bad.PublicKey, bad.Revisable, bad.Method = v.PublicKey, v.Revisable, v.Method
+		if _, err = svc.CreateVote(ctx, bad); err == nil {
+			t.Errorf("test %v (case # 1) failed, the poll %+v is created", testinfo, v)
+			svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+		}
+	}
+
+	vote.PublicKey = key
+	if _, err = svc.CreateVote(ctx, vote); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	data, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID)
+	if err != nil || data.PublicKey != key {
+		t.Fatalf("test %v failed, GetVoteData %+v, error: %v", testinfo, data, err)
+	}
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 2: the ballots are saved, the counts are not changed and hidden from everybody;
+		var ballots []*EncryptedBallot
+		for i, co_ids := range [][]int16{{1}, {2, 3}, {1, 3}, {1}} {
+			ballot, err := EncryptBallot(data, co_ids)
+			if err != nil {
+				t.Fatalf("test %v (case # 2) failed, EncryptBallot err %v", testinfo, err)
+			}
+			r, err := svc.CastEncryptedBallot(public, TESTDATA_NEW_VOTE_ID, *ballot, TESTDATA_USER_ID+string(rune('a'+i)))
+			if err != nil || r.Id == "" {
+				t.Fatalf("test %v (case # 2) failed, CastEncryptedBallot err %v", testinfo, err)
+			}
+			ballots = append(ballots, ballot)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-x"); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("test %v (case # 2) failed, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+		}
+		if _, err := svc.GetVoteResults(ctx, TESTDATA_NEW_VOTE_ID); err != ErrResultsHidden {
+			t.Errorf("test %v (case # 2) failed, error: %v (must be %v)", testinfo, err, ErrResultsHidden)
+		}
+		stored, err := store.LoadVote(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil {
+			t.Fatalf("test %v (case # 2) failed, LoadVote err %v", testinfo, err)
+		}
+		for _, c := range stored.Contenders {
+			if c.Count != 0 {
+				t.Errorf("test %v (case # 2) failed, the count of %d is %d", testinfo, c.Id, c.Count)
+			}
+		}
+
+		// Case 3: the copied, changed and wrong ballots are rejected;
+		permuted := *ballots[0]
+		permuted.Choices = nil
+		for i := len(ballots[0].Choices) - 1; i >= 0; i-- {
+			permuted.Choices = append(permuted.Choices, ballots[0].Choices[i])
+		}
+		recoded := *ballots[0]
+		recoded.Choices = append([]EncryptedChoice(nil), ballots[0].Choices...)
+		recoded.Choices[0].A = strings.ToUpper(recoded.Choices[0].A)
+		recoded.Choices[1].B = "00" + recoded.Choices[1].B
+		for i, ballot := range []*EncryptedBallot{ballots[0], &permuted, &recoded} {
+			if _, err = svc.CastEncryptedBallot(public, TESTDATA_NEW_VOTE_ID, *ballot, TESTDATA_USER_ID+"-x"); err != ErrConflict {
+				t.Errorf("test %v (case # 3) failed, copy # %d, error: %v (must be %v)", testinfo, i+1, err, ErrConflict)
+			}
+		}
+		changed := *ballots[1]
+		changed.Choices = append([]EncryptedChoice(nil), changed.Choices...)
+		changed.Choices[0].Ciphertext, changed.Choices[1].Ciphertext = changed.Choices[1].Ciphertext, changed.Choices[0].Ciphertext
+		wide := *data
+		wide.MaxChoices = 3
+		three, _ := EncryptBallot(&wide, []int16{1, 2, 3})
+		short := *ballots[2]
+		short.Choices = short.Choices[:2]
+		for i, ballot := range []*EncryptedBallot{&changed, three, &short, {}} {
+			if _, err = svc.CastEncryptedBallot(public, TESTDATA_NEW_VOTE_ID, *ballot, TESTDATA_USER_ID+"-x"); !errors.Is(err, ErrBadRequest) {
+				t.Errorf("test %v (case # 3) failed, ballot # %d, error: %v (must be %v)", testinfo, i+1, err, ErrBadRequest)
+			}
+		}
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{PublicKey: &other_key}); !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrConflict)
+		}
+
+		// Case 4: the poll is tallied with its key after the close;
+		if _, err = TallyEncryptedVote(public, store, TESTDATA_NEW_VOTE_ID, private); err != ErrNotClosed {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrNotClosed)
+		}
+		if _, err = svc.CloseVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Fatalf("test %v (case # 4) failed, CloseVote err %v", testinfo, err)
+		}
+		if _, err = svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID); err != ErrResultsHidden {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrResultsHidden)
+		}
+		if _, err = TallyEncryptedVote(public, store, TESTDATA_NEW_VOTE_ID, other); err != ErrKeyMismatch {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrKeyMismatch)
+		}
+		tally, err := TallyEncryptedVote(public, store, TESTDATA_NEW_VOTE_ID, private)
+		if err != nil || tally.Ballots != 4 || tally.Rejected != 0 {
+			t.Fatalf("test %v (case # 4) failed, tally %+v, error: %v", testinfo, tally, err)
+		}
+		if err = VerifyTally(public, store, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Errorf("test %v (case # 4) failed, VerifyTally err %v", testinfo, err)
+		}
+		res, err := svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || res.Tally == nil {
+			t.Fatalf("test %v (case # 4) failed, GetVoteResults err %v", testinfo, err)
+		}
+		for co_id, count := range map[int16]int64{1: 3, 2: 1, 3: 2} {
+			if c := res.findContender(co_id); c == nil || c.Count != count {
+				t.Errorf("test %v (case # 4) failed, contender %d: %+v (must be %d)", testinfo, co_id, c, count)
+			}
+		}
+		if _, err = TallyEncryptedVote(public, store, TESTDATA_NEW_VOTE_ID, private); !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrConflict)
+		}
+
+		// Case 5: the ballots added behind the service do not match the tally, the wrong ones are not counted;
+		if err = store.AddEncryptedBallot(public, TESTDATA_NEW_VOTE_ID, changed.id(), &changed); err != nil {
+			t.Fatalf("test %v (case # 5) failed, AddEncryptedBallot err %v", testinfo, err)
+		}
+		if err = VerifyTally(public, store, TESTDATA_NEW_VOTE_ID); !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 5) failed, error: %v (must be %v)", testinfo, err, ErrConflict)
+		}
+		if err = store.RemoveTally(public, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Fatalf("test %v (case # 5) failed, RemoveTally err %v", testinfo, err)
+		}
+		tally, err = TallyEncryptedVote(public, store, TESTDATA_NEW_VOTE_ID, private)
+		if err != nil || tally.Ballots != 4 || tally.Rejected != 1 || tally.Contenders[0].Count != 3 {
+			t.Errorf("test %v (case # 5) failed, tally %+v, error: %v", testinfo, tally, err)
+		}
+	})
+}
+
+// --- END OF FILE ---
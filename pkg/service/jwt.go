@@ -0,0 +1,283 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// The voters of the polls with 'Authenticate' are identified by a signed bearer token (JWT)
+// issued by some identity provider. The service does not issue tokens, it only checks the
+// signature with the public keys configured locally: PEM files, or JWKS files (the same
+// JSON as '.well-known/jwks.json' of the provider). The 'user_id' is the token subject.
+
+// TokenVerifier checks the bearer token, and returns the subject (the user id).
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (string, error)
+}
+
+// The signing methods of the asymmetric keys, HMAC is not accepted (it's a shared secret,
+// and anybody who can check the token can issue it as well).
+var jwtMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type jwtKey struct {
+	kid string // Key id, empty if the key comes from a PEM file;
+	key crypto.PublicKey
+}
+
+type jwtVerifier struct {
+	keys   []jwtKey
+	parser *jwt.Parser
+}
+
+// JWTOption changes the checks of the token claims (see 'NewJWTVerifier').
+type JWTOption func(*[]jwt.ParserOption)
+
+// WithIssuer requires the claim 'iss' to be equal to 'issuer'.
+func WithIssuer(issuer string) JWTOption {
+	return func(o *[]jwt.ParserOption) {
+		*o = append(*o, jwt.WithIssuer(issuer))
+	}
+}
+
+// WithAudience requires the claim 'aud' to contain 'audience'.
+func WithAudience(audience string) JWTOption {
+	return func(o *[]jwt.ParserOption) {
+		*o = append(*o, jwt.WithAudience(audience))
+	}
+}
+
+//////////
+//
+// VERIFY
+//
+//////////
+
+// The token must be signed by one of the keys, and it must not be expired ('exp' is required).
+// If the token has a key id ('kid'), only the key with the same id is used.
+func (v *jwtVerifier) Verify(_ context.Context, token string) (string, error) {
+	claims := jwt.RegisteredClaims{}
+	_, err := v.parser.ParseWithClaims(token, &claims, v.keyFunc)
+	if err != nil {
+		return "", err
+	}
+
+	if claims.Subject == "" {
+		return "", errors.New("token has no subject")
+	}
+	return claims.Subject, nil
+}
+
+func (v *jwtVerifier) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	set := jwt.VerificationKeySet{}
+	for _, k := range v.keys {
+		if kid == "" || k.kid == "" || k.kid == kid {
+			set.Keys = append(set.Keys, k.key)
+		}
+	}
+
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return set, nil
+}
+
+/////////////////////
+//
+// NEW JWT VERIFIER
+//
+/////////////////////
+
+// NewJWTVerifier returns a TokenVerifier checking JWT with the public keys from the files.
+// A file is JWKS if it's JSON, otherwise it's PEM (public keys or certificates).
+func NewJWTVerifier(filenames []string, opts ...JWTOption) (TokenVerifier, error) {
+	var keys []jwtKey
+	for _, name := range filenames {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		var k []jwtKey
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+			k, err = parseJWKS(data)
+		} else {
+			k, err = parsePublicKeys(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(name), err)
+		}
+		keys = append(keys, k...)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no keys to check JWT")
+	}
+
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods(jwtMethods), jwt.WithExpirationRequired()}
+	for _, opt := range opts {
+		opt(&parserOpts)
+	}
+
+	return &jwtVerifier{keys: keys, parser: jwt.NewParser(parserOpts...)}, nil
+}
+
+// It reads all PEM blocks: 'PUBLIC KEY', 'RSA PUBLIC KEY' and 'CERTIFICATE'.
+func parsePublicKeys(data []byte) ([]jwtKey, error) {
+	var keys []jwtKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key crypto.PublicKey
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+				key = cert.PublicKey
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, jwtKey{key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no public keys in PEM")
+	}
+	return keys, nil
+}
+
+// JSON Web Key (RFC 7517), only the public keys used for signatures.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func parseJWKS(data []byte) ([]jwtKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []jwtKey
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue // E.g. the key for encryption;
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys = append(keys, jwtKey{kid: k.Kid, key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys in JWKS")
+	}
+	return keys, nil
+}
+
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	b64 := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := b64(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("bad RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b64(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("bad EC key")
+		}
+		return key, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("bad Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// --- END OF FILE ---
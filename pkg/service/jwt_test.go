@@ -0,0 +1,171 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const TESTDATA_JWT_KID = "test-key-1"
+const TESTDATA_JWT_SUBJECT = "voter-42"
+
+// It writes the EC key as JWKS, and the RSA key as PEM, and returns the file names.
+func write_jwt_keys(t *testing.T, ec *ecdsa.PrivateKey, rs *rsa.PrivateKey) []string {
+	dir := t.TempDir()
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "EC", "kid": TESTDATA_JWT_KID, "use": "sig", "crv": "P-256",
+			"x": b64(ec.PublicKey.X.FillBytes(make([]byte, 32))),
+			"y": b64(ec.PublicKey.Y.FillBytes(make([]byte, 32))),
+		}},
+	})
+	jwksFile := filepath.Join(dir, "jwks.json")
+	if err := os.WriteFile(jwksFile, jwks, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	der, _ := x509.MarshalPKIXPublicKey(&rs.PublicKey)
+	pemFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return []string{jwksFile, pemFile}
+}
+
+func sign_jwt(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.Claims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func new_jwt_verifier(t *testing.T) (TokenVerifier, *ecdsa.PrivateKey, *rsa.PrivateKey) {
+	ec, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rs, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	v, err := NewJWTVerifier(write_jwt_keys(t, ec, rs), WithIssuer("test-idp"))
+	if err != nil {
+		t.Fatalf("cannot create JWT verifier, error: %v", err)
+	}
+	return v, ec, rs
+}
+
+func TestJWTVerifier(t *testing.T) {
+	testinfo := "test JWTVerifier"
+
+	v, ec, rs := new_jwt_verifier(t)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	exp := jwt.NewNumericDate(time.Now().Add(time.Hour))
+	good := jwt.RegisteredClaims{Subject: TESTDATA_JWT_SUBJECT, Issuer: "test-idp", ExpiresAt: exp}
+	expired := good
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	noExp := good
+	noExp.ExpiresAt = nil
+	noSubject := good
+	noSubject.Subject = ""
+	wrongIssuer := good
+	wrongIssuer.Issuer = "evil-idp"
+
+	var cases = []struct {
+		token string
+		ok    bool
+	}{
+		{sign_jwt(t, jwt.SigningMethodES256, ec, TESTDATA_JWT_KID, good), true},
+		{sign_jwt(t, jwt.SigningMethodES256, ec, "", good), true},         // no 'kid', all keys are tried;
+		{sign_jwt(t, jwt.SigningMethodRS256, rs, "", good), true},         // PEM key;
+		{sign_jwt(t, jwt.SigningMethodES256, ec, "unknown", good), false}, // PEM key has no 'kid', but it's RSA;
+		{sign_jwt(t, jwt.SigningMethodES256, other, TESTDATA_JWT_KID, good), false},
+		{sign_jwt(t, jwt.SigningMethodHS256, []byte("secret"), "", good), false}, // HMAC is not accepted;
+		{sign_jwt(t, jwt.SigningMethodES256, ec, TESTDATA_JWT_KID, expired), false},
+		{sign_jwt(t, jwt.SigningMethodES256, ec, TESTDATA_JWT_KID, noExp), false},
+		{sign_jwt(t, jwt.SigningMethodES256, ec, TESTDATA_JWT_KID, noSubject), false},
+		{sign_jwt(t, jwt.SigningMethodES256, ec, TESTDATA_JWT_KID, wrongIssuer), false},
+		{"not.a.token", false},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			sub, err := v.Verify(context.Background(), c.token)
+			if c.ok && (err != nil || sub != TESTDATA_JWT_SUBJECT) {
+				t.Errorf("test %v (case # %d) failed, subject %q, error: %v", testinfo, i+1, sub, err)
+			}
+			if !c.ok && err == nil {
+				t.Errorf("test %v (case # %d) failed, the token must be rejected", testinfo, i+1)
+			}
+		})
+	}
+}
+
+func TestAuthenticatedVote(t *testing.T) {
+	testinfo := "test AuthenticatedVote"
+
+	v, ec, _ := new_jwt_verifier(t)
+	store := load_store(t)
+	svc := New(store, []Middleware{}, WithTokenVerifier(v))
+	admin := WithAdmin(context.Background())
+
+	vote := new_vote(TESTDATA_NEW_VOTE_ID)
+	vote.Authenticate = true
+	if _, err := svc.CreateVote(admin, vote); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+
+	claims := jwt.RegisteredClaims{Subject: TESTDATA_JWT_SUBJECT, Issuer: "test-idp",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	voter := WithBearerToken(context.Background(), sign_jwt(t, jwt.SigningMethodES256, ec, TESTDATA_JWT_KID, claims))
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: no token;
+		_, err := svc.UpdateVoteResults(context.Background(), TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID)
+		if err != ErrUnauthorized {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrUnauthorized)
+		}
+
+		// Case 2: bad token;
+		_, err = svc.UpdateVoteResults(WithBearerToken(context.Background(), "bad"), TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID)
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("test %v (case # 2) failed, error: %v (must be %v)", testinfo, err, ErrUnauthorized)
+		}
+
+		// Case 3: good token, the voter is the token subject;
+		if _, err = svc.UpdateVoteResults(voter, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID); err != nil {
+			t.Errorf("test %v (case # 3) failed, error: %v", testinfo, err)
+		}
+		if ok, _ := store.AddVoter(context.Background(), TESTDATA_NEW_VOTE_ID, TESTDATA_JWT_SUBJECT); ok {
+			t.Errorf("test %v (case # 3) failed, the voter %q is not saved", testinfo, TESTDATA_JWT_SUBJECT)
+		}
+
+		// Case 4: the same token, other 'user_id' in the request;
+		_, err = svc.UpdateVoteResults(voter, TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID+"-2")
+		if err != ErrForbidden {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrForbidden)
+		}
+	})
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,1059 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The in-memory store is good for tests and demos, it does not need any database. It
+// mimics the behavior of the Cassandra tables 'polls.votes' and 'polls.voters' (see the
+// CQL statements in 'cassandra.go'), so the service works exactly the same way on top of it.
+// Of course, all the data is lost when the app stops.
+
+type memoryVoteStore struct {
+	mu       sync.RWMutex
+	votes    map[int]*VoteData
+	voters   map[int]map[string]time.Time   // vote_id -> user_id -> created;
+	rolls    map[int]map[string]bool        // vote_id -> entry (see 'roll.go');
+	codes    map[int]map[string]*memoryCode // vote_id -> code hash (see 'code.go');
+	ballots  map[int]map[string][]int16     // vote_id -> ballot_id -> ranking (see 'ranked.go');
+	scores   map[int]map[int16]*ScoreTally  // vote_id -> co_id -> ratings (see 'score.go');
+	choices  map[int]map[string]int16       // vote_id -> user_id -> co_id (see 'revise.go');
+	receipts map[int]map[string]Receipt     // vote_id -> receipt id (see 'receipt.go');
+	audit    map[int][]AuditEntry           // vote_id -> entries, 'Seq' is the index + 1 (see 'audit.go');
+	commits  map[int]Commitment             // vote_id -> root of the receipts (see 'commitment.go');
+	closed   map[int]bool                   // vote_id -> the receipts are sealed (see 'commitment.go');
+	sealed   map[int]map[string][]byte      // vote_id -> ballot_id -> encrypted ballot, JSON (see 'encrypted.go');
+	tallies  map[int]EncryptedTally         // vote_id -> decrypted tally (see 'encrypted.go');
+}
+
+type memoryCode struct {
+	used    bool
+	revoked bool
+}
+
+// Voter is a record of the 'voters' table.
+type Voter struct {
+	VoteId  int       `json:"vote_id"`
+	UserId  string    `json:"user_id"`
+	Created time.Time `json:"created"`
+}
+
+// MemorySeed is the content of a JSON file used to fill the in-memory store,
+// see 'testdata/votes.json' as an example.
+type MemorySeed struct {
+	Votes  []VoteData `json:"votes"`
+	Voters []Voter    `json:"voters"`
+}
+
+/////////////
+//
+// LOAD VOTE
+//
+/////////////
+
+// It returns a copy of the data, the caller can do whatever it wants with it.
+func (s *memoryVoteStore) LoadVote(_ context.Context, vote_id int) (*VoteData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.votes[vote_id]
+	if !ok || len(v.Contenders) == 0 {
+		return nil, ErrNotFound
+	}
+
+	res := *v
+	res.Contenders = append([]Contender(nil), v.Contenders...)
+	return &res, nil
+}
+
+/////////////
+//
+// ADD VOTER
+//
+/////////////
+
+// This is 'INSERT INTO polls.voters ... IF NOT EXISTS'.
+func (s *memoryVoteStore) AddVoter(_ context.Context, vote_id int, user_id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.voters[vote_id]
+	if !ok {
+		m = map[string]time.Time{}
+		s.voters[vote_id] = m
+	}
+
+	if _, ok := m[user_id]; ok {
+		return false, nil
+	}
+
+	m[user_id] = time.Now()
+	return true, nil
+}
+
+////////////////////
+//
+// INCREMENT COUNTS
+//
+////////////////////
+
+// This is the batch of 'UPDATE polls.votes SET co_count = co_count + 1 ... IF EXISTS',
+// the contenders are checked first, so nothing is counted if any of them is missing.
+func (s *memoryVoteStore) IncrementCounts(_ context.Context, vote_id int, co_ids []int16) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.votes[vote_id]
+	if !ok {
+		return false, nil
+	}
+
+	for _, co_id := range co_ids {
+		if v.findContender(co_id) == nil {
+			return false, nil
+		}
+	}
+
+	now := time.Now()
+	for _, co_id := range co_ids {
+		c := v.findContender(co_id)
+		c.Count++
+		c.Updated = now
+	}
+	return true, nil
+}
+
+////////////////
+//
+// REMOVE VOTER
+//
+////////////////
+
+// This is 'DELETE FROM polls.voters ...', it's not an error if there is no such voter.
+func (s *memoryVoteStore) RemoveVoter(_ context.Context, vote_id int, user_id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.voters[vote_id], user_id)
+	delete(s.choices[vote_id], user_id)
+	return nil
+}
+
+////////
+//
+// PING
+//
+////////
+
+func (s *memoryVoteStore) Ping(_ context.Context) error {
+	return nil
+}
+
+///////////////
+//
+// CREATE VOTE
+//
+///////////////
+
+func (s *memoryVoteStore) CreateVote(_ context.Context, vote *VoteData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote.VoteId]; ok {
+		return ErrConflict
+	}
+
+	v := *vote
+	v.Contenders = append([]Contender(nil), vote.Contenders...)
+	s.votes[v.VoteId] = &v
+	return nil
+}
+
+///////////////
+//
+// UPDATE VOTE
+//
+///////////////
+
+// The contenders (and their counts) are not changed.
+func (s *memoryVoteStore) UpdateVote(_ context.Context, vote *VoteData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.votes[vote.VoteId]
+	if !ok {
+		return ErrNotFound
+	}
+
+	contenders := v.Contenders
+	*v = *vote
+	v.Contenders = contenders
+	return nil
+}
+
+///////////////
+//
+// DELETE VOTE
+//
+///////////////
+
+func (s *memoryVoteStore) DeleteVote(_ context.Context, vote_id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.votes, vote_id)
+	delete(s.voters, vote_id)
+	delete(s.rolls, vote_id)
+	delete(s.codes, vote_id)
+	delete(s.ballots, vote_id)
+	delete(s.scores, vote_id)
+	delete(s.choices, vote_id)
+	delete(s.receipts, vote_id)
+	delete(s.audit, vote_id)
+	delete(s.commits, vote_id)
+	delete(s.closed, vote_id)
+	delete(s.sealed, vote_id)
+	delete(s.tallies, vote_id)
+	return nil
+}
+
+/////////////////
+//
+// ADD CONTENDER
+//
+/////////////////
+
+func (s *memoryVoteStore) AddContender(_ context.Context, vote_id int, contender *Contender) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.votes[vote_id]
+	if !ok {
+		return ErrNotFound
+	}
+	if v.findContender(contender.Id) != nil {
+		return ErrConflict
+	}
+
+	v.Contenders = append(v.Contenders, *contender)
+	sort.Slice(v.Contenders, func(i, j int) bool { return v.Contenders[i].Id < v.Contenders[j].Id })
+	return nil
+}
+
+////////////////////
+//
+// UPDATE CONTENDER
+//
+////////////////////
+
+// The count is not changed, it's changed by voting only.
+func (s *memoryVoteStore) UpdateContender(_ context.Context, vote_id int, contender *Contender) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.votes[vote_id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	c := v.findContender(contender.Id)
+	if c == nil {
+		return ErrNotFound
+	}
+
+	count, updated := c.Count, c.Updated
+	*c = *contender
+	c.Count, c.Updated = count, updated
+	return nil
+}
+
+////////////
+//
+// SET ROLL
+//
+////////////
+
+func (s *memoryVoteStore) SetRoll(_ context.Context, vote_id int, entries []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return ErrNotFound
+	}
+
+	if len(entries) == 0 {
+		delete(s.rolls, vote_id)
+		return nil
+	}
+
+	m := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		m[e] = true
+	}
+	s.rolls[vote_id] = m
+	return nil
+}
+
+//////////////
+//
+// CHECK ROLL
+//
+//////////////
+
+func (s *memoryVoteStore) CheckRoll(_ context.Context, vote_id int, entry string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.rolls[vote_id]
+	return !ok || m[entry], nil
+}
+
+//////////////
+//
+// ROLL STATS
+//
+//////////////
+
+func (s *memoryVoteStore) RollStats(_ context.Context, vote_id int) (*RollStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m := s.rolls[vote_id]
+	stats := RollStats{Size: int64(len(m))}
+	if len(m) == 0 {
+		return &stats, nil
+	}
+
+	for user_id := range s.voters[vote_id] {
+		if m[rollEntry(user_id)] {
+			stats.Voted++
+		}
+	}
+	return &stats, nil
+}
+
+/////////////
+//
+// ADD CODES
+//
+/////////////
+
+func (s *memoryVoteStore) AddCodes(_ context.Context, vote_id int, codes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return ErrNotFound
+	}
+
+	m, ok := s.codes[vote_id]
+	if !ok {
+		m = map[string]*memoryCode{}
+		s.codes[vote_id] = m
+	}
+
+	for _, c := range codes {
+		if _, ok := m[c]; ok {
+			return ErrConflict
+		}
+	}
+	for _, c := range codes {
+		m[c] = &memoryCode{}
+	}
+	return nil
+}
+
+/////////////
+//
+// BURN CODE
+//
+/////////////
+
+func (s *memoryVoteStore) BurnCode(_ context.Context, vote_id int, code string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.codes[vote_id][code]
+	if !ok || c.used || c.revoked {
+		return false, nil
+	}
+
+	c.used = true
+	return true, nil
+}
+
+////////////////
+//
+// RESTORE CODE
+//
+////////////////
+
+func (s *memoryVoteStore) RestoreCode(_ context.Context, vote_id int, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.codes[vote_id][code]; ok {
+		c.used = false
+	}
+	return nil
+}
+
+////////////////
+//
+// REVOKE CODES
+//
+////////////////
+
+func (s *memoryVoteStore) RevokeCodes(_ context.Context, vote_id int, codes []string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.codes[vote_id]
+	if codes == nil {
+		for c := range m {
+			codes = append(codes, c)
+		}
+	}
+
+	var n int64
+	for _, code := range codes {
+		if c, ok := m[code]; ok && !c.used && !c.revoked {
+			c.revoked = true
+			n++
+		}
+	}
+	return n, nil
+}
+
+//////////////
+//
+// ADD BALLOT
+//
+//////////////
+
+func (s *memoryVoteStore) AddBallot(_ context.Context, vote_id int, ballot_id string, ranking []int16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return ErrNotFound
+	}
+
+	m, ok := s.ballots[vote_id]
+	if !ok {
+		m = map[string][]int16{}
+		s.ballots[vote_id] = m
+	}
+
+	if _, ok := m[ballot_id]; ok {
+		return ErrConflict
+	}
+	m[ballot_id] = append([]int16(nil), ranking...)
+	return nil
+}
+
+/////////////////
+//
+// REMOVE BALLOT
+//
+/////////////////
+
+func (s *memoryVoteStore) RemoveBallot(_ context.Context, vote_id int, ballot_id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.ballots[vote_id], ballot_id)
+	return nil
+}
+
+////////////////
+//
+// LOAD BALLOTS
+//
+////////////////
+
+func (s *memoryVoteStore) LoadBallots(_ context.Context, vote_id int) ([][]int16, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res [][]int16
+	for _, r := range s.ballots[vote_id] {
+		res = append(res, append([]int16(nil), r...))
+	}
+	return res, nil
+}
+
+//////////////
+//
+// ADD SCORES
+//
+//////////////
+
+// This is the counter batch of 'polls.scores' (see 'cassandra.go'), the counts of the
+// contenders are not changed. The contenders are checked first, as by 'IncrementCounts'.
+func (s *memoryVoteStore) AddScores(_ context.Context, vote_id int, scores map[int16]int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.votes[vote_id]
+	if !ok {
+		return false, nil
+	}
+
+	for co_id := range scores {
+		if v.findContender(co_id) == nil {
+			return false, nil
+		}
+	}
+
+	m, ok := s.scores[vote_id]
+	if !ok {
+		m = map[int16]*ScoreTally{}
+		s.scores[vote_id] = m
+	}
+
+	now := time.Now()
+	for co_id, score := range scores {
+		t, ok := m[co_id]
+		if !ok {
+			t = &ScoreTally{Ratings: map[int]int64{}}
+			m[co_id] = t
+		}
+		t.Sum += int64(score)
+		t.Count++
+		t.Ratings[score]++
+		v.findContender(co_id).Updated = now
+	}
+	return true, nil
+}
+
+///////////////
+//
+// LOAD SCORES
+//
+///////////////
+
+func (s *memoryVoteStore) LoadScores(_ context.Context, vote_id int) (map[int16]*ScoreTally, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := map[int16]*ScoreTally{}
+	for co_id, t := range s.scores[vote_id] {
+		r := &ScoreTally{Sum: t.Sum, Count: t.Count, Ratings: map[int]int64{}}
+		for score, n := range t.Ratings {
+			r.Ratings[score] = n
+		}
+		res[co_id] = r
+	}
+	return res, nil
+}
+
+///////////////
+//
+// LOAD CHOICE
+//
+///////////////
+
+// The choice is a column of 'polls.voters', so there is no choice without the voter.
+func (s *memoryVoteStore) LoadChoice(_ context.Context, vote_id int, user_id string) (int16, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.voters[vote_id][user_id]; !ok {
+		return 0, ErrNotFound
+	}
+	return s.choices[vote_id][user_id], nil
+}
+
+/////////////////
+//
+// CHANGE CHOICE
+//
+/////////////////
+
+// This is 'INSERT INTO polls.voters ... IF NOT EXISTS' (0 'from'), 'DELETE ... IF co_id = ?'
+// (0 'to'), or 'UPDATE ... IF co_id = ?'.
+func (s *memoryVoteStore) ChangeChoice(_ context.Context, vote_id int, user_id string, from, to int16) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, voted := s.voters[vote_id][user_id]
+	if from == 0 {
+		if voted {
+			return false, nil
+		}
+		if _, ok := s.voters[vote_id]; !ok {
+			s.voters[vote_id] = map[string]time.Time{}
+		}
+		s.voters[vote_id][user_id] = time.Now()
+	} else if !voted || s.choices[vote_id][user_id] != from {
+		return false, nil
+	}
+
+	if to == 0 {
+		delete(s.voters[vote_id], user_id)
+		delete(s.choices[vote_id], user_id)
+		return true, nil
+	}
+
+	if _, ok := s.choices[vote_id]; !ok {
+		s.choices[vote_id] = map[string]int16{}
+	}
+	s.choices[vote_id][user_id] = to
+	return true, nil
+}
+
+//////////////
+//
+// MOVE COUNT
+//
+//////////////
+
+// This is the batch of 'UPDATE polls.votes SET co_count = co_count - 1 ...' and '+ 1', see 'IncrementCounts'.
+func (s *memoryVoteStore) MoveCount(_ context.Context, vote_id int, from, to int16) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.votes[vote_id]
+	if !ok {
+		return false, nil
+	}
+
+	for _, co_id := range []int16{from, to} {
+		if co_id != 0 && v.findContender(co_id) == nil {
+			return false, nil
+		}
+	}
+
+	now := time.Now()
+	if from != 0 {
+		c := v.findContender(from)
+		c.Count--
+		c.Updated = now
+	}
+	if to != 0 {
+		c := v.findContender(to)
+		c.Count++
+		c.Updated = now
+	}
+	return true, nil
+}
+
+///////////////
+//
+// ADD RECEIPT
+//
+///////////////
+
+func (s *memoryVoteStore) AddReceipt(_ context.Context, vote_id int, receipt *Receipt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return ErrNotFound
+	}
+	if s.closed[vote_id] {
+		return ErrClosed
+	}
+
+	m, ok := s.receipts[vote_id]
+	if !ok {
+		m = map[string]Receipt{}
+		s.receipts[vote_id] = m
+	}
+
+	if _, ok := m[receipt.Id]; ok {
+		return ErrConflict
+	}
+	m[receipt.Id] = *receipt
+	return nil
+}
+
+//////////////////
+//
+// REMOVE RECEIPT
+//
+//////////////////
+
+func (s *memoryVoteStore) RemoveReceipt(_ context.Context, vote_id int, receipt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.receipts[vote_id], receipt)
+	return nil
+}
+
+////////////////
+//
+// LOAD RECEIPT
+//
+////////////////
+
+func (s *memoryVoteStore) LoadReceipt(_ context.Context, vote_id int, receipt string) (*Receipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.receipts[vote_id][receipt]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &r, nil
+}
+
+/////////////////
+//
+// LOAD RECEIPTS
+//
+/////////////////
+
+func (s *memoryVoteStore) LoadReceipts(_ context.Context, vote_id int) ([]*Receipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]*Receipt, 0, len(s.receipts[vote_id]))
+	for _, r := range s.receipts[vote_id] {
+		res = append(res, &Receipt{Id: r.Id, VoteId: r.VoteId, CastAt: r.CastAt})
+	}
+	return res, nil
+}
+
+/////////////////
+//
+// SEAL RECEIPTS
+//
+/////////////////
+
+func (s *memoryVoteStore) SealReceipts(_ context.Context, vote_id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return ErrNotFound
+	}
+	s.closed[vote_id] = true
+	return nil
+}
+
+///////////////////
+//
+// SAVE COMMITMENT
+//
+///////////////////
+
+func (s *memoryVoteStore) SaveCommitment(_ context.Context, vote_id int, commitment *Commitment) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return false, ErrNotFound
+	}
+	if _, ok := s.commits[vote_id]; ok {
+		return false, nil
+	}
+	s.commits[vote_id] = *commitment
+	return true, nil
+}
+
+///////////////////
+//
+// LOAD COMMITMENT
+//
+///////////////////
+
+func (s *memoryVoteStore) LoadCommitment(_ context.Context, vote_id int) (*Commitment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.commits[vote_id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+/////////////////////
+//
+// REMOVE COMMITMENT
+//
+/////////////////////
+
+func (s *memoryVoteStore) RemoveCommitment(_ context.Context, vote_id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.commits, vote_id)
+	delete(s.closed, vote_id)
+	return nil
+}
+
+////////////////
+//
+// APPEND AUDIT
+//
+////////////////
+
+func (s *memoryVoteStore) AppendAudit(_ context.Context, vote_id int, entry *AuditEntry) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return false, ErrNotFound
+	}
+
+	if entry.Seq != int64(len(s.audit[vote_id]))+1 {
+		return false, nil
+	}
+	s.audit[vote_id] = append(s.audit[vote_id], *entry)
+	return true, nil
+}
+
+///////////////////
+//
+// LOAD AUDIT HEAD
+//
+///////////////////
+
+func (s *memoryVoteStore) LoadAuditHead(_ context.Context, vote_id int) (*AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	log := s.audit[vote_id]
+	if len(log) == 0 {
+		return nil, ErrNotFound
+	}
+	e := log[len(log)-1]
+	return &e, nil
+}
+
+//////////////
+//
+// LOAD AUDIT
+//
+//////////////
+
+func (s *memoryVoteStore) LoadAudit(_ context.Context, vote_id int) ([]*AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]*AuditEntry, len(s.audit[vote_id]))
+	for i := range s.audit[vote_id] {
+		e := s.audit[vote_id][i]
+		res[i] = &e
+	}
+	return res, nil
+}
+
+////////////////////////
+//
+// ADD ENCRYPTED BALLOT
+//
+////////////////////////
+
+// The ballot is kept as JSON, as in the other stores, so the caller cannot change it later.
+func (s *memoryVoteStore) AddEncryptedBallot(_ context.Context, vote_id int, ballot_id string, ballot *EncryptedBallot) error {
+	data, err := json.Marshal(ballot)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return ErrNotFound
+	}
+
+	m, ok := s.sealed[vote_id]
+	if !ok {
+		m = map[string][]byte{}
+		s.sealed[vote_id] = m
+	}
+
+	if _, ok := m[ballot_id]; ok {
+		return ErrConflict
+	}
+	m[ballot_id] = data
+	return nil
+}
+
+///////////////////////////
+//
+// REMOVE ENCRYPTED BALLOT
+//
+///////////////////////////
+
+func (s *memoryVoteStore) RemoveEncryptedBallot(_ context.Context, vote_id int, ballot_id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sealed[vote_id], ballot_id)
+	return nil
+}
+
+//////////////////////////
+//
+// LOAD ENCRYPTED BALLOTS
+//
+//////////////////////////
+
+func (s *memoryVoteStore) LoadEncryptedBallots(_ context.Context, vote_id int) ([]*EncryptedBallot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]*EncryptedBallot, 0, len(s.sealed[vote_id]))
+	for _, data := range s.sealed[vote_id] {
+		var ballot EncryptedBallot
+		if err := json.Unmarshal(data, &ballot); err != nil {
+			return nil, err
+		}
+		res = append(res, &ballot)
+	}
+	return res, nil
+}
+
+//////////////
+//
+// SAVE TALLY
+//
+//////////////
+
+func (s *memoryVoteStore) SaveTally(_ context.Context, vote_id int, tally *EncryptedTally) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[vote_id]; !ok {
+		return false, ErrNotFound
+	}
+	if _, ok := s.tallies[vote_id]; ok {
+		return false, nil
+	}
+	s.tallies[vote_id] = *tally
+	return true, nil
+}
+
+//////////////
+//
+// LOAD TALLY
+//
+//////////////
+
+func (s *memoryVoteStore) LoadTally(_ context.Context, vote_id int) (*EncryptedTally, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tallies[vote_id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t.Contenders = append([]TallyContender(nil), t.Contenders...)
+	return &t, nil
+}
+
+////////////////
+//
+// REMOVE TALLY
+//
+////////////////
+
+func (s *memoryVoteStore) RemoveTally(_ context.Context, vote_id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tallies, vote_id)
+	return nil
+}
+
+////////
+//
+// SEED
+//
+////////
+
+// It adds (or replaces) votes and voters, and it's not supposed to be used
+// when the service is already running.
+func (s *memoryVoteStore) seed(data *MemorySeed) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range data.Votes {
+		v := data.Votes[i]
+		if v.VoteId == 0 {
+			return ErrBadRequest
+		}
+		v.Contenders = append([]Contender(nil), v.Contenders...)
+		s.votes[v.VoteId] = &v
+	}
+
+	for _, r := range data.Voters {
+		m, ok := s.voters[r.VoteId]
+		if !ok {
+			m = map[string]time.Time{}
+			s.voters[r.VoteId] = m
+		}
+		m[r.UserId] = r.Created
+	}
+
+	return nil
+}
+
+/////////////////////////
+//
+// NEW MEMORY VOTE STORE
+//
+/////////////////////////
+
+// NewMemoryVoteStore returns an empty VoteStore keeping the data in memory.
+func NewMemoryVoteStore() VoteStore {
+	return &memoryVoteStore{
+		votes:    map[int]*VoteData{},
+		voters:   map[int]map[string]time.Time{},
+		rolls:    map[int]map[string]bool{},
+		codes:    map[int]map[string]*memoryCode{},
+		ballots:  map[int]map[string][]int16{},
+		scores:   map[int]map[int16]*ScoreTally{},
+		choices:  map[int]map[string]int16{},
+		receipts: map[int]map[string]Receipt{},
+		audit:    map[int][]AuditEntry{},
+		commits:  map[int]Commitment{},
+		closed:   map[int]bool{},
+		sealed:   map[int]map[string][]byte{},
+		tallies:  map[int]EncryptedTally{},
+	}
+}
+
+//////////////////////////
+//
+// LOAD MEMORY VOTE STORE
+//
+//////////////////////////
+
+// LoadMemoryVoteStore returns a VoteStore keeping the data in memory,
+// and filled with the data from the JSON file (see 'MemorySeed').
+func LoadMemoryVoteStore(filename string) (VoteStore, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var seed MemorySeed
+	if err = json.Unmarshal(data, &seed); err != nil {
+		return nil, err
+	}
+
+	s := NewMemoryVoteStore().(*memoryVoteStore)
+	if err = s.seed(&seed); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,77 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryStoreSeed(t *testing.T) {
+	testinfo := "test MemoryStoreSeed"
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the file does not exist;
+		_, err := LoadMemoryVoteStore(TESTDATA_DIR + "no_such_file.json")
+		if err == nil {
+			t.Errorf("test %v (case # 1) failed, error is nil", testinfo)
+		}
+
+		// Case 2: the data returned by the store is a copy, changes must not affect the store;
+		store := load_store(t)
+		vote, err := store.LoadVote(context.Background(), 1)
+		if err != nil {
+			t.Errorf("test %v (case # 2) failed, error: %v", testinfo, err)
+			return
+		}
+		vote.Contenders[0].Count = -1
+
+		co_count, _ := get_co_count(store, 1, vote.Contenders[0].Id)
+		if co_count == -1 {
+			t.Errorf("test %v (case # 2) failed, the store data was modified", testinfo)
+		}
+	})
+}
+
+func TestMemoryStoreConcurrentVotes(t *testing.T) {
+	testinfo := "test MemoryStoreConcurrentVotes"
+	const voters = 200
+	vote_id := 1
+	var co_id int16 = 2
+	store := load_store(t)
+
+	svc := New(store, []Middleware{})
+
+	old_co_count, err := get_co_count(store, vote_id, co_id)
+	if err != nil {
+		t.Errorf("test %v failed, cannot get current co_count, error: %v", testinfo, err)
+		return
+	}
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Every voter tries to vote twice, only the first vote must be counted;
+		var wg sync.WaitGroup
+		for i := 0; i < voters; i++ {
+			user_id := uuid.NewString()
+			for j := 0; j < 2; j++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					svc.UpdateVoteResults(context.Background(), vote_id, co_id, user_id)
+				}()
+			}
+		}
+		wg.Wait()
+
+		co_count, _ := get_co_count(store, vote_id, co_id)
+		if co_count != old_co_count+voters {
+			t.Errorf("test %v failed, co_count = %v, but it should be %v", testinfo, co_count, old_co_count+voters)
+		}
+	})
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,45 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+// The voting method of the poll decides what the ballot is and how it's counted:
+//
+//	plurality - one contender, or up to 'MaxChoices' of them (approval voting), the counts are the results;
+//	ranked    - the contenders in the order of preference, the ballots are saved and counted by instant runoff (see 'ranked.go');
+//	condorcet - the same ballots as ranked, but they are counted by pairs of contenders, and the winner is found by the Schulze method (see 'condorcet.go');
+//	score     - each contender is rated on the scale of the poll, the results are the statistics of the ratings (see 'score.go').
+//
+// The polls created before the methods were introduced have no method (empty string), they are plurality polls.
+
+const VOTE_METHOD_PLURALITY = "plurality"
+const VOTE_METHOD_RANKED = "ranked"
+const VOTE_METHOD_CONDORCET = "condorcet"
+const VOTE_METHOD_SCORE = "score"
+
+func isVoteMethod(method string) bool {
+	switch method {
+	case "", VOTE_METHOD_PLURALITY, VOTE_METHOD_RANKED, VOTE_METHOD_CONDORCET, VOTE_METHOD_SCORE:
+		return true
+	}
+	return false
+}
+
+// It returns the max number of contenders in one ballot: 'MaxChoices', or one for plurality
+// polls and all the contenders for ranked (and Condorcet) polls if it's 0.
+func (v *VoteData) maxChoices() int {
+	if v.MaxChoices > 0 {
+		return v.MaxChoices
+	}
+	if v.keepsBallots() {
+		return len(v.Contenders)
+	}
+	return 1
+}
+
+// It reports whether the ballots of the poll are saved (see 'VoteStore.AddBallot').
+func (v *VoteData) keepsBallots() bool {
+	return v.Method == VOTE_METHOD_RANKED || v.Method == VOTE_METHOD_CONDORCET
+}
+
+// --- END OF FILE ---
@@ -1,5 +1,5 @@
 //  Created : 2024-Mar-14
-// Modified : 2024-Mar-27
+// Modified : 2026-Oct-18
 
 package service
 
@@ -33,22 +33,77 @@ func (l loggingMiddleware) GetVoteData(ctx context.Context, vote_id int) (v0 *Vo
 	return l.next.GetVoteData(ctx, vote_id)
 }
 
-/*
-func (l loggingMiddleware) GetVoteResults(ctx context.Context, vote_id int) (v0 *VoteData, e1 error) {
+func (l loggingMiddleware) GetVoteResults(ctx context.Context, vote_id int) (v0 *VoteData, err error) {
 	defer func() {
-		l.logger.Log("method", "GetVoteResults", "vote_id", vote_id, "v0", v0, "e1", e1)
+		l.logger.Log("method", "GetVoteResults", "vote_id", vote_id, "v0", v0, "err", err)
 	}()
 	return l.next.GetVoteResults(ctx, vote_id)
 }
-*/
 
-func (l loggingMiddleware) UpdateVoteResults(ctx context.Context, vote_id int, co_id int16, user_id string) (e0 error) {
+func (l loggingMiddleware) UpdateVoteResults(ctx context.Context, vote_id int, co_id int16, user_id string) (r0 *Receipt, e1 error) {
 	defer func() {
-		l.logger.Log("method", "UpdateVoteResults", "vote_id", vote_id, "co_id", co_id, "user_id", user_id, "e0", e0)
+		l.logger.Log("method", "UpdateVoteResults", "vote_id", vote_id, "co_id", co_id, "user_id", user_id, "e1", e1)
 	}()
 	return l.next.UpdateVoteResults(ctx, vote_id, co_id, user_id)
 }
 
+func (l loggingMiddleware) CastBallot(ctx context.Context, vote_id int, co_ids []int16, user_id string) (r0 *Receipt, e1 error) {
+	defer func() {
+		l.logger.Log("method", "CastBallot", "vote_id", vote_id, "co_ids", co_ids, "user_id", user_id, "e1", e1)
+	}()
+	return l.next.CastBallot(ctx, vote_id, co_ids, user_id)
+}
+
+func (l loggingMiddleware) RateContenders(ctx context.Context, vote_id int, scores map[int16]int, user_id string) (r0 *Receipt, e1 error) {
+	defer func() {
+		l.logger.Log("method", "RateContenders", "vote_id", vote_id, "scores", scores, "user_id", user_id, "e1", e1)
+	}()
+	return l.next.RateContenders(ctx, vote_id, scores, user_id)
+}
+
+// The ciphertexts are not logged, only their number.
+func (l loggingMiddleware) CastEncryptedBallot(ctx context.Context, vote_id int, ballot EncryptedBallot, user_id string) (r0 *Receipt, e1 error) {
+	defer func() {
+		l.logger.Log("method", "CastEncryptedBallot", "vote_id", vote_id, "choices", len(ballot.Choices), "user_id", user_id, "e1", e1)
+	}()
+	return l.next.CastEncryptedBallot(ctx, vote_id, ballot, user_id)
+}
+
+func (l loggingMiddleware) RetractVote(ctx context.Context, vote_id int, user_id string) (e0 error) {
+	defer func() {
+		l.logger.Log("method", "RetractVote", "vote_id", vote_id, "user_id", user_id, "e0", e0)
+	}()
+	return l.next.RetractVote(ctx, vote_id, user_id)
+}
+
+func (l loggingMiddleware) GetReceipt(ctx context.Context, vote_id int, receipt string) (r0 *Receipt, e1 error) {
+	defer func() {
+		l.logger.Log("method", "GetReceipt", "vote_id", vote_id, "e1", e1) // The receipt is a secret of the voter;
+	}()
+	return l.next.GetReceipt(ctx, vote_id, receipt)
+}
+
+func (l loggingMiddleware) GetAuditHead(ctx context.Context, vote_id int) (r0 *AuditHead, e1 error) {
+	defer func() {
+		l.logger.Log("method", "GetAuditHead", "vote_id", vote_id, "r0", r0, "e1", e1)
+	}()
+	return l.next.GetAuditHead(ctx, vote_id)
+}
+
+func (l loggingMiddleware) GetCommitment(ctx context.Context, vote_id int) (r0 *Commitment, e1 error) {
+	defer func() {
+		l.logger.Log("method", "GetCommitment", "vote_id", vote_id, "r0", r0, "e1", e1)
+	}()
+	return l.next.GetCommitment(ctx, vote_id)
+}
+
+func (l loggingMiddleware) GetReceiptProof(ctx context.Context, vote_id int, receipt string) (r0 *ReceiptProof, e1 error) {
+	defer func() {
+		l.logger.Log("method", "GetReceiptProof", "vote_id", vote_id, "e1", e1) // The receipt is a secret of the voter;
+	}()
+	return l.next.GetReceiptProof(ctx, vote_id, receipt)
+}
+
 func (l loggingMiddleware) GetServiceStatus(ctx context.Context) (v0 *HealthStatus) {
 	defer func() {
 		l.logger.Log("method", "GetServiceStatus", "v0", v0)
@@ -56,4 +111,83 @@ func (l loggingMiddleware) GetServiceStatus(ctx context.Context) (v0 *HealthStat
 	return l.next.GetServiceStatus(ctx)
 }
 
+func (l loggingMiddleware) CreateVote(ctx context.Context, vote VoteData) (v0 *VoteData, err error) {
+	defer func() {
+		l.logger.Log("method", "CreateVote", "vote_id", vote.VoteId, "err", err)
+	}()
+	return l.next.CreateVote(ctx, vote)
+}
+
+func (l loggingMiddleware) UpdateVote(ctx context.Context, vote_id int, patch VotePatch) (v0 *VoteData, err error) {
+	defer func() {
+		l.logger.Log("method", "UpdateVote", "vote_id", vote_id, "err", err)
+	}()
+	return l.next.UpdateVote(ctx, vote_id, patch)
+}
+
+func (l loggingMiddleware) DeleteVote(ctx context.Context, vote_id int) (e0 error) {
+	defer func() {
+		l.logger.Log("method", "DeleteVote", "vote_id", vote_id, "e0", e0)
+	}()
+	return l.next.DeleteVote(ctx, vote_id)
+}
+
+func (l loggingMiddleware) CloseVote(ctx context.Context, vote_id int) (v0 *VoteData, err error) {
+	defer func() {
+		l.logger.Log("method", "CloseVote", "vote_id", vote_id, "err", err)
+	}()
+	return l.next.CloseVote(ctx, vote_id)
+}
+
+func (l loggingMiddleware) ReopenVote(ctx context.Context, vote_id int) (v0 *VoteData, err error) {
+	defer func() {
+		l.logger.Log("method", "ReopenVote", "vote_id", vote_id, "err", err)
+	}()
+	return l.next.ReopenVote(ctx, vote_id)
+}
+
+func (l loggingMiddleware) AddContender(ctx context.Context, vote_id int, contender Contender) (v0 *VoteData, err error) {
+	defer func() {
+		l.logger.Log("method", "AddContender", "vote_id", vote_id, "co_id", contender.Id, "err", err)
+	}()
+	return l.next.AddContender(ctx, vote_id, contender)
+}
+
+func (l loggingMiddleware) UpdateContender(ctx context.Context, vote_id int, co_id int16, patch ContenderPatch) (v0 *VoteData, err error) {
+	defer func() {
+		l.logger.Log("method", "UpdateContender", "vote_id", vote_id, "co_id", co_id, "err", err)
+	}()
+	return l.next.UpdateContender(ctx, vote_id, co_id, patch)
+}
+
+func (l loggingMiddleware) WithdrawContender(ctx context.Context, vote_id int, co_id int16) (v0 *VoteData, err error) {
+	defer func() {
+		l.logger.Log("method", "WithdrawContender", "vote_id", vote_id, "co_id", co_id, "err", err)
+	}()
+	return l.next.WithdrawContender(ctx, vote_id, co_id)
+}
+
+// The entries are not logged, only their number.
+func (l loggingMiddleware) SetRoll(ctx context.Context, vote_id int, entries []string) (v0 *RollStats, err error) {
+	defer func() {
+		l.logger.Log("method", "SetRoll", "vote_id", vote_id, "entries", len(entries), "v0", v0, "err", err)
+	}()
+	return l.next.SetRoll(ctx, vote_id, entries)
+}
+
+// The codes are not logged, only their number.
+func (l loggingMiddleware) GenerateCodes(ctx context.Context, vote_id int, n int) (v0 []string, err error) {
+	defer func() {
+		l.logger.Log("method", "GenerateCodes", "vote_id", vote_id, "n", n, "v0", len(v0), "err", err)
+	}()
+	return l.next.GenerateCodes(ctx, vote_id, n)
+}
+
+func (l loggingMiddleware) RevokeCodes(ctx context.Context, vote_id int, r CodeRevocation) (v0 int64, err error) {
+	defer func() {
+		l.logger.Log("method", "RevokeCodes", "vote_id", vote_id, "codes", len(r.Codes), "all", r.All, "v0", v0, "err", err)
+	}()
+	return l.next.RevokeCodes(ctx, vote_id, r)
+}
+
 // --- END OF FILE ---
@@ -0,0 +1,188 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQL schema migrations are embedded in the binary, there is a separate set of them for each
+// SQL driver (see 'migrations/sqlite', 'migrations/postgres'). The file name is a version
+// number and a short description, e.g. '0001_init.sql'; once released, the file must never
+// be changed, the next change is a new file with the next version number. Applied versions
+// are recorded in the 'schema_migrations' table.
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration is a single step of the database schema change.
+type Migration struct {
+	Version int
+	Name    string
+	stmt    string
+}
+
+const createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied TIMESTAMP NOT NULL
+)`
+
+//////////////////
+//
+// GET MIGRATIONS
+//
+//////////////////
+
+// It returns all migrations available for the driver, ordered by version.
+func getMigrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for SQL driver %q", driver)
+	}
+
+	var res []Migration
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("bad migration file name %q", name)
+		}
+
+		data, err := migrationFiles.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, Migration{Version: version, Name: strings.TrimSuffix(name, ".sql"), stmt: string(data)})
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	for i := 1; i < len(res); i++ {
+		if res[i].Version == res[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", res[i].Version)
+		}
+	}
+
+	return res, nil
+}
+
+//////////////////////
+//
+// PENDING MIGRATIONS
+//
+//////////////////////
+
+// It returns migrations which are not applied yet.
+func pendingMigrations(ctx context.Context, db *sql.DB, driver string) ([]Migration, error) {
+	all, err := getMigrations(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = db.ExecContext(ctx, createSchemaMigrations); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var v int
+		if err = rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		applied[v] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var res []Migration
+	for _, m := range all {
+		if !applied[m.Version] {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+///////////
+//
+// MIGRATE
+//
+///////////
+
+// It applies all pending migrations, each one in its own transaction,
+// and returns the list of migrations applied.
+func migrate(ctx context.Context, db *sql.DB, driver string) ([]Migration, error) {
+	pending, err := pendingMigrations(ctx, db, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range pending {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return done, err
+		}
+
+		if _, err = tx.ExecContext(ctx, m.stmt); err != nil {
+			tx.Rollback()
+			return done, fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+
+		stmt := rebind(driver, "INSERT INTO schema_migrations (version, name, applied) VALUES(?, ?, ?)")
+		if _, err = tx.ExecContext(ctx, stmt, m.Version, m.Name, time.Now().UTC()); err != nil {
+			tx.Rollback()
+			return done, fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+
+		if err = tx.Commit(); err != nil {
+			return done, fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+//////////////////////////
+//
+// MIGRATE SQL VOTE STORE
+//
+//////////////////////////
+
+// MigrateSQLVoteStore brings the database schema up to date, it's what 'vote-svc migrate'
+// does. It returns the list of migrations applied, it's empty if there was nothing to do.
+func MigrateSQLVoteStore(ctx context.Context, driver string, dsn string) ([]Migration, error) {
+	db, err := openSQLDatabase(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return migrate(ctx, db, driver)
+}
+
+// --- END OF FILE ---
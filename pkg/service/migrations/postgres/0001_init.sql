@@ -0,0 +1,30 @@
+-- Polls, contenders and voters (see 'pkg/service/sql.go').
+
+CREATE TABLE IF NOT EXISTS polls (
+  vote_id INTEGER PRIMARY KEY,
+  header TEXT NOT NULL DEFAULT '',
+  message TEXT NOT NULL DEFAULT '',
+  resources TEXT NOT NULL DEFAULT '',
+  deadline TIMESTAMPTZ NOT NULL,
+  authenticate BOOLEAN NOT NULL DEFAULT FALSE,
+  allowresults BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS contenders (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  co_id SMALLINT NOT NULL,
+  co_name TEXT NOT NULL DEFAULT '',
+  co_alias TEXT NOT NULL DEFAULT '',
+  co_info TEXT NOT NULL DEFAULT '',
+  co_picture TEXT NOT NULL DEFAULT '',
+  co_count BIGINT NOT NULL DEFAULT 0,
+  co_updated TIMESTAMPTZ,
+  PRIMARY KEY (vote_id, co_id)
+);
+
+CREATE TABLE IF NOT EXISTS voters (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  user_id TEXT NOT NULL,
+  created TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (vote_id, user_id)
+);
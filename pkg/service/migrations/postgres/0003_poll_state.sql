@@ -0,0 +1,4 @@
+-- The poll lifecycle (see 'pkg/service/state.go'), the empty state is an open poll.
+
+ALTER TABLE polls ADD COLUMN state TEXT NOT NULL DEFAULT '';
+ALTER TABLE polls ADD COLUMN opens_at TIMESTAMP;
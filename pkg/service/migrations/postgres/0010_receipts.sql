@@ -0,0 +1,10 @@
+-- Receipts of the votes (see 'pkg/service/receipt.go'), the receipt is a UUID. The voter
+-- is saved for the revisable polls only, it's empty for the others.
+
+CREATE TABLE IF NOT EXISTS receipts (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  receipt TEXT NOT NULL,
+  cast_at TIMESTAMPTZ NOT NULL,
+  user_id TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (vote_id, receipt)
+);
@@ -0,0 +1,13 @@
+-- Audit log of the votes (see 'pkg/service/audit.go'): the entries are numbered from 1, and
+-- each one has the hash of the previous one. The counts are "co_id:delta" separated by commas.
+
+CREATE TABLE IF NOT EXISTS audit (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  seq BIGINT NOT NULL,
+  at TIMESTAMPTZ NOT NULL,
+  action TEXT NOT NULL,
+  counts TEXT NOT NULL,
+  prev TEXT NOT NULL,
+  hash TEXT NOT NULL,
+  PRIMARY KEY (vote_id, seq)
+);
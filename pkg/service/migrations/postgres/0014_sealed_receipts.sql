@@ -0,0 +1,5 @@
+-- The receipts of the poll are sealed before its root is computed (see 'pkg/service/commitment.go'):
+-- the vote locks the poll and checks the flag before its receipt is saved, so the receipt being
+-- saved at the close either gets into the root, or the vote is refused.
+
+ALTER TABLE polls ADD COLUMN receipts_sealed BOOLEAN NOT NULL DEFAULT FALSE;
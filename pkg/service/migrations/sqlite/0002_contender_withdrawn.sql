@@ -0,0 +1,3 @@
+-- The withdrawn contender keeps its count, but cannot get new votes.
+
+ALTER TABLE contenders ADD COLUMN co_withdrawn BOOLEAN NOT NULL DEFAULT FALSE;
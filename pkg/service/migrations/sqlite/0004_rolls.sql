@@ -0,0 +1,7 @@
+-- Eligibility rolls (see 'pkg/service/roll.go'), the entries are SHA-256 hashes.
+
+CREATE TABLE IF NOT EXISTS rolls (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  entry TEXT NOT NULL,
+  PRIMARY KEY (vote_id, entry)
+);
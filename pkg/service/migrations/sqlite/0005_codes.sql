@@ -0,0 +1,12 @@
+-- Invitation codes (see 'pkg/service/code.go'), the codes are SHA-256 hashes.
+
+ALTER TABLE polls ADD COLUMN require_code BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS codes (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  code TEXT NOT NULL,
+  created TIMESTAMP NOT NULL,
+  used TIMESTAMP,
+  revoked BOOLEAN NOT NULL DEFAULT FALSE,
+  PRIMARY KEY (vote_id, code)
+);
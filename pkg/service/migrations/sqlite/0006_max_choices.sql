@@ -0,0 +1,3 @@
+-- Approval voting (see 'CastBallot' in 'pkg/service/service.go'), 0 is a single choice.
+
+ALTER TABLE polls ADD COLUMN max_choices INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- Ranked-choice polls (see 'pkg/service/ranked.go'), the empty method is a plurality poll.
+-- The ballots are not related to the voters, the ranking is the list of contenders: "3,1,2".
+
+ALTER TABLE polls ADD COLUMN method TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS ballots (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  ballot_id TEXT NOT NULL,
+  ranking TEXT NOT NULL,
+  created TIMESTAMP NOT NULL,
+  PRIMARY KEY (vote_id, ballot_id)
+);
@@ -0,0 +1,14 @@
+-- Score polls (see 'pkg/service/score.go'): the scale of the poll, the sum of the ratings of
+-- each contender (the number of its ratings is 'co_count'), and the number of each score.
+
+ALTER TABLE polls ADD COLUMN score_min INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE polls ADD COLUMN score_max INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE contenders ADD COLUMN co_sum BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS scores (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  co_id SMALLINT NOT NULL,
+  score INTEGER NOT NULL,
+  ratings BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (vote_id, co_id, score)
+);
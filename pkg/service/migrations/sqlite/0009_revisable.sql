@@ -0,0 +1,5 @@
+-- Revisable polls (see 'pkg/service/revise.go'): the contender chosen by the voter is saved
+-- with the voter, NULL for the votes of the other polls (and the votes given before).
+
+ALTER TABLE polls ADD COLUMN revisable BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE voters ADD COLUMN co_id SMALLINT;
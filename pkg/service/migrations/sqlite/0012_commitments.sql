@@ -0,0 +1,9 @@
+-- Roots of the Merkle trees of the receipts of the closed polls (see 'pkg/service/commitment.go'),
+-- the root is SHA-256 in hex.
+
+CREATE TABLE IF NOT EXISTS commitments (
+  vote_id INTEGER NOT NULL PRIMARY KEY REFERENCES polls (vote_id) ON DELETE CASCADE,
+  root TEXT NOT NULL,
+  leaves BIGINT NOT NULL,
+  committed_at TIMESTAMP NOT NULL
+);
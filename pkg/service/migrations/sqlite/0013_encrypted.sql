@@ -0,0 +1,19 @@
+-- Encrypted polls (see 'pkg/service/encrypted.go'): the public key of the poll (hex, empty for the
+-- other polls), the encrypted ballots (JSON, not related to the voters), and the decrypted tally
+-- (JSON with the proofs), saved by 'vote-svc tally' after the close.
+
+ALTER TABLE polls ADD COLUMN public_key TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS encrypted_ballots (
+  vote_id INTEGER NOT NULL REFERENCES polls (vote_id) ON DELETE CASCADE,
+  ballot_id TEXT NOT NULL,
+  ballot TEXT NOT NULL,
+  created TIMESTAMP NOT NULL,
+  PRIMARY KEY (vote_id, ballot_id)
+);
+
+CREATE TABLE IF NOT EXISTS tallies (
+  vote_id INTEGER NOT NULL PRIMARY KEY REFERENCES polls (vote_id) ON DELETE CASCADE,
+  tally TEXT NOT NULL,
+  tallied_at TIMESTAMP NOT NULL
+);
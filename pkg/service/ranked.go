@@ -0,0 +1,169 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"sort"
+)
+
+// Ranked-choice polls: the voter sends the contenders in the order of preference (the first
+// one is the most preferred, the ranking can be partial). Each ballot is saved by the store
+// as it is, without the voter (see 'VoteStore.AddBallot'), and the count of the contender is
+// the number of the first preferences. The results are computed by instant runoff (IRV):
+
+// 1. Each ballot counts for its most preferred contender still in the race.
+// 2. If a contender has the majority of these ballots, it's the winner.
+// 3. Otherwise the contender with the fewest ballots is eliminated, and its ballots are
+// transferred to their next preferences (or exhausted if there are none), go to 1.
+
+// If several contenders have the fewest ballots, all of them are eliminated in the same round.
+// If all the contenders in the race are tied, there is no winner. The withdrawn contenders
+// are not in the race, the ballots skip them.
+
+// RunoffResult is the instant-runoff tally of the ranked ballots, see 'GetVoteResults'.
+type RunoffResult struct {
+	Ballots int64         `json:"ballots"` // Number of the ballots;
+	Rounds  []RunoffRound `json:"rounds"`
+	Winner  int16         `json:"winner"` // The contender id, or 0 if there is no winner (a tie, no ballots);
+}
+
+// RunoffRound is a single round of the runoff. The ballots of the eliminated contenders are
+// transferred to the contenders of the next round (or exhausted), it's the Sankey diagram.
+type RunoffRound struct {
+	Counts     []RunoffCount    `json:"counts"`     // The contenders in the race, ordered by id;
+	Exhausted  int64            `json:"exhausted"`  // The ballots without contenders in the race;
+	Eliminated []int16          `json:"eliminated"` // The contenders eliminated at the end of the round;
+	Transfers  []RunoffTransfer `json:"transfers"`  // Where their ballots go in the next round;
+}
+
+type RunoffCount struct {
+	Id    int16 `json:"id"`
+	Count int64 `json:"count"`
+}
+
+type RunoffTransfer struct {
+	From  int16 `json:"from"`
+	To    int16 `json:"to"` // 0 means that the ballots are exhausted;
+	Count int64 `json:"count"`
+}
+
+// It loads the ballots of the ranked poll and runs the tally.
+func (b *basicVoteService) runoff(ctx context.Context, vote *VoteData) (*RunoffResult, error) {
+	ballots, err := b.store.LoadBallots(ctx, vote.VoteId)
+	if err != nil {
+		return nil, err
+	}
+	return runoff(vote.Contenders, ballots), nil
+}
+
+//////////
+//
+// RUNOFF
+//
+//////////
+
+// It runs the instant runoff, see above. The ballots are not changed.
+func runoff(contenders []Contender, ballots [][]int16) *RunoffResult {
+	res := &RunoffResult{Ballots: int64(len(ballots))}
+
+	race := map[int16]bool{}
+	for _, c := range contenders {
+		if !c.Withdrawn {
+			race[c.Id] = true
+		}
+	}
+
+	// The current choice of each ballot, -1 if it's exhausted;
+	top := make([]int, len(ballots))
+	next := func(i int, from int) int {
+		for j := from; j < len(ballots[i]); j++ {
+			if race[ballots[i][j]] {
+				return j
+			}
+		}
+		return -1
+	}
+	for i := range ballots {
+		top[i] = next(i, 0)
+	}
+
+	for len(race) > 0 {
+		counts := map[int16]int64{}
+		for id := range race {
+			counts[id] = 0
+		}
+
+		var round RunoffRound
+		var total int64
+		for i, j := range top {
+			if j < 0 {
+				round.Exhausted++
+				continue
+			}
+			counts[ballots[i][j]]++
+			total++
+		}
+
+		var lowest int64 = -1
+		for id, n := range counts {
+			round.Counts = append(round.Counts, RunoffCount{Id: id, Count: n})
+			if n*2 > total {
+				res.Winner = id
+			}
+			if lowest < 0 || n < lowest {
+				lowest = n
+			}
+		}
+		sort.Slice(round.Counts, func(i, j int) bool { return round.Counts[i].Id < round.Counts[j].Id })
+
+		if res.Winner == 0 {
+			for _, c := range round.Counts {
+				if c.Count == lowest {
+					round.Eliminated = append(round.Eliminated, c.Id)
+				}
+			}
+		}
+
+		// No winner, and nobody can be eliminated: all of them are tied;
+		if res.Winner != 0 || len(round.Eliminated) == len(race) {
+			round.Eliminated = nil
+			res.Rounds = append(res.Rounds, round)
+			break
+		}
+
+		for _, id := range round.Eliminated {
+			delete(race, id)
+		}
+
+		transfers := map[[2]int16]int64{}
+		for i, j := range top {
+			if j < 0 || race[ballots[i][j]] {
+				continue
+			}
+			from := ballots[i][j]
+			top[i] = next(i, j+1)
+
+			var to int16
+			if top[i] >= 0 {
+				to = ballots[i][top[i]]
+			}
+			transfers[[2]int16{from, to}]++
+		}
+
+		for k, n := range transfers {
+			round.Transfers = append(round.Transfers, RunoffTransfer{From: k[0], To: k[1], Count: n})
+		}
+		sort.Slice(round.Transfers, func(i, j int) bool {
+			a, b := round.Transfers[i], round.Transfers[j]
+			return a.From < b.From || (a.From == b.From && a.To < b.To)
+		})
+
+		res.Rounds = append(res.Rounds, round)
+	}
+
+	return res
+}
+
+// --- END OF FILE ---
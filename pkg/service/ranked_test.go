@@ -0,0 +1,125 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRunoff(t *testing.T) {
+	testinfo := "test Runoff"
+
+	three := []Contender{{Id: 1}, {Id: 2}, {Id: 3}}
+	withdrawn := []Contender{{Id: 1}, {Id: 2}, {Id: 3, Withdrawn: true}}
+
+	var cases = []struct {
+		contenders []Contender
+		ballots    [][]int16
+		winner     int16
+		rounds     int
+		transfers  []RunoffTransfer // Of the first round;
+	}{
+		// The majority of the first preferences;
+		{three, [][]int16{{1, 2}, {1}, {2}}, 1, 1, nil},
+		// The ballots of 3 go to 2, and 2 wins;
+		{three, [][]int16{{1, 2}, {1, 2}, {1}, {1}, {2, 1}, {2}, {2}, {3, 2}, {3, 2}}, 2, 2, []RunoffTransfer{{3, 2, 2}}},
+		// The ballot of 3 is exhausted, 1 and 2 are tied;
+		{three, [][]int16{{1}, {1}, {2}, {2}, {3}}, 0, 2, []RunoffTransfer{{3, 0, 1}}},
+		// 2 and 3 are eliminated at once, 1 wins;
+		{three, [][]int16{{1}, {1}, {2, 1}, {3}}, 1, 2, []RunoffTransfer{{2, 1, 1}, {3, 0, 1}}},
+		// The withdrawn contender is skipped;
+		{withdrawn, [][]int16{{3, 1}, {3, 1}, {2}}, 1, 1, nil},
+		// No ballots, no winner;
+		{three, nil, 0, 1, nil},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			res := runoff(c.contenders, c.ballots)
+			if res.Winner != c.winner || len(res.Rounds) != c.rounds || res.Ballots != int64(len(c.ballots)) {
+				t.Errorf("test %v (case # %d) failed, winner %d, %d rounds (must be %d, %d)",
+					testinfo, i+1, res.Winner, len(res.Rounds), c.winner, c.rounds)
+				return
+			}
+			if !reflect.DeepEqual(res.Rounds[0].Transfers, c.transfers) {
+				t.Errorf("test %v (case # %d) failed, transfers %v (must be %v)", testinfo, i+1, res.Rounds[0].Transfers, c.transfers)
+			}
+		})
+	}
+}
+
+func TestRankedVote(t *testing.T) {
+	testRankedVote(t, load_store(t))
+}
+
+// It votes in a ranked poll, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testRankedVote(t *testing.T, store VoteStore) {
+	testinfo := "test RankedVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	vote := new_vote(TESTDATA_NEW_VOTE_ID)
+	vote.Contenders = append(vote.Contenders, Contender{Id: 3, Name: "Zig", Alias: "Zig", Picture: "zig.png"})
+	vote.Method = VOTE_METHOD_RANKED
+	if _, err := svc.CreateVote(ctx, vote); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the ballots, the first preferences are counted;
+		for i, ranking := range [][]int16{{1, 2}, {1}, {2, 1}, {3, 2}, {3, 2, 1}} {
+			if _, err := svc.CastBallot(public, TESTDATA_NEW_VOTE_ID, ranking, TESTDATA_USER_ID+string(rune('a'+i))); err != nil {
+				t.Errorf("test %v (case # 1) failed, ballot %v, error: %v", testinfo, ranking, err)
+			}
+		}
+
+		// Case 2: nobody has the majority, the ballot of 2 goes to 1, and 1 wins in the second round;
+		res, err := svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || res.Runoff == nil {
+			t.Fatalf("test %v (case # 2) failed, results %+v, error: %v", testinfo, res, err)
+		}
+		if res.Contenders[0].Count != 2 || res.Contenders[1].Count != 1 || res.Contenders[2].Count != 2 {
+			t.Errorf("test %v (case # 2) failed, contenders %+v", testinfo, res.Contenders)
+		}
+		if res.Runoff.Ballots != 5 || res.Runoff.Winner != 1 || len(res.Runoff.Rounds) != 2 {
+			t.Errorf("test %v (case # 2) failed, runoff %+v", testinfo, res.Runoff)
+		}
+		if data, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID); err != nil || data.Runoff != nil {
+			t.Errorf("test %v (case # 2) failed, vote data %+v, error: %v", testinfo, data, err)
+		}
+
+		// Case 3: the method cannot be changed after voting has started;
+		method := VOTE_METHOD_PLURALITY
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Method: &method}); !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrConflict)
+		}
+
+		// Case 4: the ranking is limited by 'max_choices';
+		two := 2
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{MaxChoices: &two}); err != nil {
+			t.Errorf("test %v (case # 4) failed, UpdateVote err %v", testinfo, err)
+		}
+		_, err = svc.CastBallot(public, TESTDATA_NEW_VOTE_ID, []int16{3, 2, 1}, TESTDATA_USER_ID)
+		if err != ErrTooManyChoices {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrTooManyChoices)
+		}
+
+		// Case 5: the ballot is removed if the vote is not recorded;
+		broken := New(brokenVoteStore{store}, []Middleware{})
+		if _, err = broken.CastBallot(public, TESTDATA_NEW_VOTE_ID, []int16{2, 3}, TESTDATA_USER_ID); err == nil {
+			t.Errorf("test %v (case # 5) failed, error is nil", testinfo)
+		}
+		if ballots, err := store.LoadBallots(public, TESTDATA_NEW_VOTE_ID); err != nil || len(ballots) != 5 {
+			t.Errorf("test %v (case # 5) failed, %d ballots (must be 5), error: %v", testinfo, len(ballots), err)
+		}
+	})
+}
+
+// --- END OF FILE ---
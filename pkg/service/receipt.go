@@ -0,0 +1,92 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Receipts: each recorded vote gets a receipt, a random identifier (UUID) with the time of
+// the vote, and the voter can check it later (see 'GetReceipt'). The receipt proves that the
+// ballot was recorded, it does not tell the choice and it's not related to the voter, so the
+// 'voters' table is not exposed. The revisable polls are the exception: the receipt is saved
+// with the voter, and it tells the current choice of the voter (or that the vote has been
+// retracted), see 'revise.go'. Each change of the vote gets a new receipt.
+
+// The receipt is saved with the vote: SQL databases do it in the same transaction (see
+// 'VoteRecorder'), otherwise it's removed if the vote is not recorded.
+
+// Receipt is returned by 'CastBallot' (and the other ways to vote), and by 'GetReceipt'.
+type Receipt struct {
+	Id        string    `json:"id"`
+	VoteId    int       `json:"vote_id"`
+	CastAt    time.Time `json:"cast_at"`             // The time of the vote (milliseconds, UTC);
+	Choice    int16     `json:"co_id,omitempty"`     // The current choice of the voter, revisable polls only;
+	Retracted bool      `json:"retracted,omitempty"` // The vote of the revisable poll has been retracted;
+	UserId    string    `json:"-"`                   // It's saved for the revisable polls only;
+}
+
+// It returns a new receipt of the vote, the voter is saved for the revisable poll only. The
+// time is in milliseconds, it's the precision of Cassandra timestamps.
+func newReceipt(vote *VoteData, user_id string) *Receipt {
+	r := &Receipt{
+		Id:     uuid.NewString(),
+		VoteId: vote.VoteId,
+		CastAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if vote.Revisable {
+		r.UserId = user_id
+	}
+	return r
+}
+
+// It's the receipt as it's returned to the voter right after the vote.
+func (r *Receipt) issued() *Receipt {
+	return &Receipt{Id: r.Id, VoteId: r.VoteId, CastAt: r.CastAt}
+}
+
+///////////////
+//
+// GET RECEIPT
+//
+///////////////
+
+// It confirms that the vote with the 'receipt' was recorded in the poll: it returns the
+// receipt with the time of the vote, or ErrNotFound (the poll is a draft, or there is no such
+// receipt). In a revisable poll, the receipt has the current choice of the voter, or it's
+// 'Retracted'. Nobody is authenticated here, the receipt itself is the secret.
+
+func (b *basicVoteService) GetReceipt(ctx context.Context, vote_id int, receipt string) (*Receipt, error) {
+	if _, err := uuid.Parse(receipt); err != nil {
+		return nil, ErrBadRequest
+	}
+
+	vote, err := b.loadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := b.store.LoadReceipt(ctx, vote_id, receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	if vote.Revisable && r.UserId != "" {
+		r.Choice, err = b.store.LoadChoice(ctx, vote_id, r.UserId)
+		switch {
+		case err == ErrNotFound:
+			r.Retracted = true
+		case err != nil:
+			return nil, err
+		}
+	}
+
+	r.UserId = ""
+	return r, nil
+}
+
+// --- END OF FILE ---
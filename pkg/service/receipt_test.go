@@ -0,0 +1,110 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// It keeps the id of the last receipt saved by the store.
+type receiptSpyStore struct {
+	VoteStore
+	last *string
+}
+
+func (s receiptSpyStore) AddReceipt(ctx context.Context, vote_id int, receipt *Receipt) error {
+	*s.last = receipt.Id
+	return s.VoteStore.AddReceipt(ctx, vote_id, receipt)
+}
+
+func TestReceiptVote(t *testing.T) {
+	testReceiptVote(t, load_store(t))
+}
+
+// It checks the receipts of the votes, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testReceiptVote(t *testing.T, store VoteStore) {
+	testinfo := "test ReceiptVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	if _, err := svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the vote gets a receipt;
+		r, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID)
+		if err != nil || r == nil || r.Id == "" || r.VoteId != TESTDATA_NEW_VOTE_ID || r.CastAt.IsZero() {
+			t.Fatalf("test %v (case # 1) failed, receipt %+v, error: %v", testinfo, r, err)
+		}
+		if r2, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID); err != ErrForbidden || r2 != nil {
+			t.Errorf("test %v (case # 1) failed, receipt %+v, error: %v (must be %v)", testinfo, r2, err, ErrForbidden)
+		}
+
+		// Case 2: the receipt is found, without the choice;
+		res, err := svc.GetReceipt(public, TESTDATA_NEW_VOTE_ID, r.Id)
+		if err != nil || res.Id != r.Id || !res.CastAt.Equal(r.CastAt) || res.Choice != 0 || res.Retracted || res.UserId != "" {
+			t.Errorf("test %v (case # 2) failed, receipt %+v (must be %+v), error: %v", testinfo, res, r, err)
+		}
+
+		// Case 3: the unknown receipts;
+		if _, err = svc.GetReceipt(public, TESTDATA_NEW_VOTE_ID, uuid.NewString()); err != ErrNotFound {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+		if _, err = svc.GetReceipt(public, 1, r.Id); err != ErrNotFound {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+		if _, err = svc.GetReceipt(public, TESTDATA_NEW_VOTE_ID, "1; DROP TABLE receipts"); err != ErrBadRequest {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+		}
+
+		// Case 4: the receipt is removed if the vote is not recorded;
+		var last string
+		broken := New(receiptSpyStore{brokenVoteStore{store}, &last}, []Middleware{})
+		if _, err = broken.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-2"); err == nil || last == "" {
+			t.Errorf("test %v (case # 4) failed, receipt %q, error: %v", testinfo, last, err)
+		}
+		if _, err = svc.GetReceipt(public, TESTDATA_NEW_VOTE_ID, last); err != ErrNotFound {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+
+		// Case 5: the receipts of the revisable poll tell the current choice;
+		yes := true
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Revisable: &yes}); err != nil {
+			t.Fatalf("test %v (case # 5) failed, UpdateVote err %v", testinfo, err)
+		}
+		first, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-3")
+		if err != nil {
+			t.Fatalf("test %v (case # 5) failed, error: %v", testinfo, err)
+		}
+		second, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID+"-3")
+		if err != nil || second.Id == first.Id {
+			t.Fatalf("test %v (case # 5) failed, receipts %+v, %+v, error: %v", testinfo, first, second, err)
+		}
+		for _, id := range []string{first.Id, second.Id} {
+			if res, err = svc.GetReceipt(public, TESTDATA_NEW_VOTE_ID, id); err != nil || res.Choice != 2 || res.Retracted {
+				t.Errorf("test %v (case # 5) failed, receipt %+v, error: %v", testinfo, res, err)
+			}
+		}
+		if res, err = svc.GetReceipt(public, TESTDATA_NEW_VOTE_ID, r.Id); err != nil || res.Choice != 0 {
+			t.Errorf("test %v (case # 5) failed, receipt %+v, error: %v", testinfo, res, err)
+		}
+
+		// Case 6: the vote is retracted;
+		if err = svc.RetractVote(public, TESTDATA_NEW_VOTE_ID, TESTDATA_USER_ID+"-3"); err != nil {
+			t.Fatalf("test %v (case # 6) failed, error: %v", testinfo, err)
+		}
+		if res, err = svc.GetReceipt(public, TESTDATA_NEW_VOTE_ID, second.Id); err != nil || res.Choice != 0 || !res.Retracted {
+			t.Errorf("test %v (case # 6) failed, receipt %+v, error: %v", testinfo, res, err)
+		}
+	})
+}
+
+// --- END OF FILE ---
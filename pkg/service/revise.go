@@ -0,0 +1,147 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Revisable polls: the voter can change the vote, or retract it, until the poll is closed.
+// The store keeps the contender chosen by the voter with the voter (see 'LoadChoice'), and
+// a new vote moves the count from the old contender to the new one, there is still one vote
+// per voter. Only a plurality poll with a single choice can be revisable, and it cannot
+// require invitation codes (a code is used once).
+
+// The voter is changed first, conditionally (see 'ChangeChoice'): if two votes of the same
+// voter go at once, one of them is rejected with ErrConflict. Then the count is moved by
+// 'MoveCount', and the voter is changed back if it fails. The SQL stores do both in one
+// transaction (see 'VoteReviser'). Each change is an entry of the audit log, see 'audit.go'.
+
+const ERR_MSG_NOT_REVISABLE = "votes of this poll cannot be changed"
+
+// ErrNotRevisable is returned if the voter has voted in a poll which is not revisable, or
+// before the poll became revisable (the choice was not saved).
+var ErrNotRevisable = fmt.Errorf("%w: %s", ErrForbidden, ERR_MSG_NOT_REVISABLE)
+
+////////////////
+//
+// RETRACT VOTE
+//
+////////////////
+
+// It retracts the vote of the voter in the revisable poll (ErrNotRevisable otherwise): the
+// voter is removed, and the count of its contender is decremented, so the voter can vote
+// again. It returns ErrNotFound if the voter has not voted, and ErrClosed after the deadline.
+// The voter is checked as by 'CastBallot' (the token, if the poll requires authentication).
+
+func (b *basicVoteService) RetractVote(ctx context.Context, vote_id int, user_id string) error {
+	if b.store == nil {
+		return ErrServiceUnavailable
+	}
+
+	vote, err := b.store.LoadVote(ctx, vote_id)
+	if err != nil {
+		return err
+	}
+
+	if !vote.Revisable {
+		return ErrNotRevisable
+	}
+
+	if err = vote.checkOpen(time.Now()); err != nil {
+		return err
+	}
+
+	if vote.Authenticate {
+		if user_id, err = b.authenticate(ctx); err != nil {
+			return err
+		}
+	}
+
+	// The voter is recorded under the roll entry, if the poll has a roll (see 'checkRoll');
+	if user_id, err = b.checkRoll(ctx, vote_id, user_id); err != nil {
+		return err
+	}
+
+	return b.reviseVote(ctx, vote_id, user_id, 0)
+}
+
+// It moves the vote of the voter to the contender 'to' (0 retracts the vote), see above.
+func (b *basicVoteService) reviseVote(ctx context.Context, vote_id int, user_id string, to int16) error {
+	from, err := b.store.LoadChoice(ctx, vote_id, user_id)
+	switch {
+	case err == ErrNotFound:
+		if to == 0 {
+			return ErrNotFound // Nothing to retract;
+		}
+		from = 0
+	case err != nil:
+		return err
+	case from == 0:
+		return ErrNotRevisable
+	case from == to:
+		return nil // The same vote again;
+	}
+
+	audit := newAuditEntry(AUDIT_REVISE, map[int16]int{to: 1})
+	switch {
+	case from == 0:
+		audit = newAuditEntry(AUDIT_VOTE, map[int16]int{to: 1})
+	case to == 0:
+		audit = newAuditEntry(AUDIT_RETRACT, map[int16]int{from: -1})
+	default:
+		audit.Counts[from] = -1
+	}
+
+	if rev, ok := b.store.(VoteReviser); ok {
+		for i := 0; i < AUDIT_RETRIES; i++ {
+			if err = rev.ReviseVote(ctx, vote_id, user_id, from, to, audit); err != ErrAuditConflict {
+				break
+			}
+		}
+		return err
+	}
+
+	applied, err := b.store.ChangeChoice(ctx, vote_id, user_id, from, to)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return fmt.Errorf("%w: the vote has been changed in the meantime", ErrConflict)
+	}
+
+	if err = b.appendAudit(ctx, vote_id, audit); err != nil {
+		b.store.ChangeChoice(ctx, vote_id, user_id, to, from)
+		return err
+	}
+
+	applied, err = b.store.MoveCount(ctx, vote_id, from, to)
+	if err == nil && !applied {
+		err = ErrBadRequest // The contender has disappeared in the meantime;
+	}
+	if err != nil {
+		b.store.ChangeChoice(ctx, vote_id, user_id, to, from)
+		b.appendAudit(ctx, vote_id, audit.void())
+		return err
+	}
+	return nil
+}
+
+// It returns ErrBadRequest if the poll cannot be revisable, see 'validateVote'.
+func validateRevisable(vote *VoteData) error {
+	if !vote.Revisable {
+		return nil
+	}
+	if (vote.Method != "" && vote.Method != VOTE_METHOD_PLURALITY) || vote.MaxChoices > 1 {
+		return fmt.Errorf("%w: revisable poll must be a plurality poll with a single choice", ErrBadRequest)
+	}
+	if vote.RequireCode {
+		return fmt.Errorf("%w: revisable poll cannot require codes", ErrBadRequest)
+	}
+	return nil
+}
+
+// --- END OF FILE ---
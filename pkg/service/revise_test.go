@@ -0,0 +1,140 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// The count is not moved, e.g. the database is down after the voter is changed.
+type brokenMoveStore struct {
+	VoteStore
+}
+
+func (b brokenMoveStore) MoveCount(ctx context.Context, vote_id int, from, to int16) (bool, error) {
+	return false, errors.New("write timeout")
+}
+
+func TestRevisableVote(t *testing.T) {
+	testRevisableVote(t, load_store(t))
+}
+
+// It changes and retracts the votes in a revisable poll, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testRevisableVote(t *testing.T, store VoteStore) {
+	testinfo := "test RevisableVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	vote := new_vote(TESTDATA_NEW_VOTE_ID)
+	vote.Revisable = true
+	vote.MaxChoices = 2
+	if _, err := svc.CreateVote(ctx, vote); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("test %v failed, CreateVote err %v (must be %v)", testinfo, err, ErrBadRequest)
+	}
+	vote.Revisable = false
+	vote.MaxChoices = 0
+	if _, err := svc.CreateVote(ctx, vote); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	counts := func(n1, n2 int64) bool {
+		res, err := svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID)
+		return err == nil && res.Contenders[0].Count == n1 && res.Contenders[1].Count == n2
+	}
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the vote given before the poll is revisable cannot be changed;
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, "early"); err != nil {
+			t.Fatalf("test %v (case # 1) failed, error: %v", testinfo, err)
+		}
+		if err := svc.RetractVote(public, TESTDATA_NEW_VOTE_ID, "early"); err != ErrNotRevisable {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrNotRevisable)
+		}
+		yes := true
+		if _, err := svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Revisable: &yes}); err != nil {
+			t.Fatalf("test %v (case # 1) failed, UpdateVote err %v", testinfo, err)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, "early"); err != ErrNotRevisable {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrNotRevisable)
+		}
+
+		// Case 2: the vote is moved, the same vote again changes nothing;
+		for _, co_id := range []int16{1, 2, 2} {
+			if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, co_id, TESTDATA_USER_ID); err != nil {
+				t.Errorf("test %v (case # 2) failed, co_id %d, error: %v", testinfo, co_id, err)
+			}
+		}
+		if !counts(1, 1) {
+			t.Errorf("test %v (case # 2) failed, the counts must be 1, 1", testinfo)
+		}
+
+		// Case 3: the vote is retracted once, then the voter can vote again;
+		if err := svc.RetractVote(public, TESTDATA_NEW_VOTE_ID, TESTDATA_USER_ID); err != nil {
+			t.Errorf("test %v (case # 3) failed, error: %v", testinfo, err)
+		}
+		if err := svc.RetractVote(public, TESTDATA_NEW_VOTE_ID, TESTDATA_USER_ID); err != ErrNotFound {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+		if !counts(1, 0) {
+			t.Errorf("test %v (case # 3) failed, the counts must be 1, 0", testinfo)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID); err != nil || !counts(2, 0) {
+			t.Errorf("test %v (case # 3) failed, error: %v", testinfo, err)
+		}
+
+		// Case 4: the voter is changed back if the count is not moved;
+		broken := New(brokenMoveStore{store}, []Middleware{})
+		if _, err := broken.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID); err == nil {
+			t.Errorf("test %v (case # 4) failed, error is nil", testinfo)
+		}
+		if co_id, err := store.LoadChoice(public, TESTDATA_NEW_VOTE_ID, TESTDATA_USER_ID); err != nil || co_id != 1 || !counts(2, 0) {
+			t.Errorf("test %v (case # 4) failed, choice %d (must be 1), error: %v", testinfo, co_id, err)
+		}
+
+		// Case 5: the revisable poll does not take codes, and none is stored;
+		if _, err := svc.GenerateCodes(ctx, TESTDATA_NEW_VOTE_ID, 3); !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 5) failed, error: %v (must be %v)", testinfo, err, ErrConflict)
+		}
+		if n, err := svc.RevokeCodes(ctx, TESTDATA_NEW_VOTE_ID, CodeRevocation{All: true}); err != nil || n != 0 {
+			t.Errorf("test %v (case # 5) failed, %v codes stored (must be 0), error: %v", testinfo, n, err)
+		}
+
+		// Case 6: nothing is changed after the poll is closed;
+		if _, err := svc.CloseVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Fatalf("test %v (case # 6) failed, CloseVote err %v", testinfo, err)
+		}
+		if err := svc.RetractVote(public, TESTDATA_NEW_VOTE_ID, TESTDATA_USER_ID); err != ErrClosed {
+			t.Errorf("test %v (case # 6) failed, error: %v (must be %v)", testinfo, err, ErrClosed)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID); err != ErrClosed {
+			t.Errorf("test %v (case # 6) failed, error: %v (must be %v)", testinfo, err, ErrClosed)
+		}
+
+		// Case 7: the receipts of the revised votes are not committed, and the poll stays revisable;
+		if _, err := store.LoadCommitment(public, TESTDATA_NEW_VOTE_ID); err != ErrNotFound {
+			t.Errorf("test %v (case # 7) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+		if _, err := svc.GetCommitment(public, TESTDATA_NEW_VOTE_ID); err != ErrRevisableCommitment || !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 7) failed, error: %v (must be %v)", testinfo, err, ErrRevisableCommitment)
+		}
+		receipts, err := store.LoadReceipts(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || len(receipts) < 3 {
+			t.Fatalf("test %v (case # 7) failed, %d receipts, error: %v", testinfo, len(receipts), err)
+		}
+		if _, err = svc.GetReceiptProof(public, TESTDATA_NEW_VOTE_ID, receipts[0].Id); err != ErrRevisableCommitment {
+			t.Errorf("test %v (case # 7) failed, error: %v (must be %v)", testinfo, err, ErrRevisableCommitment)
+		}
+		no := false
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Revisable: &no}); !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 7) failed, error: %v (must be %v)", testinfo, err, ErrConflict)
+		}
+	})
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,198 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The eligibility roll is the list of people who may vote in the poll. If the poll has a
+// roll, the voter must be on it (ErrNotEligible), otherwise anybody can vote. The roll is
+// uploaded by the admin as CSV, the first column is the voter identifier ('user_id', or the
+// token subject if the poll requires authentication, see 'jwt.go'), or its SHA-256 hash.
+
+// The store keeps the hashes only: the identifiers are trimmed and lowercased (so an email
+// is matched whatever the case), and hashed. A value of 64 hex digits is taken as a hash
+// already, it lets the admin upload e.g. the hashes of emails without the emails.
+
+// A poll which requires invitation codes without authentication cannot have a roll: the
+// 'user_id' of its voter is the code (see 'code.go'), and it's never on the roll. Such a roll
+// is rejected by 'SetRoll', and so are the codes of the poll with a roll (ErrCodeRoll).
+
+const ROLL_HASH_LEN = sha256.Size * 2
+
+const ERR_MSG_CODE_ROLL = "poll requiring codes without authentication cannot have a roll"
+
+var (
+	// ErrNotEligible is returned if the poll has a roll, and the voter is not on it.
+	ErrNotEligible = fmt.Errorf("%w: %s", ErrForbidden, ERR_MSG_NOT_ELIGIBLE)
+	// ErrCodeRoll is returned if the poll would require the codes without authentication, and have a roll.
+	ErrCodeRoll = fmt.Errorf("%w: %s", ErrConflict, ERR_MSG_CODE_ROLL)
+)
+
+// RollStats is the turnout against the roll, see 'GetVoteResults'.
+type RollStats struct {
+	Size    int64   `json:"size"`    // Number of voters on the roll;
+	Voted   int64   `json:"voted"`   // Number of them who have voted;
+	Turnout float64 `json:"turnout"` // Voted / Size, 0..1;
+}
+
+////////////
+//
+// SET ROLL
+//
+////////////
+
+// It replaces the roll of the poll with 'entries' (identifiers or hashes, see above), the
+// duplicates are ignored. An empty list removes the roll, i.e. anybody can vote again. It
+// returns the new turnout, the voters who have voted before are counted if they are on it,
+// or ErrCodeRoll if the poll requires the codes without authentication (see above).
+
+func (b *basicVoteService) SetRoll(ctx context.Context, vote_id int, entries []string) (*RollStats, error) {
+	if !IsAdmin(ctx) {
+		return nil, ErrUnauthorized
+	}
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	seen := map[string]bool{}
+	hashes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		h := rollEntry(e)
+		if h == "" {
+			return nil, ErrBadRequest
+		}
+		if !seen[h] {
+			seen[h] = true
+			hashes = append(hashes, h)
+		}
+	}
+
+	if len(hashes) > 0 {
+		vote, err := b.store.LoadVote(ctx, vote_id)
+		if err != nil {
+			return nil, err
+		}
+		if vote.RequireCode && !vote.Authenticate {
+			return nil, ErrCodeRoll
+		}
+	}
+
+	if err := b.store.SetRoll(ctx, vote_id, hashes); err != nil {
+		return nil, err
+	}
+	return b.rollStats(ctx, vote_id)
+}
+
+// It returns the turnout, or nil if the poll has no roll.
+func (b *basicVoteService) rollStats(ctx context.Context, vote_id int) (*RollStats, error) {
+	stats, err := b.store.RollStats(ctx, vote_id)
+	if err != nil || stats.Size == 0 {
+		return nil, err
+	}
+
+	stats.Turnout = float64(stats.Voted) / float64(stats.Size)
+	return stats, nil
+}
+
+// It returns ErrCodeRoll if the poll has a roll, it's called before the poll requires the
+// codes without authentication (see above).
+func (b *basicVoteService) checkNoRoll(ctx context.Context, vote_id int) error {
+	stats, err := b.store.RollStats(ctx, vote_id)
+	if err != nil {
+		return err
+	}
+	if stats.Size > 0 {
+		return ErrCodeRoll
+	}
+	return nil
+}
+
+// It returns ErrNotEligible if the poll has a roll, and the voter is not on it. Otherwise it
+// returns the identifier the voter is recorded under: the roll entry if the poll has a roll,
+// so the variants of one identifier (the case, the spaces, the hash) are one voter, and the
+// 'user_id' as is if it has not.
+func (b *basicVoteService) checkRoll(ctx context.Context, vote_id int, user_id string) (string, error) {
+	// The empty entry is never on the roll, so it's true only if the poll has no roll;
+	no_roll, err := b.store.CheckRoll(ctx, vote_id, "")
+	if err != nil || no_roll {
+		return user_id, err
+	}
+
+	entry := rollEntry(user_id)
+	ok, err := b.store.CheckRoll(ctx, vote_id, entry)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrNotEligible
+	}
+	return entry, nil
+}
+
+// It returns the hash of the identifier as it's kept by the store (see above), or an empty
+// string if there is no identifier.
+func rollEntry(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return ""
+	}
+
+	if len(id) == ROLL_HASH_LEN {
+		if _, err := hex.DecodeString(id); err == nil {
+			return id
+		}
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+//////////////////
+//
+// PARSE ROLL CSV
+//
+//////////////////
+
+// ParseRollCSV returns the first column of each record. Other columns (e.g. names) are
+// ignored, so is the header: the first record if it's 'user_id', 'id', 'email' or 'hash'.
+func ParseRollCSV(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // The number of columns is not checked;
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var entries []string
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		e := strings.TrimSpace(record[0])
+		if first {
+			switch strings.ToLower(e) {
+			case "user_id", "id", "email", "hash":
+				continue
+			}
+		}
+		if e != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,163 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRollCSV(t *testing.T) {
+	testinfo := "test ParseRollCSV"
+
+	var cases = []struct {
+		csv  string
+		want []string
+	}{
+		{"user_id,name\nalice,Alice\n bob , Bob\n\n", []string{"alice", "bob"}},
+		{"alice\n# comment\n\"carol, jr\"\n", []string{"alice", "carol, jr"}},
+		{"Email\nalice@example.com\n", []string{"alice@example.com"}},
+		{"", nil},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			got, err := ParseRollCSV(strings.NewReader(c.csv))
+			if err != nil || !reflect.DeepEqual(got, c.want) {
+				t.Errorf("test %v (case # %d) failed, entries %q (must be %q), error: %v", testinfo, i+1, got, c.want, err)
+			}
+		})
+	}
+
+	if _, err := ParseRollCSV(strings.NewReader("\"alice\n")); err == nil {
+		t.Errorf("test %v failed, bad CSV must be rejected", testinfo)
+	}
+}
+
+func TestVoteRoll(t *testing.T) {
+	testVoteRoll(t, load_store(t))
+}
+
+// It uploads the roll and votes, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testVoteRoll(t *testing.T, store VoteStore) {
+	testinfo := "test VoteRoll"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	if _, err := svc.CreateVote(ctx, new_vote(TESTDATA_NEW_VOTE_ID)); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	bob := sha256.Sum256([]byte("bob"))
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: no roll, anybody can vote;
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, "early-voter"); err != nil {
+			t.Errorf("test %v (case # 1) failed, error: %v", testinfo, err)
+		}
+
+		// Case 2: the roll is uploaded by the admin only;
+		roll := []string{"Alice@Example.com", hex.EncodeToString(bob[:]), "early-voter", "alice@example.com "}
+		if _, err := svc.SetRoll(public, TESTDATA_NEW_VOTE_ID, roll); err != ErrUnauthorized {
+			t.Errorf("test %v (case # 2) failed, error: %v (must be %v)", testinfo, err, ErrUnauthorized)
+		}
+		if _, err := svc.SetRoll(ctx, TESTDATA_NEW_VOTE_ID+1, roll); err != ErrNotFound {
+			t.Errorf("test %v (case # 2) failed, error: %v (must be %v)", testinfo, err, ErrNotFound)
+		}
+
+		// Case 3: the duplicates are ignored, the early voter is on the roll;
+		stats, err := svc.SetRoll(ctx, TESTDATA_NEW_VOTE_ID, roll)
+		if err != nil || stats == nil || stats.Size != 3 || stats.Voted != 1 {
+			t.Errorf("test %v (case # 3) failed, stats %+v, error: %v", testinfo, stats, err)
+		}
+
+		// Case 4: the voter is not on the roll;
+		_, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, "carol")
+		if err != ErrNotEligible || !errors.Is(err, ErrForbidden) {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrNotEligible)
+		}
+
+		// Case 5: the identifier is matched whatever the case, and by its hash;
+		if _, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, "ALICE@example.com"); err != nil {
+			t.Errorf("test %v (case # 5) failed, error: %v", testinfo, err)
+		}
+		if _, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, "bob"); err != nil {
+			t.Errorf("test %v (case # 5) failed, error: %v", testinfo, err)
+		}
+
+		// Case 5a: the variants of the identifier are the same voter, who has voted already;
+		alice := sha256.Sum256([]byte("alice@example.com"))
+		for _, user_id := range []string{"alice@example.com", " Alice@Example.COM", hex.EncodeToString(alice[:]), "BOB"} {
+			if _, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, user_id); err != ErrForbidden {
+				t.Errorf("test %v (case # 5a) failed, user_id %q, error: %v (must be %v)", testinfo, user_id, err, ErrForbidden)
+			}
+		}
+
+		// Case 6: the turnout is in the results, but not in the vote data;
+		res, err := svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || res.Roll == nil || res.Roll.Voted != 3 || res.Roll.Turnout != 1 {
+			t.Errorf("test %v (case # 6) failed, results %+v, error: %v", testinfo, res, err)
+		}
+		if res, err = svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID); err != nil || res.Roll != nil {
+			t.Errorf("test %v (case # 6) failed, vote data %+v, error: %v", testinfo, res, err)
+		}
+
+		// Case 7: the empty roll removes the roll;
+		if stats, err = svc.SetRoll(ctx, TESTDATA_NEW_VOTE_ID, nil); err != nil || stats != nil {
+			t.Errorf("test %v (case # 7) failed, stats %+v, error: %v", testinfo, stats, err)
+		}
+		if _, err = svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, "carol"); err != nil {
+			t.Errorf("test %v (case # 7) failed, error: %v", testinfo, err)
+		}
+
+		// Case 8: the codes without authentication and the roll are exclusive, the codes are not stored;
+		if _, err = svc.SetRoll(ctx, TESTDATA_NEW_VOTE_ID, roll); err != nil {
+			t.Fatalf("test %v (case # 8) failed, error: %v", testinfo, err)
+		}
+		if _, err = svc.GenerateCodes(ctx, TESTDATA_NEW_VOTE_ID, 3); err != ErrCodeRoll || !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 8) failed, error: %v (must be %v)", testinfo, err, ErrCodeRoll)
+		}
+		if n, err := svc.RevokeCodes(ctx, TESTDATA_NEW_VOTE_ID, CodeRevocation{All: true}); err != nil || n != 0 {
+			t.Errorf("test %v (case # 8) failed, %v codes stored (must be 0), error: %v", testinfo, n, err)
+		}
+		yes, no := true, false
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{RequireCode: &yes}); err != ErrCodeRoll {
+			t.Errorf("test %v (case # 8) failed, error: %v (must be %v)", testinfo, err, ErrCodeRoll)
+		}
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{RequireCode: &yes, Authenticate: &yes}); err != nil {
+			t.Errorf("test %v (case # 8) failed, error: %v", testinfo, err)
+		}
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Authenticate: &no}); err != ErrCodeRoll {
+			t.Errorf("test %v (case # 8) failed, error: %v (must be %v)", testinfo, err, ErrCodeRoll)
+		}
+
+		// Case 9: the roll of the poll requiring the codes without authentication is rejected;
+		if _, err = svc.SetRoll(ctx, TESTDATA_NEW_VOTE_ID, nil); err != nil {
+			t.Errorf("test %v (case # 9) failed, error: %v", testinfo, err)
+		}
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Authenticate: &no}); err != nil {
+			t.Errorf("test %v (case # 9) failed, error: %v", testinfo, err)
+		}
+		if _, err = svc.SetRoll(ctx, TESTDATA_NEW_VOTE_ID, roll); err != ErrCodeRoll {
+			t.Errorf("test %v (case # 9) failed, error: %v (must be %v)", testinfo, err, ErrCodeRoll)
+		}
+		codes, err := svc.GenerateCodes(ctx, TESTDATA_NEW_VOTE_ID, 1)
+		if err != nil {
+			t.Fatalf("test %v (case # 9) failed, error: %v", testinfo, err)
+		}
+		if _, err = svc.UpdateVoteResults(WithVoteCode(public, codes[0]), TESTDATA_NEW_VOTE_ID, 1, ""); err != nil {
+			t.Errorf("test %v (case # 9) failed, error: %v", testinfo, err)
+		}
+	})
+}
+
+// --- END OF FILE ---
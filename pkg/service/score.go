@@ -0,0 +1,143 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// Score polls: the voter rates every contender on the scale of the poll ('ScoreMin' to
+// 'ScoreMax', e.g. 0..5 stars), and the ballot is rejected if any contender in the race is
+// not rated. The store keeps a tally for each contender: the sum and the number of its
+// ratings, and the number of each score (see 'VoteStore.AddScores'), the ballots are not
+// saved. The results are the statistics of the ratings instead of the counts (the count of
+// the contender is the number of its ratings).
+
+// The scale of the poll without 'ScoreMin' and 'ScoreMax' is 0..SCORE_DEFAULT_MAX, and
+// the scale cannot be wider than MAX_SCORE_RANGE (each score is a bar of the histogram).
+const SCORE_DEFAULT_MAX = 5
+const MAX_SCORE_RANGE = 100
+
+// ScoreStats are the ratings of the contender in a score poll, see 'GetVoteResults'.
+type ScoreStats struct {
+	Ratings   int64   `json:"ratings"` // Number of the ratings;
+	Sum       int64   `json:"sum"`
+	Mean      float64 `json:"mean"`      // 0 if there are no ratings;
+	Histogram []int64 `json:"histogram"` // Number of the ratings of each score, from 'score_min' to 'score_max';
+}
+
+// It returns the scale of the score poll, see 'SCORE_DEFAULT_MAX'.
+func (v *VoteData) scale() (int, int) {
+	if v.ScoreMin == 0 && v.ScoreMax == 0 {
+		return 0, SCORE_DEFAULT_MAX
+	}
+	return v.ScoreMin, v.ScoreMax
+}
+
+///////////////////
+//
+// RATE CONTENDERS
+//
+///////////////////
+
+// It's a ballot of the score poll: 'scores' has the rating of each contender (co_id -> score).
+// All the contenders must be rated except the withdrawn ones, which cannot be rated
+// (ErrWithdrawn), and the scores must be on the scale of the poll, otherwise ErrBadRequest.
+// The rest is the same as 'CastBallot': the poll must be open, the voter votes once, etc.
+
+func (b *basicVoteService) RateContenders(ctx context.Context, vote_id int, scores map[int16]int, user_id string) (*Receipt, error) {
+	return b.castVote(ctx, vote_id, user_id, func(vote *VoteData) (*VoteRecord, error) {
+		if err := vote.checkScores(scores); err != nil {
+			return nil, err
+		}
+		return &VoteRecord{Scores: scores}, nil
+	})
+}
+
+// It returns an error if the ratings are not a valid ballot of the poll, see 'RateContenders'.
+func (v *VoteData) checkScores(scores map[int16]int) error {
+	if v.Method != VOTE_METHOD_SCORE {
+		return fmt.Errorf("%w: the poll is not a score poll", ErrBadRequest)
+	}
+
+	min, max := v.scale()
+	for co_id, score := range scores {
+		contender := v.findContender(co_id)
+		if contender == nil {
+			return ErrBadRequest
+		}
+		if contender.Withdrawn {
+			return ErrWithdrawn
+		}
+		if score < min || score > max {
+			return fmt.Errorf("%w: score must be %d..%d", ErrBadRequest, min, max)
+		}
+	}
+
+	for _, c := range v.Contenders {
+		if _, ok := scores[c.Id]; !ok && !c.Withdrawn {
+			return fmt.Errorf("%w: every contender must be rated", ErrBadRequest)
+		}
+	}
+	return nil
+}
+
+// It returns ErrBadRequest if the scale of the score poll is not valid, see 'validateVote'.
+func validateScale(vote *VoteData) error {
+	if vote.Method != VOTE_METHOD_SCORE {
+		return nil
+	}
+
+	min, max := vote.scale()
+	if min >= max || max-min > MAX_SCORE_RANGE {
+		return fmt.Errorf("%w: score_min must be less than score_max, up to %d apart", ErrBadRequest, MAX_SCORE_RANGE)
+	}
+	return nil
+}
+
+///////////////
+//
+// SCORE STATS
+//
+///////////////
+
+// It loads the tallies of the score poll, and sets the statistics and the count of each contender.
+func (b *basicVoteService) scoreStats(ctx context.Context, vote *VoteData) error {
+	tallies, err := b.store.LoadScores(ctx, vote.VoteId)
+	if err != nil {
+		return err
+	}
+
+	min, max := vote.scale()
+	for i := range vote.Contenders {
+		c := &vote.Contenders[i]
+		c.Score = newScoreStats(tallies[c.Id], min, max)
+		c.Count = c.Score.Ratings
+	}
+	return nil
+}
+
+// The scores out of the scale are not in the histogram (the scale cannot be changed after
+// voting has started, anyway), but they are in the sum and the mean.
+func newScoreStats(t *ScoreTally, min, max int) *ScoreStats {
+	res := &ScoreStats{Histogram: make([]int64, max-min+1)}
+	if t == nil {
+		return res
+	}
+
+	res.Ratings = t.Count
+	res.Sum = t.Sum
+	if t.Count > 0 {
+		res.Mean = float64(t.Sum) / float64(t.Count)
+	}
+	for score, n := range t.Ratings {
+		if score >= min && score <= max {
+			res.Histogram[score-min] = n
+		}
+	}
+	return res
+}
+
+// --- END OF FILE ---
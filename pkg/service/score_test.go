@@ -0,0 +1,141 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckScores(t *testing.T) {
+	testinfo := "test CheckScores"
+
+	vote := VoteData{Method: VOTE_METHOD_SCORE, ScoreMin: 1, ScoreMax: 5,
+		Contenders: []Contender{{Id: 1}, {Id: 2}, {Id: 3, Withdrawn: true}}}
+
+	var cases = []struct {
+		scores map[int16]int
+		err    error
+	}{
+		{map[int16]int{1: 5, 2: 1}, nil},
+		{map[int16]int{1: 5}, ErrBadRequest},             // The contender # 2 is not rated;
+		{map[int16]int{1: 5, 2: 1, 3: 2}, ErrWithdrawn},  // The withdrawn one is rated;
+		{map[int16]int{1: 5, 2: 1, 4: 2}, ErrBadRequest}, // Unknown contender;
+		{map[int16]int{1: 6, 2: 1}, ErrBadRequest},       // Out of the scale;
+		{map[int16]int{1: 0, 2: 1}, ErrBadRequest},
+		{nil, ErrBadRequest},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			if err := vote.checkScores(c.scores); !errors.Is(err, c.err) || (c.err == nil && err != nil) {
+				t.Errorf("test %v (case # %d) failed, error: %v (must be %v)", testinfo, i+1, err, c.err)
+			}
+		})
+	}
+}
+
+func TestScoreStats(t *testing.T) {
+	testinfo := "test ScoreStats"
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the histogram is 1..5, the score out of the scale is in the mean only;
+		res := newScoreStats(&ScoreTally{Sum: 16, Count: 4, Ratings: map[int]int64{1: 1, 5: 2, 7: 1}}, 1, 5)
+		want := &ScoreStats{Ratings: 4, Sum: 16, Mean: 4, Histogram: []int64{1, 0, 0, 0, 2}}
+		if !reflect.DeepEqual(res, want) {
+			t.Errorf("test %v (case # 1) failed, %+v (must be %+v)", testinfo, res, want)
+		}
+
+		// Case 2: no ratings, no mean;
+		res = newScoreStats(nil, 0, 2)
+		want = &ScoreStats{Histogram: []int64{0, 0, 0}}
+		if !reflect.DeepEqual(res, want) {
+			t.Errorf("test %v (case # 2) failed, %+v (must be %+v)", testinfo, res, want)
+		}
+	})
+}
+
+func TestScoreVote(t *testing.T) {
+	testScoreVote(t, load_store(t))
+}
+
+// It votes in a score poll, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testScoreVote(t *testing.T, store VoteStore) {
+	testinfo := "test ScoreVote"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	vote := new_vote(TESTDATA_NEW_VOTE_ID)
+	vote.Method = VOTE_METHOD_SCORE
+	vote.ScoreMax = MAX_SCORE_RANGE + 1
+	if _, err := svc.CreateVote(ctx, vote); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("test %v failed, CreateVote err %v (must be %v)", testinfo, err, ErrBadRequest)
+	}
+	vote.ScoreMax = 0 // It's 0..SCORE_DEFAULT_MAX;
+	if _, err := svc.CreateVote(ctx, vote); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: the contenders are rated, not selected;
+		_, err := svc.CastBallot(public, TESTDATA_NEW_VOTE_ID, []int16{1}, TESTDATA_USER_ID)
+		if !errors.Is(err, ErrBadRequest) {
+			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+		}
+
+		// Case 2: the ratings are added, the voter votes once;
+		for i, scores := range []map[int16]int{{1: 5, 2: 0}, {1: 4, 2: 1}, {1: 5, 2: 5}} {
+			if _, err = svc.RateContenders(public, TESTDATA_NEW_VOTE_ID, scores, TESTDATA_USER_ID+string(rune('a'+i))); err != nil {
+				t.Errorf("test %v (case # 2) failed, scores %v, error: %v", testinfo, scores, err)
+			}
+		}
+		_, err = svc.RateContenders(public, TESTDATA_NEW_VOTE_ID, map[int16]int{1: 1, 2: 1}, TESTDATA_USER_ID+"a")
+		if err != ErrForbidden {
+			t.Errorf("test %v (case # 2) failed, error: %v (must be %v)", testinfo, err, ErrForbidden)
+		}
+
+		// Case 3: the results have the statistics of the ratings;
+		res, err := svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil {
+			t.Fatalf("test %v (case # 3) failed, error: %v", testinfo, err)
+		}
+		want := []ScoreStats{
+			{Ratings: 3, Sum: 14, Mean: 14.0 / 3, Histogram: []int64{0, 0, 0, 0, 1, 2}},
+			{Ratings: 3, Sum: 6, Mean: 2, Histogram: []int64{1, 1, 0, 0, 0, 1}},
+		}
+		for i, c := range res.Contenders {
+			if c.Score == nil || !reflect.DeepEqual(*c.Score, want[i]) || c.Count != 3 {
+				t.Errorf("test %v (case # 3) failed, contender %d: %+v (must be %+v)", testinfo, c.Id, c.Score, want[i])
+			}
+		}
+		if data, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID); err != nil || data.Contenders[0].Score != nil {
+			t.Errorf("test %v (case # 3) failed, vote data %+v, error: %v", testinfo, data, err)
+		}
+
+		// Case 4: the scale cannot be changed after voting has started;
+		ten := 10
+		if _, err = svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{ScoreMax: &ten}); !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrConflict)
+		}
+
+		// Case 5: nothing is added if the vote is not recorded;
+		broken := New(brokenVoteStore{store}, []Middleware{})
+		if _, err = broken.RateContenders(public, TESTDATA_NEW_VOTE_ID, map[int16]int{1: 0, 2: 0}, TESTDATA_USER_ID); err == nil {
+			t.Errorf("test %v (case # 5) failed, error is nil", testinfo)
+		}
+		if _, err = svc.RateContenders(public, TESTDATA_NEW_VOTE_ID, map[int16]int{1: 0, 2: 0}, TESTDATA_USER_ID); err != nil {
+			t.Errorf("test %v (case # 5) failed, the voter was not removed, error: %v", testinfo, err)
+		}
+		if tallies, err := store.LoadScores(public, TESTDATA_NEW_VOTE_ID); err != nil || tallies[1].Count != 4 {
+			t.Errorf("test %v (case # 5) failed, tallies %+v, error: %v", testinfo, tallies, err)
+		}
+	})
+}
+
+// --- END OF FILE ---
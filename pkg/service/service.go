@@ -1,18 +1,19 @@
 //  Created : 2024-Mar-14
-// Modified : 2024-May-14
+// Modified : 2026-Oct-18
 
 package service
 
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gocql/gocql"
+	"github.com/google/uuid"
 )
 
 // These are HealthStatus messages.
@@ -21,6 +22,7 @@ const SERVICE_STATUS_DOWN = "DOWN"
 const SERVICE_STATUS_UNKNOWN = "Unknown"
 const SERVICE_STATUS_LOW_MEMORY = "Unsufficient memory"
 const SERVICE_STATUS_NO_DATABASE = "Database connection failure"
+const SERVICE_STATUS_DEGRADED = "Database degraded, some nodes are down"
 
 // The following error messages should not be capitalized (compiler warning);
 const ERR_MSG_NO_CONTENT = "no content"
@@ -28,9 +30,17 @@ const ERR_MSG_BAD_REQUEST = "bad request"
 const ERR_MSG_UNAUTHORIZED = "unauthorized"
 const ERR_MSG_FORBIDDEN = "forbidden"
 const ERR_MSG_NOT_FOUND = "not found"
+const ERR_MSG_CONFLICT = "conflict"
+const ERR_MSG_WITHDRAWN = "contender withdrawn"
+const ERR_MSG_NOT_OPEN = "poll is not open yet"
+const ERR_MSG_CLOSED = "poll is closed"
+const ERR_MSG_RESULTS_HIDDEN = "results are not available until the poll is closed"
+const ERR_MSG_NOT_ELIGIBLE = "voter is not on the roll"
+const ERR_MSG_TOO_MANY_CHOICES = "too many choices"
 const ERR_MSG_METHOD_NOT_ALLOWED = "method not allowed"
 const ERR_MSG_UNAVAILABLE = "service unavailable"
 const ERR_MSG_SERVER_ERROR = "internal server error"
+const ERR_MSG_SCHEMA_MISMATCH = "database schema mismatch"
 
 const LOW_MEM_THRESHOLD uint64 = 1048576 // Mem size in KB (~1GB);
 
@@ -40,16 +50,45 @@ var (
 	ErrUnauthorized        = errors.New(ERR_MSG_UNAUTHORIZED)
 	ErrForbidden           = errors.New(ERR_MSG_FORBIDDEN)
 	ErrNotFound            = errors.New(ERR_MSG_NOT_FOUND)
+	ErrConflict            = errors.New(ERR_MSG_CONFLICT) // E.g. the vote with this 'vote_id' exists;
+	ErrWithdrawn           = errors.New(ERR_MSG_WITHDRAWN)
+	ErrNotOpen             = fmt.Errorf("%w: %s", ErrForbidden, ERR_MSG_NOT_OPEN) // See 'VoteData.CurrentState';
+	ErrClosed              = fmt.Errorf("%w: %s", ErrForbidden, ERR_MSG_CLOSED)
+	ErrResultsHidden       = fmt.Errorf("%w: %s", ErrForbidden, ERR_MSG_RESULTS_HIDDEN)    // See 'AllowResults';
+	ErrTooManyChoices      = fmt.Errorf("%w: %s", ErrBadRequest, ERR_MSG_TOO_MANY_CHOICES) // See 'MaxChoices';
 	ErrMethodNotAllowed    = errors.New(ERR_MSG_METHOD_NOT_ALLOWED)
 	ErrServiceUnavailable  = errors.New(ERR_MSG_UNAVAILABLE)
 	ErrInternalServerError = errors.New(ERR_MSG_SERVER_ERROR)
+	ErrSchemaMismatch      = errors.New(ERR_MSG_SCHEMA_MISMATCH) // The database tables don't match the queries;
 )
 
 // VoteSvcService describes the service.
 type VoteService interface {
 	GetVoteData(ctx context.Context, vote_id int) (*VoteData, error)
-	UpdateVoteResults(ctx context.Context, vote_id int, co_id int16, user_id string) error
+	GetVoteResults(ctx context.Context, vote_id int) (*VoteData, error)
+	UpdateVoteResults(ctx context.Context, vote_id int, co_id int16, user_id string) (*Receipt, error)
+	CastBallot(ctx context.Context, vote_id int, co_ids []int16, user_id string) (*Receipt, error)
+	RateContenders(ctx context.Context, vote_id int, scores map[int16]int, user_id string) (*Receipt, error)
+	CastEncryptedBallot(ctx context.Context, vote_id int, ballot EncryptedBallot, user_id string) (*Receipt, error)
+	RetractVote(ctx context.Context, vote_id int, user_id string) error
+	GetReceipt(ctx context.Context, vote_id int, receipt string) (*Receipt, error)
+	GetAuditHead(ctx context.Context, vote_id int) (*AuditHead, error)
+	GetCommitment(ctx context.Context, vote_id int) (*Commitment, error)
+	GetReceiptProof(ctx context.Context, vote_id int, receipt string) (*ReceiptProof, error)
 	GetServiceStatus(ctx context.Context) *HealthStatus
+
+	// Poll administration, the caller must be an admin (see 'WithAdmin' and 'admin.go').
+	CreateVote(ctx context.Context, vote VoteData) (*VoteData, error)
+	UpdateVote(ctx context.Context, vote_id int, patch VotePatch) (*VoteData, error)
+	DeleteVote(ctx context.Context, vote_id int) error
+	CloseVote(ctx context.Context, vote_id int) (*VoteData, error)
+	ReopenVote(ctx context.Context, vote_id int) (*VoteData, error)
+	AddContender(ctx context.Context, vote_id int, contender Contender) (*VoteData, error)
+	UpdateContender(ctx context.Context, vote_id int, co_id int16, patch ContenderPatch) (*VoteData, error)
+	WithdrawContender(ctx context.Context, vote_id int, co_id int16) (*VoteData, error)
+	SetRoll(ctx context.Context, vote_id int, entries []string) (*RollStats, error)
+	GenerateCodes(ctx context.Context, vote_id int, n int) ([]string, error)
+	RevokeCodes(ctx context.Context, vote_id int, r CodeRevocation) (int64, error)
 }
 
 type Contender struct {
@@ -60,6 +99,11 @@ type Contender struct {
 	Picture string    `json:"picture"` // A filename to be added to URL;
 	Count   int64     `json:"count"`   // Number of votes for this contender;
 	Updated time.Time `json:"updated"` // Last count update timestamp;
+
+	Score *ScoreStats `json:"score,omitempty"` // The ratings in a score poll, see 'GetVoteResults';
+
+	// A withdrawn contender stays in the results with its count, but nobody can vote for it.
+	Withdrawn bool `json:"withdrawn"`
 }
 
 type VoteData struct {
@@ -69,35 +113,45 @@ type VoteData struct {
 	Resources    string      `json:"resources"` // URL to external resources, e.g. images;
 	Deadline     time.Time   `json:"deadline"`
 	Authenticate bool        `json:"authenticate"`
-	AllowResults bool        `json:"allow_results"`
+	AllowResults bool        `json:"allow_results"` // If false, the counts are hidden until the poll is closed;
+	State        string      `json:"state"`         // See 'state.go';
+	OpensAt      time.Time   `json:"opens_at"`      // Used by the 'scheduled' state only;
+	RequireCode  bool        `json:"require_code"`  // The voters must send invitation codes (see 'code.go');
+	Method       string      `json:"method"`        // See 'method.go';
+	MaxChoices   int         `json:"max_choices"`   // Up to N contenders in one ballot, see 'maxChoices';
+	ScoreMin     int         `json:"score_min"`     // The scale of a score poll (see 'score.go');
+	ScoreMax     int         `json:"score_max"`
+	Revisable    bool        `json:"revisable"`            // The voters can change or retract their votes (see 'revise.go');
+	PublicKey    string      `json:"public_key,omitempty"` // The ballots are encrypted with this key (see 'encrypted.go');
 	Contenders   []Contender `json:"contenders"`
-}
+	Roll         *RollStats  `json:"roll,omitempty"` // The turnout, if the poll has a roll (see 'roll.go');
 
-type VoteDataAux struct {
-	voteId       int
-	co_id        int16
-	header       string
-	message      string
-	resources    string
-	deadline     time.Time
-	authenticate bool
-	allowresults bool
-	co_name      string
-	co_alias     string
-	co_info      string
-	co_picture   string
-	co_count     int64
-	co_updated   time.Time
+	Runoff    *RunoffResult    `json:"runoff,omitempty"`    // The rounds of a ranked poll (see 'ranked.go');
+	Condorcet *CondorcetResult `json:"condorcet,omitempty"` // The pairwise matrix of a Condorcet poll (see 'condorcet.go');
+	Tally     *EncryptedTally  `json:"tally,omitempty"`     // The decrypted counts of an encrypted poll (see 'encrypted.go');
 }
 
 type HealthStatus struct {
-	Status  int32  `json:"health_status"`
-	Message string `json:"health_message"`
+	Status  int32        `json:"health_status"`
+	Message string       `json:"health_message"`
+	Nodes   []NodeStatus `json:"nodes,omitempty"` // Database nodes, if the store reports them;
 }
 
-// The database is Apache Cassandra noSQL/CQL.
+// The data is kept by the 'VoteStore' (see 'store.go'), by default it's Apache Cassandra.
 type basicVoteService struct {
-	db *gocql.ClusterConfig
+	store    VoteStore
+	verifier TokenVerifier // The voters of the polls with 'Authenticate' (see 'jwt.go');
+}
+
+// Option changes the service created by 'New' or 'NewBasicVoteService'.
+type Option func(*basicVoteService)
+
+// WithTokenVerifier sets the verifier of the voters' tokens. Without it, nobody can vote
+// in the polls with 'Authenticate' (ErrUnauthorized).
+func WithTokenVerifier(v TokenVerifier) Option {
+	return func(b *basicVoteService) {
+		b.verifier = v
+	}
 }
 
 /////////////////
@@ -106,98 +160,98 @@ type basicVoteService struct {
 //
 /////////////////
 
-// 1. It provides data required to fill the [ browser client ] form for a specified 'vote_id'.
-// 2. It provides results related to a specified 'vote_id', including data required for diagram.
-
-// In both cases this func returns the same struct containing all the data available, and the
-// client app decides what to use and what to ignore, e.g. in frist case it ignores counts of
-// votes for contenders, while in second case those counts are used to create a graphic diagram.
+// It provides data required to fill the [ browser client ] form for a specified 'vote_id'.
 
 // The 'VoteData' struct includes info about vote purpose, candidates/contenders, etc. All data is
-// fetched from the database. The number of records related to a specific 'vote_id' is equal to the
-// number of contenders which is not supposed to be large, maybe 2..20). This data is packed into
-// a single struct 'VoteData' containing an array (i.e. slice) of the type struct 'Contenders'
-// with the data about contenders (id, name, picture, ...).
+// fetched from the store (see 'VoteStore.LoadVote'). The 'state' is the current one (e.g. an
+// open poll is closed after the deadline), and a draft is not found unless the caller is admin.
+// The counts of votes are not here (they are zero), see 'GetVoteResults'.
 
 func (b *basicVoteService) GetVoteData(ctx context.Context, vote_id int) (*VoteData, error) {
+	vote, err := b.loadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
+	}
 
-	if b.db == nil {
-		return nil, ErrServiceUnavailable
+	for i := range vote.Contenders {
+		vote.Contenders[i].Count = 0
+		vote.Contenders[i].Updated = time.Time{}
 	}
+	return vote, nil
+}
+
+////////////////////
+//
+// GET VOTE RESULTS
+//
+////////////////////
 
-	session, err := b.db.CreateSession()
+// It provides results related to a specified 'vote_id', including data required for diagram.
+// The same 'VoteData' with the counts and the turnout against the roll (if the poll has a
+// roll, see 'roll.go'), but if 'AllowResults' is false, nobody except the admin can see
+// them until the poll is closed (ErrResultsHidden). The ranked polls have the rounds of
+// the instant runoff as well (see 'ranked.go'), the Condorcet polls have the pairwise matrix
+// and the Schulze winners (see 'condorcet.go'), and the contenders of the score polls have
+// the statistics of their ratings (see 'score.go'). The counts of the encrypted polls are
+// hidden from everybody (the admin too) until the poll is tallied, see 'encrypted.go'.
+
+func (b *basicVoteService) GetVoteResults(ctx context.Context, vote_id int) (*VoteData, error) {
+	vote, err := b.loadVote(ctx, vote_id)
 	if err != nil {
 		return nil, err
 	}
 
-	defer session.Close()
-
-	records := []VoteDataAux{} // This is an intermediate slice to receive the database records;
-	m := map[string]interface{}{}
+	if !vote.AllowResults && !IsAdmin(ctx) {
+		switch vote.State {
+		case VOTE_STATE_CLOSED, VOTE_STATE_ARCHIVED:
+		default:
+			return nil, ErrResultsHidden
+		}
+	}
 
-	// Note that 'stmt' here is a CQL statement (Cassandra Query Language), not SQL.
-	// In general, it's supposed to fetch at least two records (two contenders are minimum,
-	// there is no sense to have election if you have only one candidate). Once again, this
-	// is not SQL database, the tables are not normalized and some data is duplicated.
+	if vote.PublicKey != "" {
+		if err = b.encryptedResults(ctx, vote); err != nil {
+			return nil, err
+		}
+	}
 
-	stmt := `SELECT vote_id, co_id, header, message, resources, deadline, authenticate,
-	 allowresults, co_name, co_alias, co_info, co_picture, co_count, co_updated
-	 FROM polls.votes WHERE vote_id = ?`
+	if vote.Roll, err = b.rollStats(ctx, vote_id); err != nil {
+		return nil, err
+	}
 
-	// iterable := session.Query(stmt, vote_id).WithContext(ctx).Consistency(gocql.One).Iter()
-	iterable := session.Query(stmt, vote_id).Iter()
+	switch vote.Method {
+	case VOTE_METHOD_RANKED:
+		if vote.Runoff, err = b.runoff(ctx, vote); err != nil {
+			return nil, err
+		}
+	case VOTE_METHOD_CONDORCET:
+		if vote.Condorcet, err = b.condorcet(ctx, vote); err != nil {
+			return nil, err
+		}
+	case VOTE_METHOD_SCORE:
+		if err = b.scoreStats(ctx, vote); err != nil {
+			return nil, err
+		}
+	}
+	return vote, nil
+}
 
-	for iterable.MapScan(m) {
-		records = append(records, VoteDataAux{
-			voteId:       m["vote_id"].(int),
-			co_id:        m["co_id"].(int16),
-			header:       m["header"].(string),
-			message:      m["message"].(string),
-			resources:    m["resources"].(string),
-			deadline:     m["deadline"].(time.Time),
-			authenticate: m["authenticate"].(bool),
-			allowresults: m["allowresults"].(bool),
-			co_name:      m["co_name"].(string),
-			co_alias:     m["co_alias"].(string),
-			co_info:      m["co_info"].(string),
-			co_picture:   m["co_picture"].(string),
-			co_count:     m["co_count"].(int64),
-			co_updated:   m["co_updated"].(time.Time),
-		})
+// It loads the vote with the current state, the draft is seen by the admin only.
+func (b *basicVoteService) loadVote(ctx context.Context, vote_id int) (*VoteData, error) {
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
+	}
 
-		m = map[string]interface{}{} // Do not remove this!
+	vote, err := b.store.LoadVote(ctx, vote_id)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(records) == 0 {
+	vote.State = vote.CurrentState(time.Now())
+	if vote.State == VOTE_STATE_DRAFT && !IsAdmin(ctx) {
 		return nil, ErrNotFound
 	}
-
-	// Let's repack database records into a single struct 'VoteData'.
-	var contenders []Contender
-	for _, r := range records {
-		contenders = append(contenders, Contender{
-			Id:      r.co_id,
-			Name:    r.co_name,
-			Alias:   r.co_alias,
-			Info:    r.co_info,
-			Count:   r.co_count,
-			Updated: r.co_updated,
-			Picture: r.co_picture,
-		})
-	}
-
-	var res VoteData = VoteData{
-		VoteId:       records[0].voteId,
-		Header:       records[0].header,
-		Message:      records[0].message,
-		Resources:    records[0].resources,
-		Deadline:     records[0].deadline,
-		Authenticate: records[0].authenticate,
-		AllowResults: records[0].allowresults,
-		Contenders:   contenders,
-	}
-
-	return &res, nil
+	return vote, nil
 }
 
 ///////////////////////
@@ -206,45 +260,169 @@ func (b *basicVoteService) GetVoteData(ctx context.Context, vote_id int) (*VoteD
 //
 ///////////////////////
 
-// This func performs following ops with the database:
+// It's a ballot with a single contender, see 'CastBallot'.
 
-// 1. It checks if the specified 'vote_id' and 'co_id' are valid (i.e. present in the database)
-// and the current datetime is before the deadline. If not, it returns an error.
+func (b *basicVoteService) UpdateVoteResults(ctx context.Context, vote_id int, co_id int16, user_id string) (*Receipt, error) {
+	return b.CastBallot(ctx, vote_id, []int16{co_id}, user_id)
+}
+
+///////////////
+//
+// CAST BALLOT
+//
+///////////////
+
+// This func performs following ops with the store:
+
+// 1. It checks if the specified 'vote_id' and 'co_ids' are valid (i.e. present in the database,
+// and the contenders are not withdrawn) and the poll is open (see 'VoteData.CurrentState'). If not,
+// it returns an error: ErrNotOpen before the poll opens, ErrClosed after the deadline. The ballot
+// has 1..'MaxChoices' different contenders (approval voting), otherwise ErrTooManyChoices. In
+// a ranked (or Condorcet) poll, the 'co_ids' are in the order of preference (see 'ranked.go').
+
+// If the poll requires authentication, the 'user_id' is the subject of the bearer token
+// (see 'WithBearerToken' and 'TokenVerifier'), the 'user_id' sent by the client is ignored.
+// Without a valid token the vote is rejected with ErrUnauthorized. If the poll has a roll,
+// the voter must be on it (ErrNotEligible, see 'roll.go'). If the poll requires invitation
+// codes, the code is burned with the vote, and the voter is the code unless it's
+// authenticated (ErrCodeRequired, ErrInvalidCode, see 'code.go'). If the poll is revisable,
+// the voter who has voted already changes the vote instead (see 'revise.go').
 
 // 2. It tries to insert a new record into the 'voters' table (new 'user_id')
 // to prevent this user/voter from voting again. In case of failure, it returns an error.
 
-// 3. It updates the 'votes' table incrementing the 'co_count' of the specified contender.
+// 3. It updates the 'votes' table incrementing the 'co_count' of each selected contender,
+// all of them or none (see 'VoteStore.IncrementCounts'), so there is no half a ballot. The
+// ranked ballot is saved, and only its first preference is counted. The score ballot adds
+// the ratings instead (see 'VoteStore.AddScores').
+
+// The receipt of the vote is saved with it, and returned to the voter (see 'receipt.go').
+
+func (b *basicVoteService) CastBallot(ctx context.Context, vote_id int, co_ids []int16, user_id string) (*Receipt, error) {
+	return b.castVote(ctx, vote_id, user_id, func(vote *VoteData) (*VoteRecord, error) {
+		if vote.Method == VOTE_METHOD_SCORE {
+			return nil, fmt.Errorf("%w: the contenders of a score poll are rated", ErrBadRequest)
+		}
+		if vote.PublicKey != "" {
+			return nil, fmt.Errorf("%w: the ballots of the poll are encrypted", ErrBadRequest)
+		}
+		if err := vote.checkChoices(co_ids); err != nil {
+			return nil, err
+		}
 
-func (b *basicVoteService) UpdateVoteResults(ctx context.Context, vote_id int, co_id int16, user_id string) (e0 error) {
-	if b.db == nil {
-		return ErrServiceUnavailable
+		r := &VoteRecord{Counts: co_ids}
+		if vote.keepsBallots() {
+			r.Counts = co_ids[:1]
+			r.BallotId = uuid.NewString()
+			r.Ranking = co_ids
+		}
+		return r, nil
+	})
+}
+
+// It's the common part of all ballots (see 'CastBallot', 'RateContenders' and
+// 'CastEncryptedBallot'): the poll is loaded, the 'ballot' checks it and returns what must be
+// recorded, then the poll must be open, and the voter must be authenticated, on the roll, and
+// with a code if required. It returns the receipt of the recorded vote.
+func (b *basicVoteService) castVote(ctx context.Context, vote_id int, user_id string,
+	ballot func(vote *VoteData) (*VoteRecord, error)) (*Receipt, error) {
+	if b.store == nil {
+		return nil, ErrServiceUnavailable
 	}
 
-	session, err := b.db.CreateSession()
+	// Step # 1: let's check if the 'vote_id' and the ballot are valid and the poll is open;
+	vote, err := b.store.LoadVote(ctx, vote_id)
+	if err == ErrNotFound {
+		return nil, ErrBadRequest // I prefer to return ErrBadRequest here;
+	}
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	r, err := ballot(vote)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = vote.checkOpen(time.Now()); err != nil {
+		return nil, err // E.g. after the deadline no voting;
+	}
+
+	if vote.Authenticate {
+		if user_id, err = b.authenticate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	code := ""
+	if vote.RequireCode {
+		if code, err = voteCode(ctx); err != nil {
+			return nil, err
+		}
+		if !vote.Authenticate {
+			user_id = code
+		}
+	}
+
+	if user_id, err = b.checkRoll(ctx, vote_id, user_id); err != nil {
+		return nil, err
 	}
 
-	defer session.Close()
+	receipt := newReceipt(vote, user_id)
 
-	// Step # 1: let's check if the 'vote_id' and 'co_id' are valid and deadline is in the future;
-	stmt := "SELECT deadline FROM polls.votes WHERE vote_id = ? AND co_id = ?"
+	// The vote of the revisable poll moves the count, if the voter has voted already;
+	if vote.Revisable {
+		if err = b.store.AddReceipt(ctx, vote_id, receipt); err != nil {
+			return nil, err
+		}
+		if err = b.reviseVote(ctx, vote_id, user_id, r.Counts[0]); err != nil {
+			b.store.RemoveReceipt(ctx, vote_id, receipt.Id)
+			return nil, err
+		}
+		return receipt.issued(), nil
+	}
 
-	var deadline time.Time // This would be the number of records found in the 'votes' table;
-	err = session.Query(stmt, vote_id, co_id).WithContext(ctx).Scan(&deadline)
-	if err != nil { // This is supposed to be ErrNotFound if CQL returns 0 rows;
-		return ErrBadRequest // But I prefer to return ErrBadRequest;
+	r.UserId = user_id
+	r.Code = code
+	r.Receipt = receipt
+	r.Audit = r.auditEntry()
+
+	// Steps # 2 and # 3 in one transaction, if the store can do it (see 'VoteRecorder');
+	if rec, ok := b.store.(VoteRecorder); ok {
+		for i := 0; i < AUDIT_RETRIES; i++ {
+			if err = rec.RecordVote(ctx, vote_id, r); err != ErrAuditConflict {
+				break
+			}
+		}
+	} else {
+		err = b.recordVote(ctx, vote_id, r)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return receipt.issued(), nil
+}
 
-	if deadline.Before(time.Now()) {
-		return ErrForbidden // After the deadline no voting;
+// It records the vote step by step, and each step is undone if the next one fails.
+func (b *basicVoteService) recordVote(ctx context.Context, vote_id int, r *VoteRecord) (e0 error) {
+	// The code is burned first, it's the only way to be sure that it's used once.
+	if r.Code != "" {
+		applied, err := b.store.BurnCode(ctx, vote_id, r.Code)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			return ErrInvalidCode
+		}
+		defer func() {
+			if e0 != nil {
+				b.store.RestoreCode(ctx, vote_id, r.Code) // The vote is not recorded;
+			}
+		}()
 	}
 
 	// Step # 2: let's try to insert a new record into the 'voters' table;
-	stmt = "INSERT INTO polls.voters (vote_id, user_id, created) VALUES(?, ?, toTimeStamp(now())) IF NOT EXISTS"
-	m := make(map[string]interface{})
-	applied, err := session.Query(stmt, vote_id, user_id).WithContext(ctx).MapScanCAS(m)
+	applied, err := b.store.AddVoter(ctx, vote_id, r.UserId)
 	if err != nil {
 		return err
 	}
@@ -252,23 +430,100 @@ func (b *basicVoteService) UpdateVoteResults(ctx context.Context, vote_id int, c
 		return ErrForbidden // Looks like this voter has voted earlier;
 	}
 
-	// Step # 3: let's increment the 'co_count' for the specified contender in the 'votes' table.
-	stmt = `UPDATE polls.votes SET co_count = co_count + 1, co_updated = toTimeStamp(now())
-		WHERE vote_id = ? AND co_id = ? IF EXISTS`
-	m = make(map[string]interface{})
-	applied, err = session.Query(stmt, vote_id, co_id).WithContext(ctx).MapScanCAS(m)
-	if !(err == nil && applied) {
-		// If this failed, the voter has the right to vote again.
-		// It means that the voter's 'user_id' must be removed from the 'voters' table.
-		stmt = "DELETE FROM polls.voters WHERE vote_id = ? AND user_id = ?"
-		session.Query(stmt, vote_id, user_id).WithContext(ctx).Exec()
+	// If anything below fails, the voter has the right to vote again.
+	// It means that the voter's 'user_id' must be removed from the 'voters' table.
+	defer func() {
+		if e0 != nil {
+			b.store.RemoveVoter(ctx, vote_id, r.UserId)
+		}
+	}()
+
+	if r.Ranking != nil {
+		if err = b.store.AddBallot(ctx, vote_id, r.BallotId, r.Ranking); err != nil {
+			return err
+		}
+		defer func() {
+			if e0 != nil {
+				b.store.RemoveBallot(ctx, vote_id, r.BallotId)
+			}
+		}()
+	}
+
+	if r.Encrypted != nil {
+		if err = b.store.AddEncryptedBallot(ctx, vote_id, r.BallotId, r.Encrypted); err != nil {
+			return err
+		}
+		defer func() {
+			if e0 != nil {
+				b.store.RemoveEncryptedBallot(ctx, vote_id, r.BallotId)
+			}
+		}()
+	}
+
+	if r.Receipt != nil {
+		if err = b.store.AddReceipt(ctx, vote_id, r.Receipt); err != nil {
+			return err
+		}
+		defer func() {
+			if e0 != nil {
+				b.store.RemoveReceipt(ctx, vote_id, r.Receipt.Id)
+			}
+		}()
+	}
+
+	// The log is never changed, the entry of the failed vote is reversed by another one;
+	if r.Audit != nil {
+		if err = b.appendAudit(ctx, vote_id, r.Audit); err != nil {
+			return err
+		}
+		defer func() {
+			if e0 != nil {
+				b.appendAudit(ctx, vote_id, r.Audit.void())
+			}
+		}()
+	}
+
+	// Step # 3: let's increment the 'co_count' for the selected contenders in the 'votes' table,
+	// or add the ratings of the score ballot. The encrypted ballot changes no counts.
+	if r.Encrypted != nil {
+		return nil
+	}
+	if r.Scores != nil {
+		applied, err = b.store.AddScores(ctx, vote_id, r.Scores)
+	} else {
+		applied, err = b.store.IncrementCounts(ctx, vote_id, r.Counts)
+	}
+	if err != nil {
 		return err
 	}
+	if !applied {
+		return ErrBadRequest // The contender has disappeared in the meantime;
+	}
 
 	// Success!
 	return nil
 }
 
+////////////////
+//
+// AUTHENTICATE
+//
+////////////////
+
+// It returns the user id from the bearer token of the voter.
+func (b *basicVoteService) authenticate(ctx context.Context) (string, error) {
+	token := BearerToken(ctx)
+	if token == "" || b.verifier == nil {
+		return "", ErrUnauthorized
+	}
+
+	user_id, err := b.verifier.Verify(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	return user_id, nil
+}
+
 //////////////////////
 //
 // GET SERVICE STATUS
@@ -290,23 +545,28 @@ func (b *basicVoteService) GetServiceStatus(ctx context.Context) *HealthStatus {
 	}
 
 	// Let's check if the database is available.
-	if b.db == nil {
+	if b.store == nil || b.store.Ping(ctx) != nil {
 		hs.Status = http.StatusServiceUnavailable
 		hs.Message = SERVICE_STATUS_NO_DATABASE
-		return &hs
-	} else {
-		// Let's try to connect to the database.
-		session, err := b.db.CreateSession()
-		if err != nil {
-			hs.Status = http.StatusServiceUnavailable
-			hs.Message = SERVICE_STATUS_NO_DATABASE
-			return &hs
+		if r, ok := b.store.(NodeReporter); ok {
+			hs.Nodes = r.NodeStatus()
 		}
-		session.Close()
+		return &hs
 	}
 
 	hs.Status = http.StatusOK
 	hs.Message = SERVICE_STATUS_OK
+
+	// The service works while some database nodes are down, but operators should know it.
+	if r, ok := b.store.(NodeReporter); ok {
+		hs.Nodes = r.NodeStatus()
+		for _, n := range hs.Nodes {
+			if !n.Up {
+				hs.Message = SERVICE_STATUS_DEGRADED
+				break
+			}
+		}
+	}
 	return &hs
 }
 
@@ -317,10 +577,14 @@ func (b *basicVoteService) GetServiceStatus(ctx context.Context) *HealthStatus {
 //////////////////////////
 
 // NewBasicVoteService returns a naive, stateless implementation of VoteService.
-func NewBasicVoteService(db *gocql.ClusterConfig) VoteService {
-	return &basicVoteService{
-		db: db,
+func NewBasicVoteService(store VoteStore, opts ...Option) VoteService {
+	b := &basicVoteService{
+		store: store,
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 ////////////////////
@@ -330,14 +594,60 @@ func NewBasicVoteService(db *gocql.ClusterConfig) VoteService {
 ////////////////////
 
 // New returns a VoteService with all of the expected middleware wired in.
-func New(db *gocql.ClusterConfig, middleware []Middleware) VoteService {
-	var svc VoteService = NewBasicVoteService(db)
+func New(store VoteStore, middleware []Middleware, opts ...Option) VoteService {
+	var svc VoteService = NewBasicVoteService(store, opts...)
 	for _, m := range middleware {
 		svc = m(svc)
 	}
 	return svc
 }
 
+//////////////////
+//
+// FIND CONTENDER
+//
+//////////////////
+
+// It returns a pointer to the contender 'co_id', or nil if there is no such contender.
+func (v *VoteData) findContender(co_id int16) *Contender {
+	for i := range v.Contenders {
+		if v.Contenders[i].Id == co_id {
+			return &v.Contenders[i]
+		}
+	}
+	return nil
+}
+
+/////////////////
+//
+// CHECK CHOICES
+//
+/////////////////
+
+// It checks the contenders of the ballot: at least one, 'maxChoices' at most, no duplicates,
+// and no withdrawn contenders (ErrWithdrawn).
+func (v *VoteData) checkChoices(co_ids []int16) error {
+	if len(co_ids) == 0 {
+		return ErrBadRequest
+	}
+	if len(co_ids) > v.maxChoices() {
+		return ErrTooManyChoices
+	}
+
+	seen := make(map[int16]bool, len(co_ids))
+	for _, co_id := range co_ids {
+		contender := v.findContender(co_id)
+		if contender == nil || seen[co_id] {
+			return ErrBadRequest
+		}
+		if contender.Withdrawn {
+			return ErrWithdrawn
+		}
+		seen[co_id] = true
+	}
+	return nil
+}
+
 /////////////////////
 //
 // GET AVAILABLE MEM
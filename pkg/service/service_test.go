@@ -1,61 +1,66 @@
 //  Created : 2024-Mar-14
-// Modified : 2024-Mar-30
+// Modified : 2026-Oct-18
+
+// These tests use the in-memory store filled with 'testdata/votes.json', so
+// they do not need a database. See 'cassandra_test.go' for the database tests.
 
 package service
 
 import (
-	// "bytes"
-	// "context"
-	// "fmt"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
-
-	// "os"
 	"testing"
 
-	"github.com/gocql/gocql"
 	"github.com/google/uuid"
-	// "github.com/hailocab/go-hostpool"
 )
 
-const ENTRY_NODE_1 = "172.16.70.31"
-
-// const ENTRY_NODE_2 = "172.16.70.32"
-const KEYSPACE_NAME = "polls"
 const TESTDATA_DIR = "../../testdata/"
+const TESTDATA_VOTES = TESTDATA_DIR + "votes.json"
+
+// This is supposed to be already present in the 'voters' (see 'testdata/votes.json');
+const TESTDATA_USER_ID = "8b820e4e-8f43-4cd1-a8b3-f90c44e13ece"
+
+func load_store(t *testing.T) VoteStore {
+	store, err := LoadMemoryVoteStore(TESTDATA_VOTES)
+	if err != nil {
+		t.Fatalf("cannot load %v, error: %v", TESTDATA_VOTES, err)
+	}
+	return store
+}
+
+func get_co_count(store VoteStore, vote_id int, co_id int16) (int64, error) {
+	vote, err := store.LoadVote(context.Background(), vote_id)
+	if err != nil {
+		return 0, err
+	}
+
+	c := vote.findContender(co_id)
+	if c == nil {
+		return 0, ErrNotFound
+	}
+	return c.Count, nil
+}
 
-func connect_db() *gocql.ClusterConfig {
-	cluster := gocql.NewCluster(ENTRY_NODE_1)
-	cluster.Consistency = gocql.Quorum
-	// cluster.ProtoVersion = 4
-	cluster.Timeout = time.Second * 10
-	cluster.ConnectTimeout = time.Second * 10
-	// cluster.Keyspace = KEYSPACE_NAME
-	// cluster.PoolConfig.HostSelectionPolicy = gocql.HostPoolHostPolicy(hostpool.New(nil))
+// This store fails to increment counts, it's used to check that the voter is removed then.
+type brokenVoteStore struct {
+	VoteStore
+}
 
-	// cluster.Authenticator = gocql.PasswordAuthenticator{
-	// Username: "user",
-	// Password: "password",
-	// }
+func (b brokenVoteStore) IncrementCounts(ctx context.Context, vote_id int, co_ids []int16) (bool, error) {
+	return false, errors.New("write timeout")
+}
 
-	return cluster
+func (b brokenVoteStore) AddScores(ctx context.Context, vote_id int, scores map[int16]int) (bool, error) {
+	return false, errors.New("write timeout")
 }
 
 func TestGetVoteData(t *testing.T) {
 	testinfo := "test GetVoteData"
-	// var vote_id int = 1
 	var resources string = "https://ws4/votes/1/images/"
 
-	cluster := connect_db()
-
-	if cluster == nil {
-		t.Errorf("cannot connect to database")
-		return
-	}
-
-	svc := New(cluster, []Middleware{})
+	svc := New(load_store(t), []Middleware{})
 
 	t.Run(testinfo, func(t *testing.T) {
 		// Case 1: vote_id = 1 (good), func must return 'VoteData' and err must be 'nil';
@@ -70,6 +75,11 @@ func TestGetVoteData(t *testing.T) {
 			return
 		}
 
+		if len(res.Contenders) != 4 {
+			t.Errorf("test %v (case # 1) failed, %v contenders, but it should be %v", testinfo, len(res.Contenders), 4)
+			return
+		}
+
 		// Case 2: vote_id = 2 (bad), func must return 'nil' err must be 'ErrNotFound';
 		_, err = svc.GetVoteData(context.Background(), 2)
 		if err != ErrNotFound {
@@ -82,46 +92,29 @@ func TestGetVoteData(t *testing.T) {
 func TestAddVoteOk(t *testing.T) {
 	testinfo := "test AddVoteOk"
 	vote_id := 1
-	co_id := 1
-	co_count := 0
+	var co_id int16 = 1
 	random_user_id := uuid.NewString()
-	cluster := connect_db()
-
-	if cluster == nil {
-		t.Errorf("cannot connect to database")
-		return
-	}
-
-	svc := New(cluster, []Middleware{})
+	store := load_store(t)
 
-	session, err := cluster.CreateSession()
-	if err != nil {
-		t.Errorf("test %v failed, cannot create session, error: %v", testinfo, err)
-		return
-	}
-	defer session.Close()
+	svc := New(store, []Middleware{})
 
 	// Get current 'co_count' related to 'vote_id' and 'co_id'; save it as 'old_co_count';
-	stmt := "SELECT vote_id, co_id, co_count FROM polls.votes WHERE vote_id = ? AND co_id = ? LIMIT 1"
-
-	err = session.Query(stmt, vote_id, co_id).WithContext(context.Background()).Consistency(gocql.One).Scan(&vote_id, &co_id, &co_count)
+	old_co_count, err := get_co_count(store, vote_id, co_id)
 	if err != nil {
 		t.Errorf("test %v failed, cannot get current co_count, error: %v", testinfo, err)
 		return
 	}
-	old_co_count := co_count
 
 	t.Run(testinfo, func(t *testing.T) {
 		// Add one vote to 'co_id' related to 'vote_id';
-		err := svc.UpdateVoteResults(context.Background(), vote_id, int16(co_id), random_user_id)
+		_, err := svc.UpdateVoteResults(context.Background(), vote_id, co_id, random_user_id)
 		if err != nil {
 			t.Errorf("test %v failed, error: %v", testinfo, err)
 			return
 		}
 
 		// Once again get current 'co_count' related to 'vote_id' and 'co_id'; it must be increased by one;
-		stmt := "SELECT vote_id, co_id, co_count FROM polls.votes WHERE vote_id = ? AND co_id = ? LIMIT 1"
-		err = session.Query(stmt, vote_id, co_id).WithContext(context.Background()).Consistency(gocql.One).Scan(&vote_id, &co_id, &co_count)
+		co_count, err := get_co_count(store, vote_id, co_id)
 		if err != nil {
 			t.Errorf("test %v failed, cannot get new co_count, error: %v", testinfo, err)
 			return
@@ -139,68 +132,166 @@ func TestAddVoteReject(t *testing.T) {
 	// This should be impossible;
 	testinfo := "test AddVoteReject"
 	vote_id := 1
-	co_id := 1
-	co_count := 0
-
-	// This is supposed to be already present in the 'polls.voters' table;
-	user_id := "8b820e4e-8f43-4cd1-a8b3-f90c44e13ece"
-
-	cluster := connect_db()
-
-	if cluster == nil {
-		t.Errorf("cannot connect to database")
-		return
-	}
+	var co_id int16 = 1
+	store := load_store(t)
 
-	svc := New(cluster, []Middleware{})
-
-	session, err := cluster.CreateSession()
-	if err != nil {
-		t.Errorf("test %v failed, cannot create session, error: %v", testinfo, err)
-		return
-	}
-	defer session.Close()
+	svc := New(store, []Middleware{})
 
 	// Get current 'co_count' related to 'vote_id' and 'co_id'; save it as 'old_co_count';
-	stmt := "SELECT vote_id, co_id, co_count FROM polls.votes WHERE vote_id = ? AND co_id = ? LIMIT 1"
-
-	err = session.Query(stmt, vote_id, co_id).WithContext(context.Background()).Consistency(gocql.One).Scan(&vote_id, &co_id, &co_count)
+	old_co_count, err := get_co_count(store, vote_id, co_id)
 	if err != nil {
 		t.Errorf("test %v failed, cannot get current co_count, error: %v", testinfo, err)
 		return
 	}
-	old_co_count := co_count
 
 	t.Run(testinfo, func(t *testing.T) {
 		// Case 1: Try to add one vote to 'co_id' related to 'vote_id';
-		err := svc.UpdateVoteResults(context.Background(), vote_id, int16(co_id), user_id)
+		_, err := svc.UpdateVoteResults(context.Background(), vote_id, co_id, TESTDATA_USER_ID)
 		if err != ErrForbidden {
 			t.Errorf("test %v (case # 1) failed, error: %v (must be %v)", testinfo, err, ErrForbidden)
 		}
 
 		// Once again get current 'co_count' related to 'vote_id' and 'co_id'; it must be unchanged;
-		stmt := "SELECT vote_id, co_id, co_count FROM polls.votes WHERE vote_id = ? AND co_id = ? LIMIT 1"
-		err = session.Query(stmt, vote_id, co_id).WithContext(context.Background()).Consistency(gocql.One).Scan(&vote_id, &co_id, &co_count)
+		co_count, err := get_co_count(store, vote_id, co_id)
 		if err != nil {
 			t.Errorf("test %v (case # 1) failed, cannot get new co_count, error: %v", testinfo, err)
 			return
 		}
 
-		if co_count == (old_co_count + 1) {
+		if co_count != old_co_count {
 			t.Errorf("test %v failed, co_count = %v, but it should be %v", testinfo, co_count, old_co_count)
 			return
 		}
 
 		// Case 2: Try to add one vote to 'co_id' related to non-existent 'vote_id';
+		_, err = svc.UpdateVoteResults(context.Background(), 2, co_id, uuid.NewString())
+		if err != ErrBadRequest {
+			t.Errorf("test %v (case # 2) failed, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+		}
 
+		// Case 3: Try to add one vote to non-existent 'co_id';
+		_, err = svc.UpdateVoteResults(context.Background(), vote_id, 21, uuid.NewString())
+		if err != ErrBadRequest {
+			t.Errorf("test %v (case # 3) failed, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+		}
+
+		// Case 4: Try to vote after the deadline (vote_id = 3);
+		_, err = svc.UpdateVoteResults(context.Background(), 3, co_id, uuid.NewString())
+		if err != ErrClosed {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrClosed)
+		}
+	})
+}
+
+func TestAddVoteCompensate(t *testing.T) {
+	// If the count cannot be incremented, the voter must be removed from the 'voters',
+	// so this voter can try once again;
+	testinfo := "test AddVoteCompensate"
+	vote_id := 1
+	var co_id int16 = 1
+	user_id := uuid.NewString()
+	store := load_store(t)
+
+	svc := New(brokenVoteStore{store}, []Middleware{})
+
+	t.Run(testinfo, func(t *testing.T) {
+		_, err := svc.UpdateVoteResults(context.Background(), vote_id, co_id, user_id)
+		if err == nil {
+			t.Errorf("test %v failed, error is nil", testinfo)
+			return
+		}
+
+		applied, err := store.AddVoter(context.Background(), vote_id, user_id)
+		if err != nil || !applied {
+			t.Errorf("test %v failed, the voter was not removed (err: %v)", testinfo, err)
+		}
+	})
+}
+
+func TestCastBallot(t *testing.T) {
+	testCastBallot(t, load_store(t))
+}
+
+// It votes for several contenders (approval voting), the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testCastBallot(t *testing.T, store VoteStore) {
+	testinfo := "test CastBallot"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	vote := new_vote(TESTDATA_NEW_VOTE_ID)
+	vote.Contenders = append(vote.Contenders, Contender{Id: 3, Name: "Zig", Alias: "Zig", Picture: "zig.png"})
+	vote.MaxChoices = 4
+	if _, err := svc.CreateVote(ctx, vote); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("test %v failed, max_choices > contenders, error: %v (must be %v)", testinfo, err, ErrBadRequest)
+	}
+
+	vote.MaxChoices = 2
+	if _, err := svc.CreateVote(ctx, vote); err != nil {
+		t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+	}
+	defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+	t.Run(testinfo, func(t *testing.T) {
+		// Case 1: no contenders, the same one twice, unknown one, too many;
+		var cases = []struct {
+			co_ids []int16
+			want   error
+		}{
+			{nil, ErrBadRequest},
+			{[]int16{1, 1}, ErrBadRequest},
+			{[]int16{1, 21}, ErrBadRequest},
+			{[]int16{1, 2, 3}, ErrTooManyChoices},
+		}
+		for i, c := range cases {
+			if _, err := svc.CastBallot(public, TESTDATA_NEW_VOTE_ID, c.co_ids, TESTDATA_USER_ID); err != c.want {
+				t.Errorf("test %v (case # 1.%d) failed, error: %v (must be %v)", testinfo, i+1, err, c.want)
+			}
+		}
+
+		// Case 2: two contenders, and the voter cannot vote again;
+		if _, err := svc.CastBallot(public, TESTDATA_NEW_VOTE_ID, []int16{1, 3}, TESTDATA_USER_ID); err != nil {
+			t.Errorf("test %v (case # 2) failed, error: %v", testinfo, err)
+		}
+		if _, err := svc.CastBallot(public, TESTDATA_NEW_VOTE_ID, []int16{2}, TESTDATA_USER_ID); err != ErrForbidden {
+			t.Errorf("test %v (case # 2) failed, error: %v (must be %v)", testinfo, err, ErrForbidden)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 2, TESTDATA_USER_ID+"-2"); err != nil {
+			t.Errorf("test %v (case # 2) failed, error: %v", testinfo, err)
+		}
+
+		// Case 3: the store counts all the contenders or none;
+		if applied, err := store.IncrementCounts(public, TESTDATA_NEW_VOTE_ID, []int16{1, 21}); err != nil || applied {
+			t.Errorf("test %v (case # 3) failed, applied %v, error: %v", testinfo, applied, err)
+		}
+
+		for _, want := range []struct {
+			co_id int16
+			count int64
+		}{{1, 1}, {2, 1}, {3, 1}} {
+			if count, err := get_co_count(store, TESTDATA_NEW_VOTE_ID, want.co_id); err != nil || count != want.count {
+				t.Errorf("test %v (case # 3) failed, contender %d has %d votes (must be %d), error: %v",
+					testinfo, want.co_id, count, want.count, err)
+			}
+		}
+
+		// Case 4: a single choice again;
+		single := 0
+		if _, err := svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{MaxChoices: &single}); err != nil {
+			t.Errorf("test %v (case # 4) failed, UpdateVote err %v", testinfo, err)
+		}
+		_, err := svc.CastBallot(public, TESTDATA_NEW_VOTE_ID, []int16{1, 2}, TESTDATA_USER_ID+"-3")
+		if err != ErrTooManyChoices || !errors.Is(err, ErrBadRequest) {
+			t.Errorf("test %v (case # 4) failed, error: %v (must be %v)", testinfo, err, ErrTooManyChoices)
+		}
 	})
 }
 
 func TestHealthCheck(t *testing.T) {
 	testinfo := "test HealthCheck"
-	cluster := connect_db()
 
-	svc := New(cluster, []Middleware{})
+	svc := New(load_store(t), []Middleware{})
 
 	t.Run(testinfo, func(t *testing.T) {
 		res := svc.GetServiceStatus(context.Background())
@@ -210,6 +301,35 @@ func TestHealthCheck(t *testing.T) {
 	})
 }
 
+// The store with three database nodes, one of them is down.
+type clusterVoteStore struct {
+	VoteStore
+}
+
+func (c clusterVoteStore) NodeStatus() []NodeStatus {
+	return []NodeStatus{
+		{Address: "172.16.70.31:9042", DataCenter: "dc1", Up: true},
+		{Address: "172.16.70.32:9042", DataCenter: "dc1", Up: false},
+		{Address: "172.16.70.33:9042", DataCenter: "dc2", Up: true},
+	}
+}
+
+func TestHealthCheckDegraded(t *testing.T) {
+	testinfo := "test HealthCheck degraded"
+
+	svc := New(clusterVoteStore{load_store(t)}, []Middleware{})
+
+	t.Run(testinfo, func(t *testing.T) {
+		res := svc.GetServiceStatus(context.Background())
+		if res.Status != http.StatusOK || res.Message != SERVICE_STATUS_DEGRADED {
+			t.Errorf("test %v failed, res is %v %q", testinfo, res.Status, res.Message)
+		}
+		if len(res.Nodes) != 3 || res.Nodes[1].Up {
+			t.Errorf("test %v failed, nodes are %v", testinfo, res.Nodes)
+		}
+	})
+}
+
 func TestGetFreeMem(t *testing.T) {
 	testinfo := "test getFreeMeme"
 
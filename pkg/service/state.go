@@ -0,0 +1,99 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// The poll lifecycle. The state is stored with the poll, but some transitions are made by
+// the clock, not by the admin: a scheduled poll opens at 'OpensAt', and an open poll closes
+// at 'Deadline'. So the stored state is not always the current one, see 'CurrentState'.
+//
+//	draft -> scheduled -> open -> closed -> archived
+//
+// A draft is seen by the admin only. The polls created before the lifecycle was introduced
+// have no state (empty string), they are open until the deadline.
+
+const VOTE_STATE_DRAFT = "draft"
+const VOTE_STATE_SCHEDULED = "scheduled"
+const VOTE_STATE_OPEN = "open"
+const VOTE_STATE_CLOSED = "closed"
+const VOTE_STATE_ARCHIVED = "archived"
+
+func isVoteState(state string) bool {
+	switch state {
+	case VOTE_STATE_DRAFT, VOTE_STATE_SCHEDULED, VOTE_STATE_OPEN, VOTE_STATE_CLOSED, VOTE_STATE_ARCHIVED:
+		return true
+	}
+	return false
+}
+
+/////////////////
+//
+// CURRENT STATE
+//
+/////////////////
+
+// CurrentState returns the state of the poll at the moment 'now'.
+func (v *VoteData) CurrentState(now time.Time) string {
+	switch v.State {
+	case VOTE_STATE_DRAFT, VOTE_STATE_CLOSED, VOTE_STATE_ARCHIVED:
+		return v.State
+
+	case VOTE_STATE_SCHEDULED:
+		if now.Before(v.OpensAt) {
+			return VOTE_STATE_SCHEDULED
+		}
+	}
+
+	if v.Deadline.Before(now) {
+		return VOTE_STATE_CLOSED
+	}
+	return VOTE_STATE_OPEN
+}
+
+// It returns nil if the poll accepts votes at the moment 'now', otherwise ErrNotOpen
+// (draft or scheduled) or ErrClosed (closed or archived).
+func (v *VoteData) checkOpen(now time.Time) error {
+	switch v.CurrentState(now) {
+	case VOTE_STATE_OPEN:
+		return nil
+	case VOTE_STATE_DRAFT, VOTE_STATE_SCHEDULED:
+		return ErrNotOpen
+	}
+	return ErrClosed
+}
+
+// The order of the states, the lifecycle goes forward only (see above).
+var voteStateOrder = map[string]int{
+	VOTE_STATE_DRAFT:     0,
+	VOTE_STATE_SCHEDULED: 1,
+	VOTE_STATE_OPEN:      2,
+	VOTE_STATE_CLOSED:    3,
+	VOTE_STATE_ARCHIVED:  4,
+}
+
+// It returns ErrConflict unless the admin can move the poll from the state 'from' (the current
+// one) to the state 'to'. A state can be skipped (e.g. a draft is opened at once), but not the
+// other way: the only way back is 'ReopenVote' ('reopen'), it opens the closed poll again.
+// No state is the same as open (see above).
+func checkTransition(from, to string, reopen bool) error {
+	if to == "" {
+		to = VOTE_STATE_OPEN
+	}
+	if !isVoteState(to) {
+		return nil // It's rejected by 'validateVote';
+	}
+	if voteStateOrder[to] >= voteStateOrder[from] {
+		return nil
+	}
+	if reopen && from == VOTE_STATE_CLOSED && to == VOTE_STATE_OPEN {
+		return nil
+	}
+	return fmt.Errorf("%w: poll cannot go from %s to %s", ErrConflict, from, to)
+}
+
+// --- END OF FILE ---
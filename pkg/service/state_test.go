@@ -0,0 +1,214 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCurrentState(t *testing.T) {
+	testinfo := "test CurrentState"
+
+	now := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+
+	var cases = []struct {
+		state    string
+		opensAt  time.Time
+		deadline time.Time
+		want     string
+	}{
+		{"", time.Time{}, after, VOTE_STATE_OPEN}, // created before the lifecycle;
+		{"", time.Time{}, before, VOTE_STATE_CLOSED},
+		{VOTE_STATE_DRAFT, time.Time{}, after, VOTE_STATE_DRAFT},
+		{VOTE_STATE_SCHEDULED, after, after.Add(time.Hour), VOTE_STATE_SCHEDULED},
+		{VOTE_STATE_SCHEDULED, before, after, VOTE_STATE_OPEN},
+		{VOTE_STATE_SCHEDULED, before.Add(-time.Hour), before, VOTE_STATE_CLOSED},
+		{VOTE_STATE_OPEN, time.Time{}, after, VOTE_STATE_OPEN},
+		{VOTE_STATE_OPEN, time.Time{}, before, VOTE_STATE_CLOSED},
+		{VOTE_STATE_CLOSED, time.Time{}, after, VOTE_STATE_CLOSED},
+		{VOTE_STATE_ARCHIVED, time.Time{}, after, VOTE_STATE_ARCHIVED},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			v := VoteData{State: c.state, OpensAt: c.opensAt, Deadline: c.deadline}
+			if got := v.CurrentState(now); got != c.want {
+				t.Errorf("test %v (case # %d) failed, state %q, must be %q", testinfo, i+1, got, c.want)
+			}
+		})
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	testinfo := "test checkTransition"
+
+	var cases = []struct {
+		from   string
+		to     string
+		reopen bool
+		ok     bool
+	}{
+		{VOTE_STATE_DRAFT, VOTE_STATE_SCHEDULED, false, true},
+		{VOTE_STATE_DRAFT, VOTE_STATE_OPEN, false, true},
+		{VOTE_STATE_SCHEDULED, VOTE_STATE_SCHEDULED, false, true}, // e.g. 'opens_at' is changed;
+		{VOTE_STATE_SCHEDULED, VOTE_STATE_DRAFT, false, false},
+		{VOTE_STATE_OPEN, VOTE_STATE_CLOSED, false, true},
+		{VOTE_STATE_OPEN, VOTE_STATE_SCHEDULED, false, false},
+		{VOTE_STATE_OPEN, "", false, true},
+		{VOTE_STATE_CLOSED, VOTE_STATE_OPEN, false, false},
+		{VOTE_STATE_CLOSED, VOTE_STATE_OPEN, true, true},
+		{VOTE_STATE_CLOSED, VOTE_STATE_ARCHIVED, false, true},
+		{VOTE_STATE_ARCHIVED, VOTE_STATE_CLOSED, false, false},
+		{VOTE_STATE_ARCHIVED, VOTE_STATE_OPEN, true, false},
+	}
+
+	for i, c := range cases {
+		t.Run(testinfo, func(t *testing.T) {
+			err := checkTransition(c.from, c.to, c.reopen)
+			if c.ok && err != nil || !c.ok && !errors.Is(err, ErrConflict) {
+				t.Errorf("test %v (case # %d) failed, %s to %s err %v", testinfo, i+1, c.from, c.to, err)
+			}
+		})
+	}
+}
+
+func TestVoteLifecycle(t *testing.T) {
+	testVoteLifecycle(t, load_store(t))
+}
+
+// It moves the vote through the states, the store must not have 'TESTDATA_NEW_VOTE_ID'.
+func testVoteLifecycle(t *testing.T, store VoteStore) {
+	testinfo := "test VoteLifecycle"
+
+	svc := New(store, []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	t.Run(testinfo, func(t *testing.T) {
+		draft := new_vote(TESTDATA_NEW_VOTE_ID)
+		draft.State = VOTE_STATE_DRAFT
+		if _, err := svc.CreateVote(ctx, draft); err != nil {
+			t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+		}
+		defer svc.DeleteVote(ctx, TESTDATA_NEW_VOTE_ID)
+
+		// Draft: the admin only can see it, nobody can vote;
+		if _, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID); err != ErrNotFound {
+			t.Errorf("test %v failed, draft GetVoteData err %v, must be %v", testinfo, err, ErrNotFound)
+		}
+		if res, err := svc.GetVoteData(ctx, TESTDATA_NEW_VOTE_ID); err != nil || res.State != VOTE_STATE_DRAFT {
+			t.Errorf("test %v failed, draft GetVoteData (admin) returned %v, err %v", testinfo, res, err)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID); err != ErrNotOpen {
+			t.Errorf("test %v failed, vote for draft err %v, must be %v", testinfo, err, ErrNotOpen)
+		}
+
+		// Scheduled: it's seen, but it's not open yet;
+		state := VOTE_STATE_SCHEDULED
+		if _, err := svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{State: &state}); err == nil {
+			t.Errorf("test %v failed, scheduled without 'opens_at' must be rejected", testinfo)
+		}
+		opensAt := time.Now().Add(time.Hour).UTC()
+		if _, err := svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{State: &state, OpensAt: &opensAt}); err != nil {
+			t.Fatalf("test %v failed, UpdateVote err %v", testinfo, err)
+		}
+		if res, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID); err != nil || res.State != VOTE_STATE_SCHEDULED {
+			t.Errorf("test %v failed, scheduled GetVoteData returned %v, err %v", testinfo, res, err)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID); err != ErrNotOpen {
+			t.Errorf("test %v failed, vote for scheduled err %v, must be %v", testinfo, err, ErrNotOpen)
+		}
+
+		// Open;
+		opensAt = time.Now().Add(-time.Hour).UTC()
+		if _, err := svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{OpensAt: &opensAt}); err != nil {
+			t.Fatalf("test %v failed, UpdateVote err %v", testinfo, err)
+		}
+		if res, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID); err != nil || res.State != VOTE_STATE_OPEN {
+			t.Errorf("test %v failed, open GetVoteData returned %v, err %v", testinfo, res, err)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID); err != nil {
+			t.Errorf("test %v failed, cannot vote: %v", testinfo, err)
+		}
+
+		// Closed early, and reopened;
+		if _, err := svc.CloseVote(public, TESTDATA_NEW_VOTE_ID); err != ErrUnauthorized {
+			t.Errorf("test %v failed, CloseVote (not admin) err %v, must be %v", testinfo, err, ErrUnauthorized)
+		}
+		if res, err := svc.CloseVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil || res.State != VOTE_STATE_CLOSED {
+			t.Fatalf("test %v failed, CloseVote returned %v, err %v", testinfo, res, err)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-2"); err != ErrClosed {
+			t.Errorf("test %v failed, vote for closed err %v, must be %v", testinfo, err, ErrClosed)
+		}
+		open := VOTE_STATE_OPEN
+		if _, err := svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{State: &open}); !errors.Is(err, ErrConflict) {
+			t.Errorf("test %v failed, UpdateVote (closed to open) err %v, must be %v", testinfo, err, ErrConflict)
+		}
+		if res, err := svc.ReopenVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil || res.State != VOTE_STATE_OPEN {
+			t.Fatalf("test %v failed, ReopenVote returned %v, err %v", testinfo, res, err)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID+"-2"); err != nil {
+			t.Errorf("test %v failed, cannot vote after reopen: %v", testinfo, err)
+		}
+
+		// The deadline has passed, it cannot be reopened;
+		deadline := time.Now().Add(-time.Minute).UTC()
+		if _, err := svc.UpdateVote(ctx, TESTDATA_NEW_VOTE_ID, VotePatch{Deadline: &deadline}); err != nil {
+			t.Fatalf("test %v failed, UpdateVote err %v", testinfo, err)
+		}
+		if res, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID); err != nil || res.State != VOTE_STATE_CLOSED {
+			t.Errorf("test %v failed, GetVoteData after the deadline returned %v, err %v", testinfo, res, err)
+		}
+		if _, err := svc.ReopenVote(ctx, TESTDATA_NEW_VOTE_ID); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("test %v failed, ReopenVote after the deadline err %v, must be %v", testinfo, err, ErrBadRequest)
+		}
+	})
+}
+
+func TestGetVoteResults(t *testing.T) {
+	testinfo := "test GetVoteResults"
+
+	svc := New(load_store(t), []Middleware{})
+	ctx := WithAdmin(context.Background())
+	public := context.Background()
+
+	t.Run(testinfo, func(t *testing.T) {
+		vote := new_vote(TESTDATA_NEW_VOTE_ID)
+		vote.AllowResults = false
+		if _, err := svc.CreateVote(ctx, vote); err != nil {
+			t.Fatalf("test %v failed, CreateVote err %v", testinfo, err)
+		}
+		if _, err := svc.UpdateVoteResults(public, TESTDATA_NEW_VOTE_ID, 1, TESTDATA_USER_ID); err != nil {
+			t.Fatalf("test %v failed, cannot vote: %v", testinfo, err)
+		}
+
+		// The counts are never in the vote data;
+		res, err := svc.GetVoteData(public, TESTDATA_NEW_VOTE_ID)
+		if err != nil || res.Contenders[0].Count != 0 {
+			t.Errorf("test %v failed, GetVoteData returned %v, err %v (counts must be hidden)", testinfo, res, err)
+		}
+
+		// The results are hidden until the poll is closed, but not for the admin;
+		if _, err = svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID); err != ErrResultsHidden {
+			t.Errorf("test %v failed, GetVoteResults err %v, must be %v", testinfo, err, ErrResultsHidden)
+		}
+		if res, err = svc.GetVoteResults(ctx, TESTDATA_NEW_VOTE_ID); err != nil || res.Contenders[0].Count != 1 {
+			t.Errorf("test %v failed, GetVoteResults (admin) returned %v, err %v", testinfo, res, err)
+		}
+
+		if _, err = svc.CloseVote(ctx, TESTDATA_NEW_VOTE_ID); err != nil {
+			t.Fatalf("test %v failed, CloseVote err %v", testinfo, err)
+		}
+		if res, err = svc.GetVoteResults(public, TESTDATA_NEW_VOTE_ID); err != nil || res.Contenders[0].Count != 1 {
+			t.Errorf("test %v failed, GetVoteResults (closed) returned %v, err %v", testinfo, res, err)
+		}
+	})
+}
+
+// --- END OF FILE ---
@@ -0,0 +1,171 @@
+//  Created : 2026-Oct-18
+// Modified : 2026-Oct-18
+
+package service
+
+import (
+	"context"
+)
+
+// VoteStore is the storage backend behind 'basicVoteService'. The service keeps the
+// voting logic (deadline check, one vote per voter, etc.), while the store only knows
+// how to read and write records. Apache Cassandra is the original (and default) store,
+// see 'cassandra.go'; other implementations must follow the same contract:
+
+//   - LoadVote returns all data related to 'vote_id', or ErrNotFound if there is nothing;
+//   - AddVoter inserts a new voter unless it exists ('applied' is false in that case);
+//   - IncrementCounts adds one vote to each contender, all or none ('applied' is false if any does not exist);
+//   - RemoveVoter deletes the voter, it compensates a failed IncrementCounts;
+//   - Ping checks if the storage is available;
+//   - CreateVote saves a new vote with its contenders, or returns ErrConflict if it exists;
+//   - UpdateVote saves the vote data except contenders, or returns ErrNotFound;
+//   - DeleteVote deletes the vote, its contenders and voters (no error if there is nothing);
+//   - AddContender adds a contender to the vote (ErrNotFound: no vote, ErrConflict: 'co_id' is used);
+//   - UpdateContender saves the contender data except the count, or returns ErrNotFound;
+//   - SetRoll replaces the roll of the vote (ErrNotFound: no vote), an empty roll is no roll;
+//   - CheckRoll returns true if the entry is on the roll of the vote, or the vote has no roll;
+//   - RollStats returns the size of the roll and the number of the voters on it (no turnout);
+//   - AddCodes saves new invitation codes of the vote (ErrNotFound: no vote);
+//   - BurnCode marks the code as used ('applied' is false if it's unknown, used or revoked);
+//   - RestoreCode marks the code as unused, it compensates a failed vote;
+//   - RevokeCodes revokes the unused codes (all of them if 'codes' is nil), and returns their number;
+//   - AddBallot saves the ranked ballot, it's not related to the voter (ErrConflict: 'ballot_id' is used);
+//   - RemoveBallot deletes the ballot, it compensates a failed vote;
+//   - LoadBallots returns all the ballots of the vote, in any order;
+//   - AddScores adds the rating of each contender to its tally, all or none ('applied' is false if any does not exist);
+//   - LoadScores returns the tallies of the ratings, the contenders without ratings may be missing;
+//   - LoadChoice returns the contender chosen by the voter in a revisable poll (ErrNotFound: no voter, 0: no choice saved);
+//   - ChangeChoice changes the choice of the voter if it's 'from' ('applied' is false otherwise): 0 'from' adds the voter, 0 'to' removes it;
+//   - MoveCount decrements the count of 'from' and increments the count of 'to' at once (0 is no contender, 'applied' as IncrementCounts);
+//   - AddReceipt saves the receipt of the vote (ErrConflict: the id is used, ErrClosed: the receipts are sealed);
+//   - RemoveReceipt deletes the receipt, it compensates a failed vote;
+//   - LoadReceipt returns the receipt of the vote, or ErrNotFound;
+//   - LoadReceipts returns all the receipts of the vote (without 'UserId'), in any order;
+//   - SealReceipts refuses the new receipts of the vote, the receipts being saved are either saved before, or refused;
+//   - SaveCommitment saves the root of the receipts unless it exists ('applied' is false in that case);
+//   - LoadCommitment returns the root of the receipts, or ErrNotFound;
+//   - RemoveCommitment deletes the root of the receipts and unseals them (no error if there is nothing);
+//   - AppendAudit saves the entry of the audit log unless its 'Seq' is used ('applied' is false in that case);
+//   - LoadAuditHead returns the last entry of the audit log, or ErrNotFound if the log is empty;
+//   - LoadAudit returns all the entries of the audit log, ordered by 'Seq';
+//   - AddEncryptedBallot saves the encrypted ballot, it's not related to the voter (ErrConflict: 'ballot_id' is used);
+//   - RemoveEncryptedBallot deletes the encrypted ballot, it compensates a failed vote;
+//   - LoadEncryptedBallots returns all the encrypted ballots of the vote, in any order;
+//   - SaveTally saves the decrypted tally unless it exists ('applied' is false in that case);
+//   - LoadTally returns the decrypted tally, or ErrNotFound;
+//   - RemoveTally deletes the decrypted tally (no error if there is nothing);
+//
+// The roll entries and the codes are hashes, see 'rollEntry' in 'roll.go' and 'codeHash' in 'code.go'.
+
+type VoteStore interface {
+	LoadVote(ctx context.Context, vote_id int) (*VoteData, error)
+	AddVoter(ctx context.Context, vote_id int, user_id string) (applied bool, err error)
+	IncrementCounts(ctx context.Context, vote_id int, co_ids []int16) (applied bool, err error)
+	RemoveVoter(ctx context.Context, vote_id int, user_id string) error
+	Ping(ctx context.Context) error
+
+	CreateVote(ctx context.Context, vote *VoteData) error
+	UpdateVote(ctx context.Context, vote *VoteData) error
+	DeleteVote(ctx context.Context, vote_id int) error
+	AddContender(ctx context.Context, vote_id int, contender *Contender) error
+	UpdateContender(ctx context.Context, vote_id int, contender *Contender) error
+
+	SetRoll(ctx context.Context, vote_id int, entries []string) error
+	CheckRoll(ctx context.Context, vote_id int, entry string) (bool, error)
+	RollStats(ctx context.Context, vote_id int) (*RollStats, error)
+
+	AddCodes(ctx context.Context, vote_id int, codes []string) error
+	BurnCode(ctx context.Context, vote_id int, code string) (applied bool, err error)
+	RestoreCode(ctx context.Context, vote_id int, code string) error
+	RevokeCodes(ctx context.Context, vote_id int, codes []string) (int64, error)
+
+	AddBallot(ctx context.Context, vote_id int, ballot_id string, ranking []int16) error
+	RemoveBallot(ctx context.Context, vote_id int, ballot_id string) error
+	LoadBallots(ctx context.Context, vote_id int) ([][]int16, error)
+
+	AddScores(ctx context.Context, vote_id int, scores map[int16]int) (applied bool, err error)
+	LoadScores(ctx context.Context, vote_id int) (map[int16]*ScoreTally, error)
+
+	LoadChoice(ctx context.Context, vote_id int, user_id string) (int16, error)
+	ChangeChoice(ctx context.Context, vote_id int, user_id string, from, to int16) (applied bool, err error)
+	MoveCount(ctx context.Context, vote_id int, from, to int16) (applied bool, err error)
+
+	AddReceipt(ctx context.Context, vote_id int, receipt *Receipt) error
+	RemoveReceipt(ctx context.Context, vote_id int, receipt string) error
+	LoadReceipt(ctx context.Context, vote_id int, receipt string) (*Receipt, error)
+	LoadReceipts(ctx context.Context, vote_id int) ([]*Receipt, error)
+	SealReceipts(ctx context.Context, vote_id int) error
+
+	SaveCommitment(ctx context.Context, vote_id int, commitment *Commitment) (applied bool, err error)
+	LoadCommitment(ctx context.Context, vote_id int) (*Commitment, error)
+	RemoveCommitment(ctx context.Context, vote_id int) error
+
+	AppendAudit(ctx context.Context, vote_id int, entry *AuditEntry) (applied bool, err error)
+	LoadAuditHead(ctx context.Context, vote_id int) (*AuditEntry, error)
+	LoadAudit(ctx context.Context, vote_id int) ([]*AuditEntry, error)
+
+	AddEncryptedBallot(ctx context.Context, vote_id int, ballot_id string, ballot *EncryptedBallot) error
+	RemoveEncryptedBallot(ctx context.Context, vote_id int, ballot_id string) error
+	LoadEncryptedBallots(ctx context.Context, vote_id int) ([]*EncryptedBallot, error)
+
+	SaveTally(ctx context.Context, vote_id int, tally *EncryptedTally) (applied bool, err error)
+	LoadTally(ctx context.Context, vote_id int) (*EncryptedTally, error)
+	RemoveTally(ctx context.Context, vote_id int) error
+}
+
+// VoteRecord is a single vote as it's saved by the store, see 'CastBallot'.
+type VoteRecord struct {
+	UserId   string
+	Code     string        // The hash of the invitation code to be burned, or "";
+	Counts   []int16       // The contenders whose counts are incremented;
+	BallotId string        // The ballot to be saved, if 'Ranking' (or 'Encrypted') is not nil;
+	Ranking  []int16       // The ranked ballot, see 'ranked.go';
+	Scores   map[int16]int // The ratings of the score ballot, they replace 'Counts' (see 'score.go');
+	Receipt  *Receipt      // The receipt to be saved with the vote, or nil (see 'receipt.go');
+	Audit    *AuditEntry   // The entry of the audit log, it's chained by the store (see 'audit.go');
+
+	Encrypted *EncryptedBallot // The encrypted ballot, no counts are changed (see 'encrypted.go');
+}
+
+// ScoreTally is what the store keeps for a contender of a score poll: the sum and the
+// number of its ratings, and the number of each score (the histogram).
+type ScoreTally struct {
+	Sum     int64
+	Count   int64
+	Ratings map[int]int64 // score -> number of ratings;
+}
+
+// VoteRecorder is implemented by the stores able to record the vote atomically, i.e.
+// to add the voter, the ballot (or the encrypted one) and the counts (or the ratings) in one transaction. If the store has it,
+// the service uses it instead of AddVoter + AddBallot + IncrementCounts/AddScores (+ RemoveVoter, etc).
+// It returns ErrForbidden if the voter exists, and ErrBadRequest if any contender does not.
+// If there is a code, the code is burned by the same transaction (ErrInvalidCode), and so
+// the receipt is saved (ErrClosed if the receipts are sealed), and the audit entry is appended
+// (ErrAuditConflict if the log has been changed in the meantime, the service retries).
+type VoteRecorder interface {
+	RecordVote(ctx context.Context, vote_id int, r *VoteRecord) error
+}
+
+// VoteReviser is implemented by the stores able to change the vote of the revisable poll
+// atomically, i.e. ChangeChoice + MoveCount in one transaction (see 'revise.go'). It returns
+// ErrConflict if the choice is not 'from' anymore, and ErrBadRequest if any contender does not exist.
+// The audit entry is appended by the same transaction, as in 'VoteRecorder'.
+type VoteReviser interface {
+	ReviseVote(ctx context.Context, vote_id int, user_id string, from, to int16, audit *AuditEntry) error
+}
+
+// NodeReporter is implemented by the stores running on a cluster of database nodes
+// (Apache Cassandra). The service adds the nodes to its status, see 'GetServiceStatus'.
+type NodeReporter interface {
+	NodeStatus() []NodeStatus
+}
+
+// NodeStatus describes a single database node as it's seen by the service.
+type NodeStatus struct {
+	Address    string `json:"address"` // host:port;
+	DataCenter string `json:"dc"`
+	Rack       string `json:"rack"`
+	Up         bool   `json:"up"`
+}
+
+// --- END OF FILE ---
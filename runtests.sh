@@ -1,17 +1,13 @@
 #!/bin/bash
 #  Created : 2023-Apr-05
-# Modified : 2024-Apr-05
+# Modified : 2026-Oct-18
 
 echo "UNIT TESTS"
 echo "=========="
 echo "pkg/service"
 echo "-----------"
-echo "Service layer tests would not work without access to the database instance!"
-read -p "Continue?(Y/n): " ANS
-if [ "$ANS" == "N" ] || [ "$ANS" == "n" ];
-then
-  exit 0
-fi
+echo "Service layer tests use the in-memory store (testdata/votes.json)."
+echo "Database tests are run by 'make test_integ'."
 cd ./pkg/service
 go test -v
 
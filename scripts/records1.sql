@@ -0,0 +1,30 @@
+--  Created: 2026-Oct-18
+-- Modified: 2026-Oct-18
+
+-- This is 'records1.cql' for SQLite (vote-svc -database-driver sqlite);
+-- the tables are created by the service, e.g.
+--   sqlite3 vote-svc.db < ./scripts/records1.sql
+
+INSERT INTO polls (vote_id, header, message, resources, deadline, authenticate, allowresults)
+  VALUES(1, 'The Most Hated Dictators', 'Current results (the voting ends 2030-Dec-31)', 'https://ws4/votes/1/images/',
+    '2030-12-31 22:00:00+03:00', false, true);
+
+INSERT INTO contenders (vote_id, co_id, co_name, co_alias, co_info, co_picture, co_count, co_updated)
+  VALUES(1, 1, 'Joseph Stalin', 'Stalin',
+    'Joseph Stalin | General Secretary (1924-1953) of Soviet Communist Party, aka Russian Social Democratic Labour Party (Bolsheviks)',
+    'Joseph_Stalin.jpg', 240, CURRENT_TIMESTAMP);
+
+INSERT INTO contenders (vote_id, co_id, co_name, co_alias, co_info, co_picture, co_count, co_updated)
+  VALUES(1, 2, 'Adolf Hitler', 'Hitler',
+    'Adolf Hitler | German Chancellor (1932-1945), leader of NSDAP (the Nazi Party, officially the National Socialist German Workers Party, 1920-1945)',
+    'Adolf_Hitler.jpg', 300, CURRENT_TIMESTAMP);
+
+INSERT INTO contenders (vote_id, co_id, co_name, co_alias, co_info, co_picture, co_count, co_updated)
+  VALUES(1, 3, 'Kim Jong Un', 'Kim',
+    'Kim Jong Un | President (?) of North Korea, General Secretary of Nort Korean Communist Party',
+    'Kim_Jong_Un.jpg', 190, CURRENT_TIMESTAMP);
+
+INSERT INTO contenders (vote_id, co_id, co_name, co_alias, co_info, co_picture, co_count, co_updated)
+  VALUES(1, 4, 'Augusto Pinochet', 'Pinochet',
+    'Augusto Pinochet | A Chilean army officer and military dictator who ruled Chile from 1973 to 1990',
+    'Augusto_Pinochet.jpg', 100, CURRENT_TIMESTAMP);